@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"guru-game/internal/catalogimport"
+	"guru-game/internal/catalogimport/handlers"
+	"guru-game/internal/db/connection"
+	"guru-game/internal/db/repository/boardgame"
+)
+
+// runImportCommand implements `go run . import -file games.csv [-format csv|bgg] [-dry-run]`
+func runImportCommand(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	file := fs.String("file", "", "path to a CSV file or BoardGameGeek XML API2 thing document")
+	format := fs.String("format", "", "csv or bgg (defaults to the file extension)")
+	dryRun := fs.Bool("dry-run", false, "report the diff without writing to the database")
+	fs.Parse(args)
+
+	if *file == "" {
+		fmt.Fprintln(os.Stderr, "usage: import -file <path> [-format csv|bgg] [-dry-run]")
+		return 2
+	}
+	if *format == "" {
+		*format = handlers.FormatFromFilename(*file)
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Printf("❌ Failed to open import file: %v", err)
+		return 1
+	}
+	defer f.Close()
+
+	rows, err := catalogimport.Parse(*format, f)
+	if err != nil {
+		log.Printf("❌ Failed to parse import file: %v", err)
+		return 1
+	}
+
+	connection.ConnectDB()
+	importer := catalogimport.NewImporter(&boardgame.PostgresBoardgameRepository{})
+	report := importer.Import(rows, *dryRun)
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		log.Printf("❌ Failed to write report: %v", err)
+		return 1
+	}
+
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,113 @@
+package aggregation
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"guru-game/internal/db/repository/aggregates"
+	"guru-game/internal/db/repository/user_states"
+)
+
+// Defaults used when the corresponding environment variables are unset
+const (
+	defaultPriorWeight    = 10
+	defaultHalfLifeDays   = 30
+	defaultRecomputeEvery = time.Hour
+	similarNeighbours     = 100
+)
+
+// Service keeps boardgame rating_avg, rating_count and popularity_score in sync with user_states.
+// RecomputeGame is called after every state change; StartPeriodicRecompute refreshes all games
+// so popularity keeps decaying even for games nobody touches, refreshes the prior mean rating
+// that RecomputeGame reads, and recomputes the game similarities used by the fallback
+// recommender.
+type Service struct {
+	repo   aggregates.AggregateRepository
+	params aggregates.Params
+}
+
+// NewService creates a new aggregation Service configured from the environment:
+// RATING_PRIOR_WEIGHT (default 10) and POPULARITY_HALF_LIFE_DAYS (default 30)
+func NewService(repo aggregates.AggregateRepository) *Service {
+	halfLifeDays := envFloat("POPULARITY_HALF_LIFE_DAYS", defaultHalfLifeDays)
+	return &Service{
+		repo: repo,
+		params: aggregates.Params{
+			PriorWeight:           envFloat("RATING_PRIOR_WEIGHT", defaultPriorWeight),
+			HalfLifeSeconds:       halfLifeDays * 24 * 60 * 60,
+			LikeWeight:            1,
+			FavoriteWeight:        2,
+			RatingWeight:          1,
+			RatingPopularityScale: 0.1,
+			PositiveRating:        user_states.PositiveRating,
+			SimilarNeighbours:     similarNeighbours,
+		},
+	}
+}
+
+// RecomputeGame refreshes the aggregates of one board game
+func (s *Service) RecomputeGame(ctx context.Context, boardgameID int) error {
+	return s.repo.RecomputeGame(ctx, boardgameID, s.params)
+}
+
+// RecomputeAll refreshes the aggregates of every board game with user states and the
+// game similarities
+func (s *Service) RecomputeAll(ctx context.Context) error {
+	start := time.Now()
+	n, err := s.repo.RecomputeAll(ctx, s.params)
+	if err != nil {
+		return err
+	}
+	log.Printf("📊 Recomputed aggregates for %d boardgames in %v", n, time.Since(start))
+
+	start = time.Now()
+	pairs, err := s.repo.RecomputeSimilarities(ctx, s.params)
+	if err != nil {
+		return err
+	}
+	log.Printf("📊 Recomputed %d game similarities in %v", pairs, time.Since(start))
+	return nil
+}
+
+// StartPeriodicRecompute runs RecomputeAll every AGGREGATION_INTERVAL (default 1h) until ctx is cancelled
+func (s *Service) StartPeriodicRecompute(ctx context.Context) {
+	interval := defaultRecomputeEvery
+	if v := os.Getenv("AGGREGATION_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			interval = d
+		} else {
+			log.Printf("⚠️ Invalid AGGREGATION_INTERVAL %q, using %v", v, interval)
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := s.RecomputeAll(ctx); err != nil {
+				log.Printf("❌ Periodic aggregate recompute failed: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func envFloat(key string, fallback float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f <= 0 {
+		log.Printf("⚠️ Invalid %s %q, using %v", key, v, fallback)
+		return fallback
+	}
+	return f
+}
@@ -0,0 +1,46 @@
+package jwt
+
+import (
+	"os"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminMiddleware ต้องใช้ต่อจาก JWTMiddleware และอนุญาตเฉพาะ username ที่อยู่ใน ADMIN_USERNAMES
+func AdminMiddleware(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(fiber.Map)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	username, _ := user["username"].(string)
+	if !IsAdmin(username) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Admin access required"})
+	}
+
+	return c.Next()
+}
+
+// IsAdmin reports whether username is listed in the comma-separated ADMIN_USERNAMES variable
+func IsAdmin(username string) bool {
+	if username == "" {
+		return false
+	}
+	for _, admin := range strings.Split(os.Getenv("ADMIN_USERNAMES"), ",") {
+		if strings.TrimSpace(admin) == username {
+			return true
+		}
+	}
+	return false
+}
+
+// UserIDFromCtx returns the user ID stored in the context by JWTMiddleware
+func UserIDFromCtx(c *fiber.Ctx) (int64, bool) {
+	user, ok := c.Locals("user").(fiber.Map)
+	if !ok {
+		return 0, false
+	}
+	id, ok := user["id"].(int64)
+	return id, ok
+}
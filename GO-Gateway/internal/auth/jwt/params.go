@@ -0,0 +1,24 @@
+package jwt
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UserAndIDParam reads the caller stored by JWTMiddleware and the integer route parameter
+// param. resource names what the ID refers to (e.g. "play") in the 400 message. When it
+// returns false a 401 or 400 has already been written.
+func UserAndIDParam[ID int | int64](c *fiber.Ctx, param, resource string) (int64, ID, bool) {
+	userID, ok := UserIDFromCtx(c)
+	if !ok {
+		c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+		return 0, 0, false
+	}
+	id, err := strconv.ParseInt(c.Params(param), 10, strconv.IntSize)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid " + resource + " ID"})
+		return 0, 0, false
+	}
+	return userID, ID(id), true
+}
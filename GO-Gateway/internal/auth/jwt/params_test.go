@@ -0,0 +1,51 @@
+package jwt
+
+import (
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestUserAndIDParam(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       fiber.Map
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"caller and id", fiber.Map{"id": int64(7)}, "/things/42", fiber.StatusOK, "7:42"},
+		{"negative id is parsed", fiber.Map{"id": int64(7)}, "/things/-3", fiber.StatusOK, "7:-3"},
+		{"no caller", nil, "/things/42", fiber.StatusUnauthorized, `{"error":"Unauthorized"}`},
+		{"caller id of the wrong type", fiber.Map{"id": "7"}, "/things/42", fiber.StatusUnauthorized, `{"error":"Unauthorized"}`},
+		{"id not a number", fiber.Map{"id": int64(7)}, "/things/abc", fiber.StatusBadRequest, `{"error":"Invalid thing ID"}`},
+		{"id out of range", fiber.Map{"id": int64(7)}, "/things/99999999999999999999", fiber.StatusBadRequest, `{"error":"Invalid thing ID"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/things/:thing_id", func(c *fiber.Ctx) error {
+				if tt.user != nil {
+					c.Locals("user", tt.user)
+				}
+				userID, thingID, ok := UserAndIDParam[int](c, "thing_id", "thing")
+				if !ok {
+					return nil
+				}
+				return c.SendString(fmt.Sprintf("%d:%d", userID, thingID))
+			})
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, tt.path, nil))
+			if err != nil {
+				t.Fatalf("app.Test() = %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus || string(body) != tt.wantBody {
+				t.Errorf("GET %s = %d %s, want %d %s", tt.path, resp.StatusCode, body, tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+}
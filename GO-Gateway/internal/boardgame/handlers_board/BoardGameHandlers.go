@@ -1,26 +1,35 @@
 package handlers_board
 
 import (
+	"context"
 	"log"
 	"strconv"
 
 	"guru-game/internal/boardgame/service_board"
 	"guru-game/internal/db/repository/boardgame"
+	"guru-game/models"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// ReviewSummaries provides the review summary shown on the game detail endpoint
+type ReviewSummaries interface {
+	Summary(ctx context.Context, boardgameID int) (*models.ReviewSummary, error)
+}
+
 // BoardGameHandlers struct สำหรับเก็บ dependencies ของ handlers
 type BoardGameHandlers struct {
 	BoardGameRepo boardgame.BoardGameRepository
+	Reviews       ReviewSummaries
 	// ถ้าต้องการเรียก service ที่เชื่อมต่อกับ Python service อาจเพิ่ม field ได้ที่นี่
 	// PythonService *service_board.PythonBoardgameService // ตัวอย่าง
 }
 
 // NewBoardGameHandlers สร้าง instance ใหม่ของ BoardGameHandlers
-func NewBoardGameHandlers(repo boardgame.BoardGameRepository) *BoardGameHandlers {
+func NewBoardGameHandlers(repo boardgame.BoardGameRepository, reviews ReviewSummaries) *BoardGameHandlers {
 	return &BoardGameHandlers{
 		BoardGameRepo: repo,
+		Reviews:       reviews,
 	}
 }
 
@@ -56,6 +65,16 @@ func (h *BoardGameHandlers) GetBoardGameByIDHandler(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Board game not found"})
 	}
 
+	// The game is still served if the summary cannot be loaded
+	if h.Reviews != nil {
+		summary, err := h.Reviews.Summary(c.Context(), id)
+		if err != nil {
+			log.Println("Failed to fetch review summary ->", err)
+		} else {
+			boardgame.ReviewSummary = summary
+		}
+	}
+
 	return c.Status(fiber.StatusOK).JSON(boardgame)
 }
 
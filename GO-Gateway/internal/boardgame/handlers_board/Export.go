@@ -0,0 +1,83 @@
+package handlers_board
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"guru-game/internal/boardgame/service_board"
+	"guru-game/internal/db/repository/boardgame"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HandleExportBoardGames streams the catalogue as CSV or newline-delimited JSON.
+// Query params: format=csv|jsonl|ndjson, fields=id,title,..., category, title, players, max_time, updated_since (RFC3339)
+func (h *BoardGameHandlers) HandleExportBoardGames(c *fiber.Ctx) error {
+	format := strings.ToLower(c.Query("format", service_board.ExportFormatCSV))
+	contentType := service_board.ExportContentType(format)
+	if contentType == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "format must be csv, jsonl or ndjson"})
+	}
+
+	fields, err := service_board.ResolveExportFields(c.Query("fields"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	filter, err := CatalogueFilterFromQuery(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	extension := format
+	if format == service_board.ExportFormatNDJSON {
+		extension = service_board.ExportFormatJSONL
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="boardgames.%s"`, extension))
+
+	// The body is written after this handler returns, so the query must not use the request context
+	repo := h.BoardGameRepo
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		count, err := service_board.ExportBoardGames(context.Background(), repo, w, format, fields, filter)
+		if err != nil {
+			// Headers are already sent, so the client only sees a truncated file
+			log.Printf("Board game export failed after %d rows: %v", count, err)
+		}
+		if err := w.Flush(); err != nil {
+			log.Printf("Board game export aborted by client after %d rows: %v", count, err)
+			return
+		}
+		log.Printf("Exported %d board games as %s", count, format)
+	})
+
+	return nil
+}
+
+// CatalogueFilterFromQuery reads the catalogue filters shared by the export and collection
+// endpoints: category, title, players, max_time, updated_since (RFC3339), min_weight,
+// max_weight and best_at (player count)
+func CatalogueFilterFromQuery(c *fiber.Ctx) (boardgame.StreamFilter, error) {
+	filter := boardgame.StreamFilter{
+		Category:        c.Query("category"),
+		Title:           c.Query("title"),
+		PlayerCount:     c.QueryInt("players"),
+		MaxPlayTime:     c.QueryInt("max_time"),
+		MinWeight:       c.QueryFloat("min_weight"),
+		MaxWeight:       c.QueryFloat("max_weight"),
+		BestPlayerCount: c.QueryInt("best_at"),
+	}
+	if since := c.Query("updated_since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return filter, errors.New("updated_since must be an RFC3339 timestamp")
+		}
+		filter.UpdatedSince = t
+	}
+	return filter, nil
+}
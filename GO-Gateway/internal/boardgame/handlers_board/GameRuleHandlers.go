@@ -0,0 +1,241 @@
+package handlers_board
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+
+	"guru-game/internal/auth/jwt"
+	"guru-game/internal/boardgame/service_board"
+	"guru-game/internal/db/repository/game_rules"
+	"guru-game/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GameRuleHandlers holds the dependencies for game rule handlers
+type GameRuleHandlers struct {
+	GameRuleService *service_board.GameRuleService
+}
+
+// NewGameRuleHandlers creates a new GameRuleHandlers instance
+func NewGameRuleHandlers(service *service_board.GameRuleService) *GameRuleHandlers {
+	return &GameRuleHandlers{GameRuleService: service}
+}
+
+// gameRuleInput is the request body for creating or updating a rule set
+type gameRuleInput struct {
+	Title    string            `json:"title"`
+	Language string            `json:"language"`
+	Steps    []models.RuleStep `json:"steps"`
+}
+
+// HandleGetGameRules returns the rule sets for a board game in the best available language.
+// ?lang= overrides Accept-Language; ?lang=all returns every language.
+func (h *GameRuleHandlers) HandleGetGameRules(c *fiber.Ctx) error {
+	boardgameID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid board game ID"})
+	}
+
+	rules, err := h.GameRuleService.GetGameRulesByBoardgameID(c.Context(), boardgameID)
+	if err != nil {
+		log.Println("Failed to fetch game rules ->", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch game rules"})
+	}
+
+	available := service_board.RuleLanguages(rules)
+	c.Vary(fiber.HeaderAcceptLanguage)
+
+	language := c.Query("lang")
+	if language == "all" {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"rules": rules, "available_languages": available})
+	}
+	if language == "" && len(available) > 0 {
+		language = c.AcceptsLanguages(available...)
+	}
+
+	rules, language = service_board.FilterRulesByLanguage(rules, language)
+	if language != "" {
+		c.Set(fiber.HeaderContentLanguage, language)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"rules":               rules,
+		"language":            language,
+		"available_languages": available,
+	})
+}
+
+// HandleCreateGameRule creates a rule set for a board game
+func (h *GameRuleHandlers) HandleCreateGameRule(c *fiber.Ctx) error {
+	boardgameID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid board game ID"})
+	}
+
+	input, err := decodeGameRuleInput(c.Body())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	rule := &models.GameRule{BoardgameID: boardgameID, Title: input.Title, Language: input.Language, Steps: input.Steps, UpdatedBy: authorID(c)}
+	if err := h.GameRuleService.CreateGameRule(c.Context(), rule); err != nil {
+		return gameRuleError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(rule)
+}
+
+// HandleUpdateGameRule replaces the title and steps of a rule set
+func (h *GameRuleHandlers) HandleUpdateGameRule(c *fiber.Ctx) error {
+	ruleID, err := strconv.Atoi(c.Params("rule_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid rule ID"})
+	}
+
+	input, err := decodeGameRuleInput(c.Body())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	rule := &models.GameRule{ID: ruleID, Title: input.Title, Language: input.Language, Steps: input.Steps, UpdatedBy: authorID(c)}
+	if err := h.GameRuleService.UpdateGameRule(c.Context(), rule); err != nil {
+		return gameRuleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(rule)
+}
+
+// HandleDeleteGameRule deletes a rule set
+func (h *GameRuleHandlers) HandleDeleteGameRule(c *fiber.Ctx) error {
+	ruleID, err := strconv.Atoi(c.Params("rule_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid rule ID"})
+	}
+
+	if err := h.GameRuleService.DeleteGameRule(c.Context(), ruleID); err != nil {
+		return gameRuleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Game rule deleted successfully"})
+}
+
+// HandleGetGameRuleVersions lists the edit history of a rule set
+func (h *GameRuleHandlers) HandleGetGameRuleVersions(c *fiber.Ctx) error {
+	ruleID, err := strconv.Atoi(c.Params("rule_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid rule ID"})
+	}
+
+	versions, err := h.GameRuleService.GetGameRuleVersions(c.Context(), ruleID)
+	if err != nil {
+		return gameRuleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"versions": versions})
+}
+
+// HandleGetGameRuleVersion returns a single stored version of a rule set
+func (h *GameRuleHandlers) HandleGetGameRuleVersion(c *fiber.Ctx) error {
+	ruleID, err := strconv.Atoi(c.Params("rule_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid rule ID"})
+	}
+	version, err := strconv.Atoi(c.Params("version"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid version"})
+	}
+
+	v, err := h.GameRuleService.GetGameRuleVersion(c.Context(), ruleID, version)
+	if err != nil {
+		return gameRuleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(v)
+}
+
+// HandleDiffGameRuleVersions compares two versions of a rule set (?from=1&to=3)
+func (h *GameRuleHandlers) HandleDiffGameRuleVersions(c *fiber.Ctx) error {
+	ruleID, err := strconv.Atoi(c.Params("rule_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid rule ID"})
+	}
+	from, errFrom := strconv.Atoi(c.Query("from"))
+	to, errTo := strconv.Atoi(c.Query("to"))
+	if errFrom != nil || errTo != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "from and to versions are required"})
+	}
+
+	diff, err := h.GameRuleService.DiffGameRuleVersions(c.Context(), ruleID, from, to)
+	if err != nil {
+		return gameRuleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(diff)
+}
+
+// HandleSearchGameRules searches rule steps: ?q=trade first turn&lang=en&limit=10&offset=0.
+// When mounted under /boardgames/:id the search is scoped to that board game.
+func (h *GameRuleHandlers) HandleSearchGameRules(c *fiber.Ctx) error {
+	params := game_rules.SearchParams{
+		Query:       c.Query("q"),
+		Language:    c.Query("lang"),
+		Limit:       c.QueryInt("limit", 10),
+		Offset:      c.QueryInt("offset", 0),
+		BoardgameID: c.QueryInt("boardgame_id", 0),
+	}
+	if idParam := c.Params("id"); idParam != "" {
+		id, err := strconv.Atoi(idParam)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid board game ID"})
+		}
+		params.BoardgameID = id
+	}
+
+	hits, err := h.GameRuleService.SearchGameRules(c.Context(), params)
+	if err != nil {
+		return gameRuleError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"query": params.Query, "results": hits})
+}
+
+// authorID returns the ID of the logged-in user editing a rule set, if any
+func authorID(c *fiber.Ctx) *int64 {
+	if id, ok := jwt.UserIDFromCtx(c); ok {
+		return &id
+	}
+	return nil
+}
+
+// decodeGameRuleInput parses the body strictly so misspelled step fields are rejected instead of dropped
+func decodeGameRuleInput(body []byte) (*gameRuleInput, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+
+	var input gameRuleInput
+	if err := dec.Decode(&input); err != nil {
+		return nil, errors.New("invalid request body: " + err.Error())
+	}
+	return &input, nil
+}
+
+func gameRuleError(c *fiber.Ctx, err error) error {
+	var validationErr *service_board.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": validationErr.Error()})
+	case errors.Is(err, game_rules.ErrRuleNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Game rule not found"})
+	case errors.Is(err, game_rules.ErrVersionNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Game rule version not found"})
+	case errors.Is(err, game_rules.ErrBoardgameNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Board game not found"})
+	default:
+		log.Println("Game rule operation failed ->", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save game rule"})
+	}
+}
@@ -0,0 +1,148 @@
+package service_board
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"guru-game/internal/db/repository/boardgame"
+	"guru-game/models"
+)
+
+// Export formats. jsonl and ndjson are the same newline-delimited JSON stream.
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatJSONL  = "jsonl"
+	ExportFormatNDJSON = "ndjson"
+)
+
+// ExportField extracts one column of an export row
+type ExportField struct {
+	name  string
+	value func(*models.BoardGame) interface{}
+}
+
+var exportFields = []ExportField{
+	{"id", func(bg *models.BoardGame) interface{} { return bg.ID }},
+	{"external_id", func(bg *models.BoardGame) interface{} { return bg.ExternalID }},
+	{"title", func(bg *models.BoardGame) interface{} { return bg.Title }},
+	{"description", func(bg *models.BoardGame) interface{} { return bg.Description }},
+	{"min_players", func(bg *models.BoardGame) interface{} { return bg.MinPlayers }},
+	{"max_players", func(bg *models.BoardGame) interface{} { return bg.MaxPlayers }},
+	{"play_time_min", func(bg *models.BoardGame) interface{} { return bg.PlayTimeMin }},
+	{"play_time_max", func(bg *models.BoardGame) interface{} { return bg.PlayTimeMax }},
+	{"categories", func(bg *models.BoardGame) interface{} { return bg.Categories }},
+	{"rating_avg", func(bg *models.BoardGame) interface{} { return bg.RatingAvg }},
+	{"rating_count", func(bg *models.BoardGame) interface{} { return bg.RatingCount }},
+	{"popularity_score", func(bg *models.BoardGame) interface{} { return bg.PopularityScore }},
+	{"image_url", func(bg *models.BoardGame) interface{} { return bg.ImageURL }},
+	{"created_at", func(bg *models.BoardGame) interface{} { return bg.CreatedAt }},
+	{"updated_at", func(bg *models.BoardGame) interface{} { return bg.UpdatedAt }},
+}
+
+// ResolveExportFields turns a comma-separated field list into export columns. An empty list selects every field.
+func ResolveExportFields(list string) ([]ExportField, error) {
+	if strings.TrimSpace(list) == "" {
+		return exportFields, nil
+	}
+
+	byName := make(map[string]ExportField, len(exportFields))
+	for _, f := range exportFields {
+		byName[f.name] = f
+	}
+
+	var selected []ExportField
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		f, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown export field %q", name)
+		}
+		selected = append(selected, f)
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no export fields selected")
+	}
+	return selected, nil
+}
+
+// ExportContentType returns the Content-Type for an export format, or "" if the format is unsupported
+func ExportContentType(format string) string {
+	switch format {
+	case ExportFormatCSV:
+		return "text/csv; charset=utf-8"
+	case ExportFormatJSONL, ExportFormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return ""
+	}
+}
+
+// ExportBoardGames streams the board games matching filter to w, writing each row as it is scanned
+func ExportBoardGames(ctx context.Context, repo boardgame.BoardGameRepository, w io.Writer, format string, fields []ExportField, filter boardgame.StreamFilter) (int, error) {
+	count := 0
+
+	switch format {
+	case ExportFormatCSV:
+		cw := csv.NewWriter(w)
+		header := make([]string, len(fields))
+		for i, f := range fields {
+			header[i] = f.name
+		}
+		if err := cw.Write(header); err != nil {
+			return 0, err
+		}
+
+		record := make([]string, len(fields))
+		err := repo.Stream(ctx, filter, func(bg *models.BoardGame) error {
+			for i, f := range fields {
+				record[i] = formatCSVValue(f.value(bg))
+			}
+			count++
+			return cw.Write(record)
+		})
+		cw.Flush()
+		if err != nil {
+			return count, err
+		}
+		return count, cw.Error()
+
+	case ExportFormatJSONL, ExportFormatNDJSON:
+		enc := json.NewEncoder(w)
+		err := repo.Stream(ctx, filter, func(bg *models.BoardGame) error {
+			row := make(map[string]interface{}, len(fields))
+			for _, f := range fields {
+				row[f.name] = f.value(bg)
+			}
+			count++
+			return enc.Encode(row)
+		})
+		return count, err
+
+	default:
+		return 0, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+func formatCSVValue(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case int:
+		return strconv.Itoa(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case time.Time:
+		return value.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,157 @@
+package service_board
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"time"
+
+	"guru-game/models"
+)
+
+// Step change kinds reported by DiffGameRuleVersions
+const (
+	StepAdded    = "added"
+	StepRemoved  = "removed"
+	StepModified = "modified"
+)
+
+// RuleDiff describes the differences between two versions of a rule set
+type RuleDiff struct {
+	RuleID      int         `json:"rule_id"`
+	FromVersion int         `json:"from_version"`
+	ToVersion   int         `json:"to_version"`
+	Title       *FieldDiff  `json:"title,omitempty"`
+	Language    *FieldDiff  `json:"language,omitempty"`
+	Steps       []StepDiff  `json:"steps"`
+	From        VersionInfo `json:"from"`
+	To          VersionInfo `json:"to"`
+}
+
+// VersionInfo identifies who saved a version and when
+type VersionInfo struct {
+	AuthorID  *int64    `json:"author_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FieldDiff is a changed scalar value
+type FieldDiff struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// StepDiff is a step that was added, removed or modified, matched by step number
+type StepDiff struct {
+	Step   int              `json:"step"`
+	Change string           `json:"change"`
+	Fields []string         `json:"fields,omitempty"`
+	From   *models.RuleStep `json:"from,omitempty"`
+	To     *models.RuleStep `json:"to,omitempty"`
+}
+
+// GetGameRuleVersions lists the stored versions of a rule set
+func (s *GameRuleService) GetGameRuleVersions(ctx context.Context, ruleID int) ([]models.GameRuleVersion, error) {
+	if _, err := s.repo.GetByID(ctx, ruleID); err != nil {
+		return nil, err
+	}
+	versions, err := s.repo.GetVersions(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if versions == nil {
+		versions = []models.GameRuleVersion{}
+	}
+	return versions, nil
+}
+
+// GetGameRuleVersion fetches one stored version of a rule set
+func (s *GameRuleService) GetGameRuleVersion(ctx context.Context, ruleID, version int) (*models.GameRuleVersion, error) {
+	return s.repo.GetVersion(ctx, ruleID, version)
+}
+
+// DiffGameRuleVersions compares two stored versions of a rule set
+func (s *GameRuleService) DiffGameRuleVersions(ctx context.Context, ruleID, fromVersion, toVersion int) (*RuleDiff, error) {
+	from, err := s.repo.GetVersion(ctx, ruleID, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.repo.GetVersion(ctx, ruleID, toVersion)
+	if err != nil {
+		return nil, err
+	}
+	return DiffRuleVersions(from, to), nil
+}
+
+// DiffRuleVersions compares two rule set snapshots
+func DiffRuleVersions(from, to *models.GameRuleVersion) *RuleDiff {
+	diff := &RuleDiff{
+		RuleID:      to.RuleID,
+		FromVersion: from.Version,
+		ToVersion:   to.Version,
+		Steps:       []StepDiff{},
+		From:        VersionInfo{AuthorID: from.AuthorID, CreatedAt: from.CreatedAt},
+		To:          VersionInfo{AuthorID: to.AuthorID, CreatedAt: to.CreatedAt},
+	}
+	if from.Title != to.Title {
+		diff.Title = &FieldDiff{From: from.Title, To: to.Title}
+	}
+	if from.Language != to.Language {
+		diff.Language = &FieldDiff{From: from.Language, To: to.Language}
+	}
+
+	fromSteps := stepsByNumber(from.Steps)
+	toSteps := stepsByNumber(to.Steps)
+
+	numbers := make([]int, 0, len(fromSteps)+len(toSteps))
+	for n := range fromSteps {
+		numbers = append(numbers, n)
+	}
+	for n := range toSteps {
+		if _, ok := fromSteps[n]; !ok {
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+
+	for _, n := range numbers {
+		before, hadBefore := fromSteps[n]
+		after, hasAfter := toSteps[n]
+		switch {
+		case !hadBefore:
+			diff.Steps = append(diff.Steps, StepDiff{Step: n, Change: StepAdded, To: after})
+		case !hasAfter:
+			diff.Steps = append(diff.Steps, StepDiff{Step: n, Change: StepRemoved, From: before})
+		default:
+			if fields := changedStepFields(before, after); len(fields) > 0 {
+				diff.Steps = append(diff.Steps, StepDiff{Step: n, Change: StepModified, Fields: fields, From: before, To: after})
+			}
+		}
+	}
+
+	return diff
+}
+
+func stepsByNumber(steps []models.RuleStep) map[int]*models.RuleStep {
+	m := make(map[int]*models.RuleStep, len(steps))
+	for i := range steps {
+		m[steps[i].Step] = &steps[i]
+	}
+	return m
+}
+
+func changedStepFields(a, b *models.RuleStep) []string {
+	var fields []string
+	if a.Title != b.Title {
+		fields = append(fields, "title")
+	}
+	if a.Description != b.Description {
+		fields = append(fields, "description")
+	}
+	if a.ImageURL != b.ImageURL {
+		fields = append(fields, "image_url")
+	}
+	if !reflect.DeepEqual(a.Tips, b.Tips) {
+		fields = append(fields, "tips")
+	}
+	return fields
+}
@@ -2,8 +2,11 @@ package service_board
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"unicode/utf8"
 
 	"guru-game/internal/db/repository/game_rules"
 	"guru-game/models"
@@ -27,7 +30,109 @@ func (s *GameRuleService) GetGameRulesByBoardgameID(ctx context.Context, boardga
 		return nil, fmt.Errorf("failed to fetch game rules from repository: %w", err)
 	}
 
-	// You might add business logic here if needed
+	if gameRules == nil {
+		gameRules = []models.GameRule{}
+	}
 
 	return gameRules, nil
 }
+
+// RuleLanguages returns the distinct languages available in a list of rule sets, in first-seen order
+func RuleLanguages(rules []models.GameRule) []string {
+	var languages []string
+	seen := make(map[string]bool)
+	for _, rule := range rules {
+		if !seen[rule.Language] {
+			seen[rule.Language] = true
+			languages = append(languages, rule.Language)
+		}
+	}
+	return languages
+}
+
+// FilterRulesByLanguage keeps the rule sets written in language. If none match, it falls back to
+// DefaultRuleLanguage and then to the first available language, returning the language it used.
+func FilterRulesByLanguage(rules []models.GameRule, language string) ([]models.GameRule, string) {
+	available := RuleLanguages(rules)
+	if len(available) == 0 {
+		return []models.GameRule{}, language
+	}
+
+	chosen := ""
+	for _, candidate := range []string{language, models.DefaultRuleLanguage, available[0]} {
+		for _, lang := range available {
+			if candidate != "" && lang == candidate {
+				chosen = lang
+				break
+			}
+		}
+		if chosen != "" {
+			break
+		}
+	}
+
+	filtered := make([]models.GameRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Language == chosen {
+			filtered = append(filtered, rule)
+		}
+	}
+	return filtered, chosen
+}
+
+// CreateGameRule validates and stores a new rule set for a boardgame
+func (s *GameRuleService) CreateGameRule(ctx context.Context, rule *models.GameRule) error {
+	if err := rule.Validate(); err != nil {
+		return &ValidationError{Err: err}
+	}
+	return s.repo.Create(ctx, rule)
+}
+
+// UpdateGameRule validates and replaces an existing rule set
+func (s *GameRuleService) UpdateGameRule(ctx context.Context, rule *models.GameRule) error {
+	if err := rule.Validate(); err != nil {
+		return &ValidationError{Err: err}
+	}
+	return s.repo.Update(ctx, rule)
+}
+
+// DeleteGameRule removes a rule set
+func (s *GameRuleService) DeleteGameRule(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// SearchGameRules runs a full-text search over rule steps
+func (s *GameRuleService) SearchGameRules(ctx context.Context, params game_rules.SearchParams) ([]models.RuleSearchHit, error) {
+	params.Query = strings.TrimSpace(params.Query)
+	if params.Query == "" {
+		return nil, &ValidationError{Err: errors.New("q is required")}
+	}
+	if utf8.RuneCountInString(params.Query) > 200 {
+		return nil, &ValidationError{Err: errors.New("q must be at most 200 characters")}
+	}
+	if params.Limit <= 0 || params.Limit > 50 {
+		params.Limit = 10
+	}
+	if params.Offset < 0 {
+		params.Offset = 0
+	}
+	params.Language = strings.ToLower(params.Language)
+
+	hits, err := s.repo.Search(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if hits == nil {
+		hits = []models.RuleSearchHit{}
+	}
+	return hits, nil
+}
+
+// ValidationError wraps input problems so handlers can answer 400 instead of 500
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string { return e.Err.Error() }
+
+func (e *ValidationError) Unwrap() error { return e.Err }
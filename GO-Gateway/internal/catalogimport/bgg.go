@@ -0,0 +1,144 @@
+package catalogimport
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+
+	"guru-game/models"
+)
+
+// bggItems mirrors the subset of a BoardGameGeek XML API2 "thing" document that we import
+type bggItems struct {
+	Items []bggItem `xml:"item"`
+}
+
+type bggItem struct {
+	Type        string      `xml:"type,attr"`
+	ID          string      `xml:"id,attr"`
+	Image       string      `xml:"image"`
+	Names       []bggName   `xml:"name"`
+	Description string      `xml:"description"`
+	MinPlayers  bggValue    `xml:"minplayers"`
+	MaxPlayers  bggValue    `xml:"maxplayers"`
+	PlayingTime bggValue    `xml:"playingtime"`
+	MinPlayTime bggValue    `xml:"minplaytime"`
+	MaxPlayTime bggValue    `xml:"maxplaytime"`
+	Links       []bggLink   `xml:"link"`
+	Ratings     *bggRatings `xml:"statistics>ratings"`
+}
+
+type bggName struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type bggValue struct {
+	Value string `xml:"value,attr"`
+}
+
+type bggLink struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type bggRatings struct {
+	UsersRated bggValue `xml:"usersrated"`
+	Average    bggValue `xml:"average"`
+}
+
+// ParseBGG reads a BoardGameGeek XML API2 "thing" document into import rows.
+// External IDs are prefixed with "bgg:" so they cannot clash with CSV identifiers.
+func ParseBGG(r io.Reader) ([]Row, error) {
+	var doc bggItems
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode BGG xml: %w", err)
+	}
+
+	rows := make([]Row, 0, len(doc.Items))
+	for i, item := range doc.Items {
+		bg, err := bggItemToBoardGame(item)
+		rows = append(rows, Row{Line: i + 1, BoardGame: bg, Err: err})
+	}
+	return rows, nil
+}
+
+func bggItemToBoardGame(item bggItem) (models.BoardGame, error) {
+	bg := models.BoardGame{
+		Description: strings.TrimSpace(html.UnescapeString(item.Description)),
+		ImageURL:    strings.TrimSpace(item.Image),
+	}
+
+	if item.ID == "" {
+		return bg, fmt.Errorf("item has no id")
+	}
+	bg.ExternalID = "bgg:" + item.ID
+
+	if item.Type != "" && item.Type != "boardgame" && item.Type != "boardgameexpansion" {
+		return bg, fmt.Errorf("unsupported item type %q", item.Type)
+	}
+
+	for _, name := range item.Names {
+		if name.Type == "primary" {
+			bg.Title = name.Value
+			break
+		}
+	}
+	if bg.Title == "" && len(item.Names) > 0 {
+		bg.Title = item.Names[0].Value
+	}
+
+	var categories []string
+	for _, link := range item.Links {
+		if link.Type == "boardgamecategory" {
+			categories = append(categories, link.Value)
+		}
+	}
+	bg.Categories = NormalizeCategories(categories)
+
+	var err error
+	if bg.MinPlayers, err = bggInt(item.MinPlayers, "minplayers"); err != nil {
+		return bg, err
+	}
+	if bg.MaxPlayers, err = bggInt(item.MaxPlayers, "maxplayers"); err != nil {
+		return bg, err
+	}
+	if bg.PlayTimeMin, err = bggInt(item.MinPlayTime, "minplaytime"); err != nil {
+		return bg, err
+	}
+	if bg.PlayTimeMax, err = bggInt(item.MaxPlayTime, "maxplaytime"); err != nil {
+		return bg, err
+	}
+	if bg.PlayTimeMax == 0 {
+		if bg.PlayTimeMax, err = bggInt(item.PlayingTime, "playingtime"); err != nil {
+			return bg, err
+		}
+	}
+
+	if item.Ratings != nil {
+		if bg.RatingCount, err = bggInt(item.Ratings.UsersRated, "usersrated"); err != nil {
+			return bg, err
+		}
+		if item.Ratings.Average.Value != "" {
+			if bg.RatingAvg, err = strconv.ParseFloat(item.Ratings.Average.Value, 64); err != nil {
+				return bg, fmt.Errorf("average must be a number, got %q", item.Ratings.Average.Value)
+			}
+		}
+	}
+
+	return bg, nil
+}
+
+func bggInt(v bggValue, field string) (int, error) {
+	if v.Value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v.Value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer, got %q", field, v.Value)
+	}
+	return n, nil
+}
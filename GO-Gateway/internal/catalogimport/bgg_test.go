@@ -0,0 +1,86 @@
+package catalogimport
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"guru-game/models"
+)
+
+func TestParseBGG(t *testing.T) {
+	input := `<?xml version="1.0" encoding="utf-8"?>
+<items>
+	<item type="boardgame" id="13">
+		<image> https://img/catan.jpg </image>
+		<name type="alternate" value="Die Siedler von Catan"/>
+		<name type="primary" value="Catan"/>
+		<description>Trade &amp;amp; build</description>
+		<minplayers value="3"/>
+		<maxplayers value="4"/>
+		<playingtime value="120"/>
+		<minplaytime value="60"/>
+		<maxplaytime value="120"/>
+		<link type="boardgamecategory" value="Negotiation"/>
+		<link type="boardgamemechanic" value="Dice Rolling"/>
+		<link type="boardgamecategory" value="Economic"/>
+		<statistics><ratings><usersrated value="120"/><average value="7.1"/></ratings></statistics>
+	</item>
+	<item type="boardgameexpansion" id="926">
+		<name type="alternate" value="Seafarers"/>
+		<playingtime value="90"/>
+	</item>
+	<item type="videogame" id="1"><name type="primary" value="Not a board game"/></item>
+	<item type="boardgame"><name type="primary" value="No id"/></item>
+	<item type="boardgame" id="2"><minplayers value="two"/></item>
+	<item type="boardgame" id="3"><statistics><ratings><average value="high"/></ratings></statistics></item>
+</items>`
+
+	rows, err := ParseBGG(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseBGG() = %v", err)
+	}
+
+	want := []struct {
+		game    models.BoardGame
+		wantErr string
+	}{
+		{models.BoardGame{
+			ExternalID: "bgg:13", Title: "Catan", Description: "Trade & build", ImageURL: "https://img/catan.jpg",
+			MinPlayers: 3, MaxPlayers: 4, PlayTimeMin: 60, PlayTimeMax: 120,
+			Categories: "Economic,Negotiation", RatingAvg: 7.1, RatingCount: 120,
+		}, ""},
+		// No primary name, and playingtime fills in a missing maxplaytime
+		{models.BoardGame{ExternalID: "bgg:926", Title: "Seafarers", PlayTimeMax: 90}, ""},
+		{models.BoardGame{}, `unsupported item type "videogame"`},
+		{models.BoardGame{}, "item has no id"},
+		{models.BoardGame{}, "minplayers must be an integer"},
+		{models.BoardGame{}, "average must be a number"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(rows), len(want))
+	}
+	for i, row := range rows {
+		if row.Line != i+1 {
+			t.Errorf("row %d line = %d, want %d", i, row.Line, i+1)
+		}
+		if want[i].wantErr != "" {
+			if row.Err == nil || !strings.Contains(row.Err.Error(), want[i].wantErr) {
+				t.Errorf("row %d error = %v, want %q", i, row.Err, want[i].wantErr)
+			}
+			continue
+		}
+		if row.Err != nil {
+			t.Errorf("row %d error = %v", i, row.Err)
+		}
+		if !reflect.DeepEqual(row.BoardGame, want[i].game) {
+			t.Errorf("row %d = %+v, want %+v", i, row.BoardGame, want[i].game)
+		}
+	}
+}
+
+func TestParseBGGInvalidXML(t *testing.T) {
+	if _, err := ParseBGG(strings.NewReader("<items><item>")); err == nil {
+		t.Fatal("ParseBGG() accepted truncated xml")
+	}
+}
@@ -0,0 +1,131 @@
+package catalogimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"guru-game/models"
+)
+
+// csvColumns lists the header names understood by ParseCSV; any other column is rejected so a
+// misspelt header does not silently drop its values. Only external_id and title are required.
+var csvColumns = []string{
+	"external_id", "title", "description", "min_players", "max_players",
+	"play_time_min", "play_time_max", "categories", "image_url",
+	"rating_avg", "rating_count", "popularity_score",
+}
+
+// ParseCSV reads a header-prefixed CSV file into import rows.
+// Rows that fail to parse are returned with Err set so the caller can report them.
+func ParseCSV(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("csv file is empty")
+		}
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	known := make(map[string]bool, len(csvColumns))
+	for _, name := range csvColumns {
+		known[name] = true
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !known[name] {
+			return nil, fmt.Errorf("csv header has unknown column %q", name)
+		}
+		index[name] = i
+	}
+	for _, required := range []string{"external_id", "title"} {
+		if _, ok := index[required]; !ok {
+			return nil, fmt.Errorf("csv header is missing required column %q", required)
+		}
+	}
+
+	var rows []Row
+	line := 1
+	for {
+		record, err := reader.Read()
+		line++
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			rows = append(rows, Row{Line: line, Err: fmt.Errorf("invalid csv record: %w", err)})
+			continue
+		}
+
+		get := func(column string) string {
+			i, ok := index[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		bg, err := csvRecordToBoardGame(get)
+		rows = append(rows, Row{Line: line, BoardGame: bg, Err: err})
+	}
+
+	return rows, nil
+}
+
+func csvRecordToBoardGame(get func(string) string) (models.BoardGame, error) {
+	bg := models.BoardGame{
+		ExternalID:  get("external_id"),
+		Title:       get("title"),
+		Description: get("description"),
+		Categories:  NormalizeCategories(strings.FieldsFunc(get("categories"), isCategorySeparator)),
+		ImageURL:    get("image_url"),
+	}
+
+	ints := map[string]*int{
+		"min_players":   &bg.MinPlayers,
+		"max_players":   &bg.MaxPlayers,
+		"play_time_min": &bg.PlayTimeMin,
+		"play_time_max": &bg.PlayTimeMax,
+		"rating_count":  &bg.RatingCount,
+	}
+	for column, dst := range ints {
+		value := get(column)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return bg, fmt.Errorf("%s must be an integer, got %q", column, value)
+		}
+		*dst = n
+	}
+
+	floats := map[string]*float64{
+		"rating_avg":       &bg.RatingAvg,
+		"popularity_score": &bg.PopularityScore,
+	}
+	for column, dst := range floats {
+		value := get(column)
+		if value == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return bg, fmt.Errorf("%s must be a number, got %q", column, value)
+		}
+		*dst = f
+	}
+
+	return bg, nil
+}
+
+func isCategorySeparator(r rune) bool {
+	return r == ',' || r == ';' || r == '|'
+}
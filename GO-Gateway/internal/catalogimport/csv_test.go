@@ -0,0 +1,96 @@
+package catalogimport
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"guru-game/models"
+)
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		want     []models.BoardGame
+		wantErrs []string // per row; "" for a row that parsed
+	}{
+		{
+			name: "all columns",
+			input: "external_id,title,description,min_players,max_players,play_time_min,play_time_max,categories,image_url,rating_avg,rating_count,popularity_score\n" +
+				"csv:1,Catan,Trade and build,3,4,60,120,\"Strategy; Negotiation|strategy\",https://img/catan.png,7.1,120,3.5\n",
+			want: []models.BoardGame{{
+				ExternalID: "csv:1", Title: "Catan", Description: "Trade and build",
+				MinPlayers: 3, MaxPlayers: 4, PlayTimeMin: 60, PlayTimeMax: 120,
+				Categories: "Negotiation,Strategy", ImageURL: "https://img/catan.png",
+				RatingAvg: 7.1, RatingCount: 120, PopularityScore: 3.5,
+			}},
+			wantErrs: []string{""},
+		},
+		{
+			name:     "header is trimmed and case-insensitive, columns in any order",
+			input:    " Title , EXTERNAL_ID\n Azul , csv:2 \n",
+			want:     []models.BoardGame{{ExternalID: "csv:2", Title: "Azul"}},
+			wantErrs: []string{""},
+		},
+		{
+			name:     "malformed record is reported and the next one still parsed",
+			input:    "external_id,title,min_players\ncsv:3,Splendor\ncsv:7,Patchwork,2\n",
+			want:     []models.BoardGame{{}, {ExternalID: "csv:7", Title: "Patchwork", MinPlayers: 2}},
+			wantErrs: []string{"invalid csv record", ""},
+		},
+		{
+			name:     "bad numbers are reported per row",
+			input:    "external_id,title,min_players,rating_avg\ncsv:4,Root,two,\ncsv:5,Wingspan,1,high\ncsv:6,Cascadia,1,4.2\n",
+			want:     []models.BoardGame{{ExternalID: "csv:4", Title: "Root"}, {ExternalID: "csv:5", Title: "Wingspan", MinPlayers: 1}, {ExternalID: "csv:6", Title: "Cascadia", MinPlayers: 1, RatingAvg: 4.2}},
+			wantErrs: []string{"min_players must be an integer", "rating_avg must be a number", ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ParseCSV(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("ParseCSV() = %v", err)
+			}
+			if len(rows) != len(tt.want) {
+				t.Fatalf("got %d rows, want %d", len(rows), len(tt.want))
+			}
+			for i, row := range rows {
+				if row.Line != i+2 {
+					t.Errorf("row %d line = %d, want %d", i, row.Line, i+2)
+				}
+				if tt.wantErrs[i] == "" {
+					if row.Err != nil {
+						t.Errorf("row %d error = %v", i, row.Err)
+					}
+					if !reflect.DeepEqual(row.BoardGame, tt.want[i]) {
+						t.Errorf("row %d = %+v, want %+v", i, row.BoardGame, tt.want[i])
+					}
+				} else if row.Err == nil || !strings.Contains(row.Err.Error(), tt.wantErrs[i]) {
+					t.Errorf("row %d error = %v, want %q", i, row.Err, tt.wantErrs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseCSVHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"empty file", "", "csv file is empty"},
+		{"missing external_id", "title\nCatan\n", `missing required column "external_id"`},
+		{"missing title", "external_id\ncsv:1\n", `missing required column "title"`},
+		{"misspelt column", "external_id,title,min_player\ncsv:1,Catan,3\n", `unknown column "min_player"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCSV(strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ParseCSV() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"guru-game/internal/catalogimport"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ImportHandlers holds the dependencies for the catalogue import endpoint
+type ImportHandlers struct {
+	Importer *catalogimport.Importer
+}
+
+// NewImportHandlers creates a new ImportHandlers instance
+func NewImportHandlers(importer *catalogimport.Importer) *ImportHandlers {
+	return &ImportHandlers{Importer: importer}
+}
+
+// HandleImport accepts a CSV or BGG XML file (multipart field "file" or the raw request body)
+// and imports it into the catalogue. Pass dry_run=true to only report the diff.
+func (h *ImportHandlers) HandleImport(c *fiber.Ctx) error {
+	format := c.Query("format")
+	dryRun := c.QueryBool("dry_run", false)
+
+	var reader io.Reader
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			log.Printf("Error opening uploaded import file: %v", err)
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "failed to read uploaded file"})
+		}
+		defer file.Close()
+		reader = file
+		if format == "" {
+			format = FormatFromFilename(fileHeader.Filename)
+		}
+	} else {
+		body := c.Body()
+		if len(body) == 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "import file is required"})
+		}
+		reader = bytes.NewReader(body)
+		if format == "" && strings.Contains(c.Get(fiber.HeaderContentType), "xml") {
+			format = catalogimport.FormatBGG
+		}
+	}
+
+	if format == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "format is required (csv or bgg)"})
+	}
+
+	rows, err := catalogimport.Parse(format, reader)
+	if err != nil {
+		log.Printf("Error parsing import file: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	report := h.Importer.Import(rows, dryRun)
+
+	status := fiber.StatusOK
+	if report.Failed > 0 && report.Failed == len(report.Rows) {
+		status = fiber.StatusUnprocessableEntity
+	}
+	return c.Status(status).JSON(report)
+}
+
+// FormatFromFilename guesses the import format from a file extension
+func FormatFromFilename(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return catalogimport.FormatCSV
+	case ".xml":
+		return catalogimport.FormatBGG
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,229 @@
+package catalogimport
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+
+	"guru-game/internal/db/repository/boardgame"
+	"guru-game/models"
+)
+
+// Supported import formats
+const (
+	FormatCSV = "csv"
+	FormatBGG = "bgg"
+)
+
+// Row actions reported by the importer
+const (
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionUnchanged = "unchanged"
+	ActionError     = "error"
+)
+
+// Row is one parsed record from an import file
+type Row struct {
+	Line      int
+	BoardGame models.BoardGame
+	Err       error
+}
+
+// RowResult describes what happened (or would happen, on a dry run) to a single row
+type RowResult struct {
+	Line        int      `json:"line"`
+	ExternalID  string   `json:"external_id,omitempty"`
+	Title       string   `json:"title,omitempty"`
+	Action      string   `json:"action"`
+	BoardgameID int      `json:"boardgame_id,omitempty"`
+	Changes     []Change `json:"changes,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// Change is a single field difference between the stored game and the imported row
+type Change struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// Report summarises an import run
+type Report struct {
+	DryRun    bool        `json:"dry_run"`
+	Created   int         `json:"created"`
+	Updated   int         `json:"updated"`
+	Unchanged int         `json:"unchanged"`
+	Failed    int         `json:"failed"`
+	Rows      []RowResult `json:"rows"`
+}
+
+// Importer maps import rows onto board games and upserts them by external ID
+type Importer struct {
+	repo boardgame.BoardGameRepository
+}
+
+// NewImporter creates a new Importer
+func NewImporter(repo boardgame.BoardGameRepository) *Importer {
+	return &Importer{repo: repo}
+}
+
+// Parse decodes an import file in the given format
+func Parse(format string, r io.Reader) ([]Row, error) {
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		return ParseCSV(r)
+	case FormatBGG, "xml":
+		return ParseBGG(r)
+	default:
+		return nil, fmt.Errorf("unsupported import format %q (expected csv or bgg)", format)
+	}
+}
+
+// Import validates and diffs each row against the catalogue. Unless dryRun is set, new and
+// changed rows are upserted. A failing row never aborts the run; it is reported instead.
+func (i *Importer) Import(rows []Row, dryRun bool) *Report {
+	report := &Report{DryRun: dryRun, Rows: make([]RowResult, 0, len(rows))}
+	seen := make(map[string]int)
+
+	for _, row := range rows {
+		result := RowResult{
+			Line:       row.Line,
+			ExternalID: row.BoardGame.ExternalID,
+			Title:      row.BoardGame.Title,
+		}
+
+		err := row.Err
+		if err == nil {
+			err = Validate(&row.BoardGame)
+		}
+		if err == nil {
+			if firstLine, dup := seen[row.BoardGame.ExternalID]; dup {
+				err = fmt.Errorf("duplicate external_id, already seen on line %d", firstLine)
+			}
+		}
+		if err == nil {
+			seen[row.BoardGame.ExternalID] = row.Line
+			err = i.applyRow(&row.BoardGame, dryRun, &result)
+		}
+
+		if err != nil {
+			result.Action = ActionError
+			result.Error = err.Error()
+			report.Failed++
+		} else {
+			switch result.Action {
+			case ActionCreate:
+				report.Created++
+			case ActionUpdate:
+				report.Updated++
+			case ActionUnchanged:
+				report.Unchanged++
+			}
+		}
+		report.Rows = append(report.Rows, result)
+	}
+
+	log.Printf("Catalogue import finished (dry_run=%v): %d created, %d updated, %d unchanged, %d failed",
+		dryRun, report.Created, report.Updated, report.Unchanged, report.Failed)
+
+	return report
+}
+
+func (i *Importer) applyRow(bg *models.BoardGame, dryRun bool, result *RowResult) error {
+	existing, err := i.repo.GetByExternalID(bg.ExternalID)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		result.Action = ActionCreate
+	} else {
+		result.BoardgameID = existing.ID
+		result.Changes = Diff(existing, bg)
+		if len(result.Changes) == 0 {
+			result.Action = ActionUnchanged
+			return nil
+		}
+		result.Action = ActionUpdate
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	id, err := i.repo.UpsertByExternalID(bg)
+	if err != nil {
+		return err
+	}
+	result.BoardgameID = id
+	return nil
+}
+
+// Validate checks that an imported game has the fields the catalogue relies on
+func Validate(bg *models.BoardGame) error {
+	var problems []string
+	if bg.ExternalID == "" {
+		problems = append(problems, "external_id is required")
+	}
+	if strings.TrimSpace(bg.Title) == "" {
+		problems = append(problems, "title is required")
+	}
+	if bg.MinPlayers < 0 || bg.MaxPlayers < 0 || bg.PlayTimeMin < 0 || bg.PlayTimeMax < 0 {
+		problems = append(problems, "player counts and play times cannot be negative")
+	}
+	if bg.MaxPlayers > 0 && bg.MinPlayers > bg.MaxPlayers {
+		problems = append(problems, "min_players cannot exceed max_players")
+	}
+	if bg.PlayTimeMax > 0 && bg.PlayTimeMin > bg.PlayTimeMax {
+		problems = append(problems, "play_time_min cannot exceed play_time_max")
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Diff lists the importable fields that differ between the stored and imported game
+func Diff(existing, imported *models.BoardGame) []Change {
+	var changes []Change
+	add := func(field string, from, to interface{}) {
+		if from != to {
+			changes = append(changes, Change{Field: field, From: from, To: to})
+		}
+	}
+
+	add("title", existing.Title, imported.Title)
+	add("description", existing.Description, imported.Description)
+	add("min_players", existing.MinPlayers, imported.MinPlayers)
+	add("max_players", existing.MaxPlayers, imported.MaxPlayers)
+	add("play_time_min", existing.PlayTimeMin, imported.PlayTimeMin)
+	add("play_time_max", existing.PlayTimeMax, imported.PlayTimeMax)
+	add("categories", NormalizeCategories(strings.Split(existing.Categories, ",")), imported.Categories)
+	add("image_url", existing.ImageURL, imported.ImageURL)
+
+	return changes
+}
+
+// NormalizeCategories trims, de-duplicates and sorts category names into the comma-separated form stored on boardgames
+func NormalizeCategories(categories []string) string {
+	set := make(map[string]struct{}, len(categories))
+	var out []string
+	for _, category := range categories {
+		category = strings.TrimSpace(category)
+		if category == "" {
+			continue
+		}
+		key := strings.ToLower(category)
+		if _, ok := set[key]; ok {
+			continue
+		}
+		set[key] = struct{}{}
+		out = append(out, category)
+	}
+	sort.Strings(out)
+	return strings.Join(out, ",")
+}
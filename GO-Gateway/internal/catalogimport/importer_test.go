@@ -0,0 +1,150 @@
+package catalogimport
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"guru-game/internal/db/repository/boardgame"
+	"guru-game/models"
+)
+
+// fakeRepo holds stored games by external ID and records upserts; other
+// BoardGameRepository methods are not used
+type fakeRepo struct {
+	boardgame.BoardGameRepository
+	games    map[string]*models.BoardGame
+	upserted []string
+}
+
+func (r *fakeRepo) GetByExternalID(externalID string) (*models.BoardGame, error) {
+	if externalID == "csv:broken" {
+		return nil, errors.New("connection reset")
+	}
+	bg, ok := r.games[externalID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *bg
+	return &copied, nil
+}
+
+func (r *fakeRepo) UpsertByExternalID(bg *models.BoardGame) (int, error) {
+	r.upserted = append(r.upserted, bg.ExternalID)
+	if stored, ok := r.games[bg.ExternalID]; ok {
+		return stored.ID, nil
+	}
+	return 100 + len(r.upserted), nil
+}
+
+func TestImport(t *testing.T) {
+	stored := map[string]*models.BoardGame{
+		"csv:1": {ID: 1, ExternalID: "csv:1", Title: "Catan", MinPlayers: 3, MaxPlayers: 4, Categories: "Strategy, Negotiation"},
+		"csv:2": {ID: 2, ExternalID: "csv:2", Title: "Azul", MinPlayers: 2, MaxPlayers: 4},
+	}
+	rows := []Row{
+		{Line: 2, BoardGame: models.BoardGame{ExternalID: "csv:1", Title: "Catan", MinPlayers: 3, MaxPlayers: 4, Categories: "Negotiation,Strategy"}},
+		{Line: 3, BoardGame: models.BoardGame{ExternalID: "csv:2", Title: "Azul", MinPlayers: 2, MaxPlayers: 5}},
+		{Line: 4, BoardGame: models.BoardGame{ExternalID: "csv:3", Title: "Root", MinPlayers: 2, MaxPlayers: 4}},
+		{Line: 5, BoardGame: models.BoardGame{ExternalID: "csv:3", Title: "Root again"}},
+		{Line: 6, BoardGame: models.BoardGame{ExternalID: "csv:4", Title: "Wingspan"}, Err: errors.New("min_players must be an integer")},
+		{Line: 7, BoardGame: models.BoardGame{ExternalID: "csv:5", Title: "Cascadia", MinPlayers: 5, MaxPlayers: 2}},
+		{Line: 8, BoardGame: models.BoardGame{ExternalID: "csv:broken", Title: "Broken"}},
+	}
+	want := []RowResult{
+		{Line: 2, ExternalID: "csv:1", Title: "Catan", Action: ActionUnchanged, BoardgameID: 1},
+		{Line: 3, ExternalID: "csv:2", Title: "Azul", Action: ActionUpdate, BoardgameID: 2, Changes: []Change{{Field: "max_players", From: 4, To: 5}}},
+		{Line: 4, ExternalID: "csv:3", Title: "Root", Action: ActionCreate},
+		{Line: 5, ExternalID: "csv:3", Title: "Root again", Action: ActionError, Error: "duplicate external_id, already seen on line 4"},
+		{Line: 6, ExternalID: "csv:4", Title: "Wingspan", Action: ActionError, Error: "min_players must be an integer"},
+		{Line: 7, ExternalID: "csv:5", Title: "Cascadia", Action: ActionError, Error: "min_players cannot exceed max_players"},
+		{Line: 8, ExternalID: "csv:broken", Title: "Broken", Action: ActionError, Error: "connection reset"},
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		repo := &fakeRepo{games: stored}
+		report := NewImporter(repo).Import(rows, true)
+
+		if len(repo.upserted) != 0 {
+			t.Errorf("dry run upserted %v", repo.upserted)
+		}
+		if !reflect.DeepEqual(report.Rows, want) {
+			t.Errorf("rows = %+v, want %+v", report.Rows, want)
+		}
+		if report.Created != 1 || report.Updated != 1 || report.Unchanged != 1 || report.Failed != 4 || !report.DryRun {
+			t.Errorf("report = %+v, want 1 created, 1 updated, 1 unchanged, 4 failed, dry run", report)
+		}
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		repo := &fakeRepo{games: stored}
+		report := NewImporter(repo).Import(rows, false)
+
+		if want := []string{"csv:2", "csv:3"}; !reflect.DeepEqual(repo.upserted, want) {
+			t.Errorf("upserted %v, want %v", repo.upserted, want)
+		}
+		if got := report.Rows[2]; got.Action != ActionCreate || got.BoardgameID != 102 {
+			t.Errorf("created row = %+v, want the new ID 102", got)
+		}
+		if report.Created != 1 || report.Updated != 1 || report.Unchanged != 1 || report.Failed != 4 || report.DryRun {
+			t.Errorf("report = %+v, want 1 created, 1 updated, 1 unchanged, 4 failed", report)
+		}
+	})
+}
+
+func TestImportDuplicateAfterInvalidRow(t *testing.T) {
+	// An invalid row does not claim its external_id, so a later valid row with the same ID is imported
+	rows := []Row{
+		{Line: 2, BoardGame: models.BoardGame{ExternalID: "csv:1"}},
+		{Line: 3, BoardGame: models.BoardGame{ExternalID: "csv:1", Title: "Catan"}},
+	}
+	report := NewImporter(&fakeRepo{}).Import(rows, true)
+	if report.Rows[0].Action != ActionError || report.Rows[1].Action != ActionCreate {
+		t.Errorf("actions = %s, %s, want error, create", report.Rows[0].Action, report.Rows[1].Action)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	existing := &models.BoardGame{
+		Title: "Catan", Description: "Trade", MinPlayers: 3, MaxPlayers: 4,
+		PlayTimeMin: 60, PlayTimeMax: 120, Categories: " strategy ,Negotiation", ImageURL: "a.png",
+		RatingAvg: 7.1, RatingCount: 10,
+	}
+	same := *existing
+	same.Categories = NormalizeCategories(strings.Split(existing.Categories, ","))
+	same.RatingAvg, same.RatingCount = 9, 99
+	if changes := Diff(existing, &same); len(changes) != 0 {
+		t.Errorf("Diff() = %+v, want no changes; ratings and category order are not compared", changes)
+	}
+
+	changed := same
+	changed.Title = "Catan 5th edition"
+	changed.PlayTimeMax = 90
+	changed.Categories = "Negotiation"
+	want := []Change{
+		{Field: "title", From: "Catan", To: "Catan 5th edition"},
+		{Field: "play_time_max", From: 120, To: 90},
+		{Field: "categories", From: "Negotiation,strategy", To: "Negotiation"},
+	}
+	if changes := Diff(existing, &changed); !reflect.DeepEqual(changes, want) {
+		t.Errorf("Diff() = %+v, want %+v", changes, want)
+	}
+}
+
+func TestNormalizeCategories(t *testing.T) {
+	tests := []struct {
+		in   []string
+		want string
+	}{
+		{nil, ""},
+		{[]string{" ", ""}, ""},
+		{[]string{"Strategy", " Negotiation ", "strategy"}, "Negotiation,Strategy"},
+		{[]string{"Party", "Dice"}, "Dice,Party"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeCategories(tt.in); got != tt.want {
+			t.Errorf("NormalizeCategories(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
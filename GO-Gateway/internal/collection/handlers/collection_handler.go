@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"guru-game/internal/auth/jwt"
+	"guru-game/internal/boardgame/handlers_board"
+	"guru-game/internal/collection"
+	"guru-game/internal/db/repository/collections"
+	"guru-game/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CollectionHandlers holds the dependencies for collection and shelf handlers
+type CollectionHandlers struct {
+	Service *collection.Service
+}
+
+// NewCollectionHandlers creates a new CollectionHandlers instance
+func NewCollectionHandlers(service *collection.Service) *CollectionHandlers {
+	return &CollectionHandlers{Service: service}
+}
+
+// HandleListCollection lists the caller's collection.
+// Query params: status, sort=added|title|rating|popularity|priority, limit, offset and the catalogue filters
+func (h *CollectionHandlers) HandleListCollection(c *fiber.Ctx) error {
+	userID, ok := jwt.UserIDFromCtx(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	q, err := gameQuery(c, userID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	q.Status = c.Query("status")
+
+	games, err := h.Service.List(c.Context(), q)
+	if err != nil {
+		return collectionError(c, err)
+	}
+	return c.JSON(fiber.Map{"games": games, "limit": q.Limit, "offset": q.Offset})
+}
+
+// HandleGetCollectionGame returns the statuses the caller gave a game
+func (h *CollectionHandlers) HandleGetCollectionGame(c *fiber.Ctx) error {
+	userID, gameID, ok := jwt.UserAndIDParam[int](c, "game_id", "board game")
+	if !ok {
+		return nil
+	}
+
+	entries, err := h.Service.Entries(c.Context(), userID, gameID)
+	if err != nil {
+		return collectionError(c, err)
+	}
+	return c.JSON(fiber.Map{"boardgame_id": gameID, "entries": entries})
+}
+
+// HandleSetCollectionStatus adds a status to a game: PUT /user/collection/:game_id/:status {"wishlist_priority": 2, "notes": "..."}
+func (h *CollectionHandlers) HandleSetCollectionStatus(c *fiber.Ctx) error {
+	userID, gameID, ok := jwt.UserAndIDParam[int](c, "game_id", "board game")
+	if !ok {
+		return nil
+	}
+
+	var entry models.CollectionEntry
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&entry); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+		}
+	}
+	entry.Status = c.Params("status")
+
+	if err := h.Service.SetStatus(c.Context(), userID, gameID, &entry); err != nil {
+		return collectionError(c, err)
+	}
+	return c.JSON(entry)
+}
+
+// HandleRemoveCollectionStatus removes a status from a game
+func (h *CollectionHandlers) HandleRemoveCollectionStatus(c *fiber.Ctx) error {
+	userID, gameID, ok := jwt.UserAndIDParam[int](c, "game_id", "board game")
+	if !ok {
+		return nil
+	}
+
+	if err := h.Service.RemoveStatus(c.Context(), userID, gameID, c.Params("status")); err != nil {
+		return collectionError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleListShelves lists the caller's shelves
+func (h *CollectionHandlers) HandleListShelves(c *fiber.Ctx) error {
+	userID, ok := jwt.UserIDFromCtx(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	shelves, err := h.Service.Shelves(c.Context(), userID)
+	if err != nil {
+		return collectionError(c, err)
+	}
+	return c.JSON(fiber.Map{"shelves": shelves})
+}
+
+// HandleCreateShelf creates a shelf: {"name": "Party games", "description": "..."}
+func (h *CollectionHandlers) HandleCreateShelf(c *fiber.Ctx) error {
+	userID, ok := jwt.UserIDFromCtx(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var shelf models.Shelf
+	if err := c.BodyParser(&shelf); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	if err := h.Service.CreateShelf(c.Context(), userID, &shelf); err != nil {
+		return collectionError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(shelf)
+}
+
+// HandleUpdateShelf renames a shelf or changes its description
+func (h *CollectionHandlers) HandleUpdateShelf(c *fiber.Ctx) error {
+	userID, shelfID, ok := jwt.UserAndIDParam[int64](c, "id", "shelf")
+	if !ok {
+		return nil
+	}
+
+	var body struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	shelf, err := h.Service.UpdateShelf(c.Context(), userID, shelfID, body.Name, body.Description)
+	if err != nil {
+		return collectionError(c, err)
+	}
+	return c.JSON(shelf)
+}
+
+// HandleDeleteShelf deletes a shelf
+func (h *CollectionHandlers) HandleDeleteShelf(c *fiber.Ctx) error {
+	userID, shelfID, ok := jwt.UserAndIDParam[int64](c, "id", "shelf")
+	if !ok {
+		return nil
+	}
+
+	if err := h.Service.DeleteShelf(c.Context(), userID, shelfID); err != nil {
+		return collectionError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleListShelfGames lists the games on a shelf with the same query params as the collection
+func (h *CollectionHandlers) HandleListShelfGames(c *fiber.Ctx) error {
+	userID, shelfID, ok := jwt.UserAndIDParam[int64](c, "id", "shelf")
+	if !ok {
+		return nil
+	}
+
+	q, err := gameQuery(c, userID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	shelf, games, err := h.Service.ShelfGames(c.Context(), userID, shelfID, q)
+	if err != nil {
+		return collectionError(c, err)
+	}
+	return c.JSON(fiber.Map{"shelf": shelf, "games": games, "limit": q.Limit, "offset": q.Offset})
+}
+
+// HandleAddToShelf puts a game on a shelf
+func (h *CollectionHandlers) HandleAddToShelf(c *fiber.Ctx) error {
+	userID, shelfID, ok := jwt.UserAndIDParam[int64](c, "id", "shelf")
+	if !ok {
+		return nil
+	}
+	gameID, err := strconv.Atoi(c.Params("game_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid board game ID"})
+	}
+
+	if err := h.Service.AddToShelf(c.Context(), userID, shelfID, gameID); err != nil {
+		return collectionError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleRemoveFromShelf takes a game off a shelf
+func (h *CollectionHandlers) HandleRemoveFromShelf(c *fiber.Ctx) error {
+	userID, shelfID, ok := jwt.UserAndIDParam[int64](c, "id", "shelf")
+	if !ok {
+		return nil
+	}
+	gameID, err := strconv.Atoi(c.Params("game_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid board game ID"})
+	}
+
+	if err := h.Service.RemoveFromShelf(c.Context(), userID, shelfID, gameID); err != nil {
+		return collectionError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func gameQuery(c *fiber.Ctx, userID int64) (collections.GameQuery, error) {
+	filter, err := handlers_board.CatalogueFilterFromQuery(c)
+	if err != nil {
+		return collections.GameQuery{}, err
+	}
+	return collections.GameQuery{
+		UserID: userID,
+		Filter: filter,
+		Sort:   c.Query("sort", collections.SortAdded),
+		Limit:  c.QueryInt("limit", collection.DefaultPageSize),
+		Offset: c.QueryInt("offset"),
+	}, nil
+}
+
+func collectionError(c *fiber.Ctx, err error) error {
+	var validationErr *collection.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": validationErr.Error()})
+	case errors.Is(err, collections.ErrBoardgameNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Board game not found"})
+	case errors.Is(err, collections.ErrEntryNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Game is not in this list"})
+	case errors.Is(err, collections.ErrShelfNotFound), errors.Is(err, collection.ErrNotShelfOwner):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shelf not found"})
+	case errors.Is(err, collections.ErrShelfNameTaken):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	default:
+		log.Println("Collection operation failed ->", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Collection operation failed"})
+	}
+}
@@ -0,0 +1,208 @@
+package collection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"guru-game/internal/db/repository/collections"
+	"guru-game/models"
+)
+
+// ErrNotShelfOwner is returned when a user reads or changes someone else's shelf
+var ErrNotShelfOwner = errors.New("shelf belongs to another user")
+
+// Limits on collection input
+const (
+	MaxNotesLength          = 2000
+	MaxShelfNameLength      = 100
+	MaxShelfDescLength      = 1000
+	MinWishlistPriority     = 1
+	MaxWishlistPriority     = 5
+	DefaultPageSize         = 50
+	MaxPageSize             = 200
+	DefaultWishlistPriority = 3
+)
+
+// ValidationError reports invalid collection input
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string { return e.Err.Error() }
+
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// Service manages user collections and shelves
+type Service struct {
+	repo collections.CollectionRepository
+}
+
+// NewService creates a new collection Service
+func NewService(repo collections.CollectionRepository) *Service {
+	return &Service{repo: repo}
+}
+
+// List returns the games in a user's collection matching q
+func (s *Service) List(ctx context.Context, q collections.GameQuery) ([]models.CollectionGame, error) {
+	if q.Status != "" && !IsStatus(q.Status) {
+		return nil, &ValidationError{Err: fmt.Errorf("status must be one of %s", strings.Join(models.CollectionStatuses, ", "))}
+	}
+	normalizePage(&q)
+	return s.repo.ListGames(ctx, q)
+}
+
+// Entries returns every status the user gave a game
+func (s *Service) Entries(ctx context.Context, userID int64, boardgameID int) ([]models.CollectionEntry, error) {
+	return s.repo.GetEntries(ctx, userID, boardgameID)
+}
+
+// SetStatus adds a status to a game in the user's collection, or updates its priority and notes.
+// Wishlist entries default to DefaultWishlistPriority; other statuses cannot carry a priority.
+func (s *Service) SetStatus(ctx context.Context, userID int64, boardgameID int, e *models.CollectionEntry) error {
+	if !IsStatus(e.Status) {
+		return &ValidationError{Err: fmt.Errorf("status must be one of %s", strings.Join(models.CollectionStatuses, ", "))}
+	}
+	if e.Status == models.CollectionWishlist {
+		if e.WishlistPriority == nil {
+			p := DefaultWishlistPriority
+			e.WishlistPriority = &p
+		}
+		if p := *e.WishlistPriority; p < MinWishlistPriority || p > MaxWishlistPriority {
+			return &ValidationError{Err: fmt.Errorf("wishlist_priority must be between %d and %d", MinWishlistPriority, MaxWishlistPriority)}
+		}
+	} else if e.WishlistPriority != nil {
+		return &ValidationError{Err: errors.New("wishlist_priority only applies to the wishlist status")}
+	}
+	if utf8.RuneCountInString(e.Notes) > MaxNotesLength {
+		return &ValidationError{Err: fmt.Errorf("notes must be at most %d characters", MaxNotesLength)}
+	}
+	return s.repo.SetEntry(ctx, userID, boardgameID, e)
+}
+
+// RemoveStatus removes a status from a game in the user's collection
+func (s *Service) RemoveStatus(ctx context.Context, userID int64, boardgameID int, status string) error {
+	if !IsStatus(status) {
+		return collections.ErrEntryNotFound
+	}
+	return s.repo.RemoveEntry(ctx, userID, boardgameID, status)
+}
+
+// OwnedGameIDs returns the games the user currently owns
+func (s *Service) OwnedGameIDs(ctx context.Context, userID int64) (map[int]bool, error) {
+	return s.repo.OwnedGameIDs(ctx, userID)
+}
+
+// Shelves returns the user's shelves
+func (s *Service) Shelves(ctx context.Context, userID int64) ([]models.Shelf, error) {
+	return s.repo.ListShelves(ctx, userID)
+}
+
+// CreateShelf creates a shelf for userID
+func (s *Service) CreateShelf(ctx context.Context, userID int64, shelf *models.Shelf) error {
+	shelf.UserID = userID
+	if err := validateShelf(shelf); err != nil {
+		return err
+	}
+	return s.repo.CreateShelf(ctx, shelf)
+}
+
+// UpdateShelf renames a shelf or changes its description
+func (s *Service) UpdateShelf(ctx context.Context, userID, shelfID int64, name, description string) (*models.Shelf, error) {
+	shelf, err := s.ownShelf(ctx, userID, shelfID)
+	if err != nil {
+		return nil, err
+	}
+	shelf.Name, shelf.Description = name, description
+	if err := validateShelf(shelf); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateShelf(ctx, shelf); err != nil {
+		return nil, err
+	}
+	return shelf, nil
+}
+
+// DeleteShelf deletes one of the user's shelves
+func (s *Service) DeleteShelf(ctx context.Context, userID, shelfID int64) error {
+	if _, err := s.ownShelf(ctx, userID, shelfID); err != nil {
+		return err
+	}
+	return s.repo.DeleteShelf(ctx, shelfID)
+}
+
+// ShelfGames returns the games on one of the user's shelves
+func (s *Service) ShelfGames(ctx context.Context, userID, shelfID int64, q collections.GameQuery) (*models.Shelf, []models.BoardGame, error) {
+	shelf, err := s.ownShelf(ctx, userID, shelfID)
+	if err != nil {
+		return nil, nil, err
+	}
+	normalizePage(&q)
+	games, err := s.repo.ListShelfGames(ctx, shelfID, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return shelf, games, nil
+}
+
+// AddToShelf puts a game on one of the user's shelves
+func (s *Service) AddToShelf(ctx context.Context, userID, shelfID int64, boardgameID int) error {
+	if _, err := s.ownShelf(ctx, userID, shelfID); err != nil {
+		return err
+	}
+	return s.repo.AddToShelf(ctx, shelfID, boardgameID)
+}
+
+// RemoveFromShelf takes a game off one of the user's shelves
+func (s *Service) RemoveFromShelf(ctx context.Context, userID, shelfID int64, boardgameID int) error {
+	if _, err := s.ownShelf(ctx, userID, shelfID); err != nil {
+		return err
+	}
+	return s.repo.RemoveFromShelf(ctx, shelfID, boardgameID)
+}
+
+// IsStatus reports whether status is a valid collection status
+func IsStatus(status string) bool {
+	for _, s := range models.CollectionStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) ownShelf(ctx context.Context, userID, shelfID int64) (*models.Shelf, error) {
+	shelf, err := s.repo.GetShelf(ctx, shelfID)
+	if err != nil {
+		return nil, err
+	}
+	if shelf.UserID != userID {
+		return nil, ErrNotShelfOwner
+	}
+	return shelf, nil
+}
+
+func validateShelf(shelf *models.Shelf) error {
+	shelf.Name = strings.TrimSpace(shelf.Name)
+	shelf.Description = strings.TrimSpace(shelf.Description)
+	switch {
+	case shelf.Name == "":
+		return &ValidationError{Err: errors.New("name is required")}
+	case utf8.RuneCountInString(shelf.Name) > MaxShelfNameLength:
+		return &ValidationError{Err: fmt.Errorf("name must be at most %d characters", MaxShelfNameLength)}
+	case utf8.RuneCountInString(shelf.Description) > MaxShelfDescLength:
+		return &ValidationError{Err: fmt.Errorf("description must be at most %d characters", MaxShelfDescLength)}
+	}
+	return nil
+}
+
+func normalizePage(q *collections.GameQuery) {
+	if q.Limit <= 0 || q.Limit > MaxPageSize {
+		q.Limit = DefaultPageSize
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+}
@@ -0,0 +1,245 @@
+package collection
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"guru-game/internal/db/repository/collections"
+	"guru-game/models"
+)
+
+// fakeRepo holds shelves by ID and records which writes reached it; other
+// CollectionRepository methods are not used
+type fakeRepo struct {
+	collections.CollectionRepository
+	shelves map[int64]*models.Shelf
+	entry   *models.CollectionEntry
+	writes  []string
+}
+
+func (r *fakeRepo) SetEntry(ctx context.Context, userID int64, boardgameID int, e *models.CollectionEntry) error {
+	r.entry = e
+	r.writes = append(r.writes, "set entry")
+	return nil
+}
+
+func (r *fakeRepo) RemoveEntry(ctx context.Context, userID int64, boardgameID int, status string) error {
+	r.writes = append(r.writes, "remove entry")
+	return nil
+}
+
+func (r *fakeRepo) GetShelf(ctx context.Context, id int64) (*models.Shelf, error) {
+	shelf, ok := r.shelves[id]
+	if !ok {
+		return nil, collections.ErrShelfNotFound
+	}
+	copied := *shelf
+	return &copied, nil
+}
+
+func (r *fakeRepo) CreateShelf(ctx context.Context, s *models.Shelf) error {
+	r.writes = append(r.writes, "create shelf")
+	return nil
+}
+
+func (r *fakeRepo) UpdateShelf(ctx context.Context, s *models.Shelf) error {
+	r.writes = append(r.writes, "update shelf")
+	return nil
+}
+
+func (r *fakeRepo) DeleteShelf(ctx context.Context, id int64) error {
+	r.writes = append(r.writes, "delete shelf")
+	return nil
+}
+
+func (r *fakeRepo) AddToShelf(ctx context.Context, shelfID int64, boardgameID int) error {
+	r.writes = append(r.writes, "add to shelf")
+	return nil
+}
+
+func (r *fakeRepo) RemoveFromShelf(ctx context.Context, shelfID int64, boardgameID int) error {
+	r.writes = append(r.writes, "remove from shelf")
+	return nil
+}
+
+func TestSetStatus(t *testing.T) {
+	priority := func(v int) *int { return &v }
+
+	tests := []struct {
+		name         string
+		entry        models.CollectionEntry
+		wantErr      string
+		wantPriority *int
+	}{
+		{"owned", models.CollectionEntry{Status: models.CollectionOwned}, "", nil},
+		{"wishlist gets the default priority", models.CollectionEntry{Status: models.CollectionWishlist}, "", priority(DefaultWishlistPriority)},
+		{"wishlist keeps its priority", models.CollectionEntry{Status: models.CollectionWishlist, WishlistPriority: priority(MaxWishlistPriority)}, "", priority(MaxWishlistPriority)},
+		{"wishlist priority too low", models.CollectionEntry{Status: models.CollectionWishlist, WishlistPriority: priority(MinWishlistPriority - 1)}, "wishlist_priority must be between", nil},
+		{"wishlist priority too high", models.CollectionEntry{Status: models.CollectionWishlist, WishlistPriority: priority(MaxWishlistPriority + 1)}, "wishlist_priority must be between", nil},
+		{"priority on another status", models.CollectionEntry{Status: models.CollectionOwned, WishlistPriority: priority(2)}, "only applies to the wishlist", nil},
+		{"unknown status", models.CollectionEntry{Status: "borrowed"}, "status must be one of", nil},
+		{"empty status", models.CollectionEntry{}, "status must be one of", nil},
+		{"notes at the limit", models.CollectionEntry{Status: models.CollectionOwned, Notes: strings.Repeat("x", MaxNotesLength)}, "", nil},
+		{"notes too long", models.CollectionEntry{Status: models.CollectionOwned, Notes: strings.Repeat("x", MaxNotesLength+1)}, "notes must be at most", nil},
+		{"thai notes at the limit", models.CollectionEntry{Status: models.CollectionOwned, Notes: strings.Repeat("ก", MaxNotesLength)}, "", nil},
+		{"thai notes too long", models.CollectionEntry{Status: models.CollectionOwned, Notes: strings.Repeat("ก", MaxNotesLength+1)}, "notes must be at most", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepo{}
+			entry := tt.entry
+			err := NewService(repo).SetStatus(context.Background(), 1, 7, &entry)
+			if tt.wantErr != "" {
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("SetStatus() = %v, want validation error containing %q", err, tt.wantErr)
+				}
+				if len(repo.writes) != 0 {
+					t.Errorf("repository writes = %v, want none", repo.writes)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetStatus() = %v", err)
+			}
+			if repo.entry == nil {
+				t.Fatal("entry was not stored")
+			}
+			got := repo.entry.WishlistPriority
+			if (got == nil) != (tt.wantPriority == nil) || (got != nil && *got != *tt.wantPriority) {
+				t.Errorf("stored priority = %v, want %v", got, tt.wantPriority)
+			}
+		})
+	}
+}
+
+func TestRemoveStatusUnknown(t *testing.T) {
+	repo := &fakeRepo{}
+	err := NewService(repo).RemoveStatus(context.Background(), 1, 7, "borrowed")
+	if !errors.Is(err, collections.ErrEntryNotFound) {
+		t.Fatalf("RemoveStatus() = %v, want ErrEntryNotFound", err)
+	}
+	if len(repo.writes) != 0 {
+		t.Errorf("repository writes = %v, want none", repo.writes)
+	}
+}
+
+func TestShelfOwnership(t *testing.T) {
+	const owner, stranger = 1, 2
+
+	tests := []struct {
+		name      string
+		userID    int64
+		shelfID   int64
+		call      func(s *Service, userID, shelfID int64) error
+		wantErr   error
+		wantWrite string
+	}{
+		{"owner renames", owner, 10, func(s *Service, u, id int64) error {
+			_, err := s.UpdateShelf(context.Background(), u, id, "Favourites", "")
+			return err
+		}, nil, "update shelf"},
+		{"owner deletes", owner, 10, func(s *Service, u, id int64) error {
+			return s.DeleteShelf(context.Background(), u, id)
+		}, nil, "delete shelf"},
+		{"owner adds a game", owner, 10, func(s *Service, u, id int64) error {
+			return s.AddToShelf(context.Background(), u, id, 7)
+		}, nil, "add to shelf"},
+		{"owner removes a game", owner, 10, func(s *Service, u, id int64) error {
+			return s.RemoveFromShelf(context.Background(), u, id, 7)
+		}, nil, "remove from shelf"},
+		{"stranger renames", stranger, 10, func(s *Service, u, id int64) error {
+			_, err := s.UpdateShelf(context.Background(), u, id, "Mine now", "")
+			return err
+		}, ErrNotShelfOwner, ""},
+		{"stranger deletes", stranger, 10, func(s *Service, u, id int64) error {
+			return s.DeleteShelf(context.Background(), u, id)
+		}, ErrNotShelfOwner, ""},
+		{"stranger adds a game", stranger, 10, func(s *Service, u, id int64) error {
+			return s.AddToShelf(context.Background(), u, id, 7)
+		}, ErrNotShelfOwner, ""},
+		{"stranger removes a game", stranger, 10, func(s *Service, u, id int64) error {
+			return s.RemoveFromShelf(context.Background(), u, id, 7)
+		}, ErrNotShelfOwner, ""},
+		{"missing shelf", owner, 99, func(s *Service, u, id int64) error {
+			return s.DeleteShelf(context.Background(), u, id)
+		}, collections.ErrShelfNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepo{shelves: map[int64]*models.Shelf{10: {ID: 10, UserID: owner, Name: "Party games"}}}
+			err := tt.call(NewService(repo), tt.userID, tt.shelfID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			var wantWrites []string
+			if tt.wantWrite != "" {
+				wantWrites = []string{tt.wantWrite}
+			}
+			if strings.Join(repo.writes, ",") != strings.Join(wantWrites, ",") {
+				t.Errorf("repository writes = %v, want %v", repo.writes, wantWrites)
+			}
+		})
+	}
+}
+
+func TestValidateShelf(t *testing.T) {
+	tests := []struct {
+		name     string
+		shelf    models.Shelf
+		wantErr  string
+		wantName string
+	}{
+		{"trimmed", models.Shelf{Name: "  Party games ", Description: " For Fridays "}, "", "Party games"},
+		{"name required", models.Shelf{Name: "   "}, "name is required", ""},
+		{"name at the limit", models.Shelf{Name: strings.Repeat("x", MaxShelfNameLength)}, "", strings.Repeat("x", MaxShelfNameLength)},
+		{"name too long", models.Shelf{Name: strings.Repeat("x", MaxShelfNameLength+1)}, "name must be at most", ""},
+		{"thai name at the limit", models.Shelf{Name: strings.Repeat("ก", MaxShelfNameLength)}, "", strings.Repeat("ก", MaxShelfNameLength)},
+		{"thai name too long", models.Shelf{Name: strings.Repeat("ก", MaxShelfNameLength+1)}, "name must be at most", ""},
+		{"description too long", models.Shelf{Name: "Party", Description: strings.Repeat("x", MaxShelfDescLength+1)}, "description must be at most", ""},
+		{"thai description at the limit", models.Shelf{Name: "Party", Description: strings.Repeat("ก", MaxShelfDescLength)}, "", "Party"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shelf := tt.shelf
+			err := validateShelf(&shelf)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("validateShelf() = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateShelf() = %v", err)
+			}
+			if shelf.Name != tt.wantName || shelf.Description != strings.TrimSpace(tt.shelf.Description) {
+				t.Errorf("shelf = %+v, want trimmed name %q and description", shelf, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestNormalizePage(t *testing.T) {
+	tests := []struct {
+		name                  string
+		limit, offset         int
+		wantLimit, wantOffset int
+	}{
+		{"defaults", 0, 0, DefaultPageSize, 0},
+		{"kept", 20, 40, 20, 40},
+		{"maximum kept", MaxPageSize, 0, MaxPageSize, 0},
+		{"over the maximum", MaxPageSize + 1, 0, DefaultPageSize, 0},
+		{"negative", -5, -10, DefaultPageSize, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := collections.GameQuery{Limit: tt.limit, Offset: tt.offset}
+			normalizePage(&q)
+			if q.Limit != tt.wantLimit || q.Offset != tt.wantOffset {
+				t.Errorf("page = %d/%d, want %d/%d", q.Limit, q.Offset, tt.wantLimit, tt.wantOffset)
+			}
+		})
+	}
+}
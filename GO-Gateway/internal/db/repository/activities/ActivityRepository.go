@@ -0,0 +1,260 @@
+package activities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"guru-game/internal/db/repository/outbox"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Activity is a row of user_activity_log
+type Activity struct {
+	ID          int64       `json:"id"`
+	UserID      int64       `json:"user_id"`
+	Type        string      `json:"type"`
+	BoardgameID *int        `json:"boardgame_id,omitempty"`
+	SessionID   string      `json:"session_id"`
+	EventID     *string     `json:"event_id,omitempty"`
+	Data        interface{} `json:"data"` // Stored as jsonb
+	OccurredAt  time.Time   `json:"occurred_at"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// View is one game page view with the time spent on it
+type View struct {
+	UserID      int64
+	BoardgameID int
+	SessionID   string
+	DwellMs     int64
+	ViewedAt    time.Time
+}
+
+// BatchItem is one activity for InsertBatch, with the view it adds to the totals and the
+// message it queues for the recommendation service, if any
+type BatchItem struct {
+	Activity Activity
+	View     *View
+	Forward  *outbox.Message
+}
+
+// RecentlyViewedGame is a game the user viewed, with totals across sessions
+type RecentlyViewedGame struct {
+	BoardgameID  int       `json:"boardgame_id"`
+	Title        string    `json:"title"`
+	ImageURL     string    `json:"image_url"`
+	Views        int64     `json:"views"`
+	DwellMs      int64     `json:"dwell_ms"`
+	LastViewedAt time.Time `json:"last_viewed_at"`
+}
+
+// ViewStats are the view totals of one game
+type ViewStats struct {
+	BoardgameID  int   `json:"boardgame_id"`
+	Views        int64 `json:"views"`
+	Sessions     int64 `json:"sessions"`
+	TotalDwellMs int64 `json:"total_dwell_ms"`
+	AvgDwellMs   int64 `json:"avg_dwell_ms"`
+}
+
+// ActivityRepository defines the interface for activity log database operations
+type ActivityRepository interface {
+	InsertBatch(ctx context.Context, items []BatchItem) (map[string]bool, error)
+	RecentlyViewed(ctx context.Context, userID int64, limit int) ([]RecentlyViewedGame, error)
+	GetViewStats(ctx context.Context, boardgameID int) (*ViewStats, error)
+}
+
+// PostgresActivityRepository handles database operations for the activity log using pgxpool
+type PostgresActivityRepository struct {
+	DB *pgxpool.Pool
+}
+
+// NewPostgresActivityRepository creates a new PostgresActivityRepository
+func NewPostgresActivityRepository(db *pgxpool.Pool) *PostgresActivityRepository {
+	return &PostgresActivityRepository{DB: db}
+}
+
+// InsertBatch appends items to the log in one transaction and, for each item that was
+// logged, adds its view to game_views and game_view_counts and queues its message in
+// recommendation_outbox. Items whose event ID is already logged for the user are skipped;
+// their event IDs are returned. Rows are COPYed into a temporary table first so the insert
+// into the log can skip duplicates instead of failing the batch, which also makes
+// concurrent resends of the same batch safe.
+func (r *PostgresActivityRepository) InsertBatch(ctx context.Context, items []BatchItem) (map[string]bool, error) {
+	duplicates := make(map[string]bool)
+	if len(items) == 0 {
+		return duplicates, nil
+	}
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		CREATE TEMP TABLE activity_batch (
+			ord          INT,
+			user_id      BIGINT,
+			type         TEXT,
+			boardgame_id INT,
+			session_id   TEXT,
+			event_id     TEXT,
+			data         JSONB,
+			occurred_at  TIMESTAMPTZ
+		) ON COMMIT DROP
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create activity batch table: %w", err)
+	}
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"activity_batch"},
+		[]string{"ord", "user_id", "type", "boardgame_id", "session_id", "event_id", "data", "occurred_at"},
+		pgx.CopyFromSlice(len(items), func(i int) ([]any, error) {
+			a := items[i].Activity
+			return []any{i, a.UserID, a.Type, a.BoardgameID, a.SessionID, a.EventID, a.Data, a.OccurredAt}, nil
+		}),
+	)
+	if err != nil {
+		log.Printf("Error copying %d activities: %v", len(items), err)
+		return nil, fmt.Errorf("failed to insert activities: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `
+		INSERT INTO user_activity_log (user_id, type, boardgame_id, session_id, event_id, data, occurred_at)
+		SELECT user_id, type, boardgame_id, session_id, event_id, data, occurred_at
+		FROM activity_batch
+		ORDER BY ord
+		ON CONFLICT (user_id, event_id) WHERE event_id IS NOT NULL DO NOTHING
+		RETURNING event_id
+	`)
+	if err != nil {
+		log.Printf("Error logging %d activities: %v", len(items), err)
+		return nil, fmt.Errorf("failed to insert activities: %w", err)
+	}
+	logged, err := pgx.CollectRows(rows, pgx.RowTo[*string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert activities: %w", err)
+	}
+	loggedIDs := make(map[string]bool, len(logged))
+	for _, id := range logged {
+		if id != nil {
+			loggedIDs[*id] = true
+		}
+	}
+
+	var views []View
+	var forward []outbox.Message
+	for _, item := range items {
+		if id := item.Activity.EventID; id != nil && !loggedIDs[*id] {
+			duplicates[*id] = true
+			continue
+		}
+		if item.View != nil {
+			views = append(views, *item.View)
+		}
+		if item.Forward != nil {
+			forward = append(forward, *item.Forward)
+		}
+	}
+	if err := recordViews(ctx, tx, views); err != nil {
+		return nil, err
+	}
+	if err := outbox.Enqueue(ctx, tx, forward...); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit activities: %w", err)
+	}
+	return duplicates, nil
+}
+
+// recordViews adds each view to its session row and the game's running totals
+func recordViews(ctx context.Context, tx pgx.Tx, views []View) error {
+	for _, v := range views {
+		var newSession bool
+		err := tx.QueryRow(ctx, `
+			INSERT INTO game_views (user_id, boardgame_id, session_id, view_count, dwell_ms, first_viewed_at, last_viewed_at)
+			VALUES ($1, $2, $3, 1, $4, $5, $5)
+			ON CONFLICT (user_id, boardgame_id, session_id) DO UPDATE
+			SET view_count = game_views.view_count + 1,
+			    dwell_ms = game_views.dwell_ms + EXCLUDED.dwell_ms,
+			    first_viewed_at = LEAST(game_views.first_viewed_at, EXCLUDED.first_viewed_at),
+			    last_viewed_at = GREATEST(game_views.last_viewed_at, EXCLUDED.last_viewed_at)
+			RETURNING (xmax = 0)
+		`, v.UserID, v.BoardgameID, v.SessionID, v.DwellMs, v.ViewedAt).Scan(&newSession)
+		if err != nil {
+			log.Printf("Error recording view of game %d by user %d: %v", v.BoardgameID, v.UserID, err)
+			return fmt.Errorf("failed to record view: %w", err)
+		}
+
+		sessions := 0
+		if newSession {
+			sessions = 1
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO game_view_counts (boardgame_id, views, sessions, total_dwell_ms, updated_at)
+			VALUES ($1, 1, $2, $3, NOW())
+			ON CONFLICT (boardgame_id) DO UPDATE
+			SET views = game_view_counts.views + 1,
+			    sessions = game_view_counts.sessions + EXCLUDED.sessions,
+			    total_dwell_ms = game_view_counts.total_dwell_ms + EXCLUDED.total_dwell_ms,
+			    updated_at = NOW()
+		`, v.BoardgameID, sessions, v.DwellMs)
+		if err != nil {
+			log.Printf("Error updating view counts for game %d: %v", v.BoardgameID, err)
+			return fmt.Errorf("failed to update view counts: %w", err)
+		}
+	}
+	return nil
+}
+
+// RecentlyViewed returns the games the user viewed most recently, newest first
+func (r *PostgresActivityRepository) RecentlyViewed(ctx context.Context, userID int64, limit int) ([]RecentlyViewedGame, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT v.boardgame_id, b.title, COALESCE(b.image_url, ''),
+		       SUM(v.view_count), SUM(v.dwell_ms), MAX(v.last_viewed_at) AS last_viewed_at
+		FROM game_views v
+		JOIN boardgames b ON b.id = v.boardgame_id
+		WHERE v.user_id = $1
+		GROUP BY v.boardgame_id, b.title, b.image_url
+		ORDER BY last_viewed_at DESC
+		LIMIT $2
+	`, userID, limit)
+	if err != nil {
+		log.Printf("Error fetching recently viewed games for user %d: %v", userID, err)
+		return nil, fmt.Errorf("failed to fetch recently viewed games: %w", err)
+	}
+
+	games, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (RecentlyViewedGame, error) {
+		var g RecentlyViewedGame
+		err := row.Scan(&g.BoardgameID, &g.Title, &g.ImageURL, &g.Views, &g.DwellMs, &g.LastViewedAt)
+		return g, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan recently viewed games: %w", err)
+	}
+	return games, nil
+}
+
+// GetViewStats returns the view totals of a game; a game nobody viewed has zero totals
+func (r *PostgresActivityRepository) GetViewStats(ctx context.Context, boardgameID int) (*ViewStats, error) {
+	stats := ViewStats{BoardgameID: boardgameID}
+	err := r.DB.QueryRow(ctx, `
+		SELECT views, sessions, total_dwell_ms FROM game_view_counts WHERE boardgame_id = $1
+	`, boardgameID).Scan(&stats.Views, &stats.Sessions, &stats.TotalDwellMs)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Error fetching view stats for game %d: %v", boardgameID, err)
+		return nil, fmt.Errorf("failed to fetch view stats: %w", err)
+	}
+	if stats.Views > 0 {
+		stats.AvgDwellMs = stats.TotalDwellMs / stats.Views
+	}
+	return &stats, nil
+}
@@ -0,0 +1,164 @@
+package aggregates
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Params controls how user_states are folded into boardgame aggregates
+type Params struct {
+	PriorWeight           float64 // C in the Bayesian average (C*m + sum) / (C + n)
+	HalfLifeSeconds       float64 // popularity contribution halves after this long
+	LikeWeight            float64
+	FavoriteWeight        float64
+	RatingWeight          float64
+	RatingPopularityScale float64 // extra popularity per rating point, so a 9 counts more than a 3
+
+	PositiveRating    float64 // lowest rating that counts as liking a game for game similarities
+	SimilarNeighbours int     // most similar games kept per game
+}
+
+// AggregateRepository recomputes rating_avg, rating_count and popularity_score on boardgames
+type AggregateRepository interface {
+	RecomputeGame(ctx context.Context, boardgameID int, params Params) error
+	RecomputeAll(ctx context.Context, params Params) (int64, error)
+	RecomputeSimilarities(ctx context.Context, params Params) (int64, error)
+}
+
+// PostgresAggregateRepository handles aggregate updates using pgxpool
+type PostgresAggregateRepository struct {
+	DB *pgxpool.Pool
+}
+
+// NewPostgresAggregateRepository creates a new PostgresAggregateRepository
+func NewPostgresAggregateRepository(db *pgxpool.Pool) *PostgresAggregateRepository {
+	return &PostgresAggregateRepository{DB: db}
+}
+
+// statsSelect aggregates user_states per game. $1..$6 are the Params values in field order.
+// A rating of 0 means "not rated" and is excluded from the rating figures.
+const statsSelect = `
+	SELECT boardgame_id,
+	       COUNT(*) FILTER (WHERE rating > 0) AS n,
+	       COALESCE(SUM(rating) FILTER (WHERE rating > 0), 0)::float8 AS total,
+	       COALESCE(SUM(
+	           (CASE WHEN liked THEN $3::float8 ELSE 0 END
+	            + CASE WHEN favorited THEN $4::float8 ELSE 0 END
+	            + CASE WHEN rating > 0 THEN $5::float8 + rating * $6::float8 ELSE 0 END)
+	           * EXP(-LN(2) * GREATEST(EXTRACT(EPOCH FROM (NOW() - updated_at)), 0)::float8 / $2::float8)
+	       ), 0)::float8 AS popularity
+	FROM user_states
+`
+
+// RecomputeGame refreshes the aggregates of a single board game from its user_states. The
+// prior mean is the one stored by the last RecomputeAll, so only the game's rows are read.
+func (r *PostgresAggregateRepository) RecomputeGame(ctx context.Context, boardgameID int, params Params) error {
+	query := `
+		WITH prior AS (
+			SELECT COALESCE((SELECT mean FROM rating_prior), 0)::float8 AS mean
+		), stats AS (` + statsSelect + ` WHERE boardgame_id = $7 GROUP BY boardgame_id
+		)
+		UPDATE boardgames b
+		SET rating_count = COALESCE(stats.n, 0),
+		    rating_avg = CASE WHEN COALESCE(stats.n, 0) = 0 THEN 0
+		                      ELSE (prior.mean * $1::float8 + stats.total) / ($1::float8 + stats.n) END,
+		    popularity_score = COALESCE(stats.popularity, 0)
+		FROM prior LEFT JOIN stats ON TRUE
+		WHERE b.id = $7
+	`
+
+	_, err := r.DB.Exec(ctx, query,
+		params.PriorWeight, params.HalfLifeSeconds, params.LikeWeight, params.FavoriteWeight,
+		params.RatingWeight, params.RatingPopularityScale, boardgameID)
+	if err != nil {
+		log.Printf("Error recomputing aggregates for boardgame %d: %v", boardgameID, err)
+		return fmt.Errorf("failed to recompute boardgame aggregates: %w", err)
+	}
+
+	return nil
+}
+
+// RecomputeAll refreshes the stored prior mean and the aggregates of every board game that
+// has user_states. Games nobody has interacted with keep their seeded values (e.g. from a
+// BGG import).
+func (r *PostgresAggregateRepository) RecomputeAll(ctx context.Context, params Params) (int64, error) {
+	query := `
+		WITH prior AS (
+			INSERT INTO rating_prior (mean)
+			SELECT COALESCE(AVG(rating), 0)::float8 FROM user_states WHERE rating > 0
+			ON CONFLICT (id) DO UPDATE SET mean = EXCLUDED.mean, updated_at = NOW()
+			RETURNING mean
+		), stats AS (` + statsSelect + ` GROUP BY boardgame_id
+		)
+		UPDATE boardgames b
+		SET rating_count = stats.n,
+		    rating_avg = CASE WHEN stats.n = 0 THEN 0
+		                      ELSE (prior.mean * $1::float8 + stats.total) / ($1::float8 + stats.n) END,
+		    popularity_score = stats.popularity
+		FROM prior, stats
+		WHERE b.id = stats.boardgame_id
+	`
+
+	tag, err := r.DB.Exec(ctx, query,
+		params.PriorWeight, params.HalfLifeSeconds, params.LikeWeight, params.FavoriteWeight,
+		params.RatingWeight, params.RatingPopularityScale)
+	if err != nil {
+		log.Printf("Error recomputing aggregates for all boardgames: %v", err)
+		return 0, fmt.Errorf("failed to recompute boardgame aggregates: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// RecomputeSimilarities replaces game_similarities with the cosine similarity of every pair
+// of games liked by the same users. A user likes a game they liked, favorited or rated at
+// least params.PositiveRating. Only the params.SimilarNeighbours closest games of each game
+// are kept. Readers keep seeing the previous similarities until the new ones are committed.
+func (r *PostgresAggregateRepository) RecomputeSimilarities(ctx context.Context, params Params) (int64, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM game_similarities`); err != nil {
+		log.Printf("Error clearing game similarities: %v", err)
+		return 0, fmt.Errorf("failed to clear game similarities: %w", err)
+	}
+	tag, err := tx.Exec(ctx, `
+		WITH positive AS (
+			SELECT user_id, boardgame_id
+			FROM user_states
+			WHERE liked OR favorited OR rating >= $1
+		), fans AS (
+			SELECT boardgame_id, COUNT(*) AS n
+			FROM positive
+			GROUP BY boardgame_id
+		), pairs AS (
+			SELECT a.boardgame_id AS source_id, b.boardgame_id AS candidate_id, COUNT(*) AS together
+			FROM positive a
+			JOIN positive b ON b.user_id = a.user_id AND b.boardgame_id <> a.boardgame_id
+			GROUP BY a.boardgame_id, b.boardgame_id
+		), ranked AS (
+			SELECT p.source_id, p.candidate_id, p.together / sqrt(fs.n * fc.n) AS similarity,
+			       ROW_NUMBER() OVER (PARTITION BY p.source_id ORDER BY p.together / sqrt(fs.n * fc.n) DESC, p.candidate_id) AS rank
+			FROM pairs p
+			JOIN fans fs ON fs.boardgame_id = p.source_id
+			JOIN fans fc ON fc.boardgame_id = p.candidate_id
+		)
+		INSERT INTO game_similarities (source_id, candidate_id, similarity)
+		SELECT source_id, candidate_id, similarity FROM ranked WHERE rank <= $2
+	`, params.PositiveRating, params.SimilarNeighbours)
+	if err != nil {
+		log.Printf("Error recomputing game similarities: %v", err)
+		return 0, fmt.Errorf("failed to recompute game similarities: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit game similarities: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -2,17 +2,24 @@ package boardgame
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"guru-game/internal/db/connection"
 	"guru-game/models"
 )
 
+// ErrNotFound is returned by GetByID when no board game has the given ID
+var ErrNotFound = errors.New("board game not found")
+
 // BoardGameRepository interface for CRUD
 type BoardGameRepository interface {
 	GetByID(id int) (*models.BoardGame, error)
 	GetAll() ([]models.BoardGame, error)
 	Delete(id int) error
 	GetUserBoardgameState(userID int, boardgameID int) (*models.UserState, error)
+	GetByExternalID(externalID string) (*models.BoardGame, error)
+	UpsertByExternalID(bg *models.BoardGame) (int, error)
+	Stream(ctx context.Context, filter StreamFilter, fn func(*models.BoardGame) error) error
 }
 
 type PostgresBoardgameRepository struct{}
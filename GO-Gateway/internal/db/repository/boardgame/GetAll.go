@@ -11,7 +11,7 @@ import (
 func (r *PostgresBoardgameRepository) GetAll() ([]models.BoardGame, error) {
 	query := `
 		SELECT 
-			id, title, description, min_players, max_players, play_time_min, play_time_max, 
+			id, COALESCE(external_id, ''), title, description, min_players, max_players, play_time_min, play_time_max, 
 			categories, rating_avg, rating_count, popularity_score, image_url, created_at, updated_at
 		FROM boardgames
 	`
@@ -27,6 +27,7 @@ func (r *PostgresBoardgameRepository) GetAll() ([]models.BoardGame, error) {
 		var bg models.BoardGame
 		err := rows.Scan(
 			&bg.ID,
+			&bg.ExternalID,
 			&bg.Title,
 			&bg.Description,
 			&bg.MinPlayers,
@@ -11,7 +11,7 @@ import (
 func (r *PostgresBoardgameRepository) GetByID(id int) (*models.BoardGame, error) {
 	query := `
 		SELECT 
-			id, title, description, min_players, max_players, 
+			id, COALESCE(external_id, ''), title, description, min_players, max_players, 
 			play_time_min, play_time_max, categories, rating_avg, rating_count, 
 			popularity_score, image_url, created_at, updated_at 
 		FROM boardgames 
@@ -22,6 +22,7 @@ func (r *PostgresBoardgameRepository) GetByID(id int) (*models.BoardGame, error)
 	var boardgame models.BoardGame
 	err := row.Scan(
 		&boardgame.ID,
+		&boardgame.ExternalID,
 		&boardgame.Title,
 		&boardgame.Description,
 		&boardgame.MinPlayers,
@@ -38,7 +39,7 @@ func (r *PostgresBoardgameRepository) GetByID(id int) (*models.BoardGame, error)
 	)
 	if err != nil {
 		if err.Error() == "no rows in result set" {
-			return nil, fmt.Errorf("board game with ID %d not found", id)
+			return nil, fmt.Errorf("%w: ID %d", ErrNotFound, id)
 		}
 		return nil, fmt.Errorf("failed to fetch board game by ID: %v", err)
 	}
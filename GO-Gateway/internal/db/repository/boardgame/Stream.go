@@ -0,0 +1,119 @@
+package boardgame
+
+import (
+	"context"
+	"fmt"
+	"guru-game/internal/db/connection"
+	"guru-game/models"
+	"strings"
+	"time"
+)
+
+// StreamFilter narrows the board games returned by Stream. Zero values are ignored.
+type StreamFilter struct {
+	Category     string    // substring match against the comma-separated categories
+	Title        string    // case-insensitive substring match
+	PlayerCount  int       // game must support this many players
+	MaxPlayTime  int       // play_time_min must not exceed this many minutes
+	UpdatedSince time.Time // only games updated at or after this time
+
+	// Community poll filters; games nobody voted on never match these
+	MinWeight       float64 // average complexity vote of at least this (1-5)
+	MaxWeight       float64 // average complexity vote of at most this (1-5)
+	BestPlayerCount int     // voted best at this player count
+}
+
+// SQLConditions returns the WHERE conditions for the filter. Columns are prefixed with
+// prefix (e.g. "b.") and values are added through arg, which returns their placeholder.
+func (filter StreamFilter) SQLConditions(prefix string, arg func(interface{}) string) []string {
+	var conditions []string
+	if filter.Category != "" {
+		conditions = append(conditions, prefix+"categories ILIKE "+arg("%"+filter.Category+"%"))
+	}
+	if filter.Title != "" {
+		conditions = append(conditions, prefix+"title ILIKE "+arg("%"+filter.Title+"%"))
+	}
+	if filter.PlayerCount > 0 {
+		p := arg(filter.PlayerCount)
+		conditions = append(conditions, fmt.Sprintf("%smin_players <= %s AND %smax_players >= %s", prefix, p, prefix, p))
+	}
+	if filter.MaxPlayTime > 0 {
+		conditions = append(conditions, prefix+"play_time_min <= "+arg(filter.MaxPlayTime))
+	}
+	if !filter.UpdatedSince.IsZero() {
+		conditions = append(conditions, prefix+"updated_at >= "+arg(filter.UpdatedSince))
+	}
+	if filter.MinWeight > 0 {
+		conditions = append(conditions, prefix+"id IN (SELECT boardgame_id FROM game_poll_stats WHERE weight_avg >= "+arg(filter.MinWeight)+")")
+	}
+	if filter.MaxWeight > 0 {
+		conditions = append(conditions, prefix+"id IN (SELECT boardgame_id FROM game_poll_stats WHERE weight_avg <= "+arg(filter.MaxWeight)+")")
+	}
+	if filter.BestPlayerCount > 0 {
+		conditions = append(conditions, prefix+"id IN (SELECT boardgame_id FROM game_poll_stats WHERE "+arg(filter.BestPlayerCount)+"::int = ANY(best_player_counts))")
+	}
+	return conditions
+}
+
+// Stream scans board games matching filter in ID order and calls fn for each row as it is read,
+// so callers can write large exports without holding the whole catalogue in memory.
+// Iteration stops at the first error returned by fn.
+func (r *PostgresBoardgameRepository) Stream(ctx context.Context, filter StreamFilter, fn func(*models.BoardGame) error) error {
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := filter.SQLConditions("", arg)
+
+	query := `
+		SELECT 
+			id, COALESCE(external_id, ''), title, description, min_players, max_players, play_time_min, play_time_max, 
+			categories, rating_avg, rating_count, popularity_score, image_url, created_at, updated_at
+		FROM boardgames
+	`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY id"
+
+	rows, err := connection.DB.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to stream board games: %v", err)
+	}
+	defer rows.Close()
+
+	var bg models.BoardGame
+	for rows.Next() {
+		err := rows.Scan(
+			&bg.ID,
+			&bg.ExternalID,
+			&bg.Title,
+			&bg.Description,
+			&bg.MinPlayers,
+			&bg.MaxPlayers,
+			&bg.PlayTimeMin,
+			&bg.PlayTimeMax,
+			&bg.Categories,
+			&bg.RatingAvg,
+			&bg.RatingCount,
+			&bg.PopularityScore,
+			&bg.ImageURL,
+			&bg.CreatedAt,
+			&bg.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to scan board game: %v", err)
+		}
+		if err := fn(&bg); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed iterating rows: %v", err)
+	}
+
+	return nil
+}
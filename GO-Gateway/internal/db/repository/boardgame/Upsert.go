@@ -0,0 +1,99 @@
+package boardgame
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"guru-game/internal/db/connection"
+	"guru-game/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetByExternalID fetches a board game by its import identifier, returning nil if none exists
+func (r *PostgresBoardgameRepository) GetByExternalID(externalID string) (*models.BoardGame, error) {
+	query := `
+		SELECT 
+			id, COALESCE(external_id, ''), title, description, min_players, max_players, 
+			play_time_min, play_time_max, categories, rating_avg, rating_count, 
+			popularity_score, image_url, created_at, updated_at 
+		FROM boardgames 
+		WHERE external_id = $1
+	`
+	row := connection.DB.QueryRow(context.Background(), query, externalID)
+
+	var bg models.BoardGame
+	err := row.Scan(
+		&bg.ID,
+		&bg.ExternalID,
+		&bg.Title,
+		&bg.Description,
+		&bg.MinPlayers,
+		&bg.MaxPlayers,
+		&bg.PlayTimeMin,
+		&bg.PlayTimeMax,
+		&bg.Categories,
+		&bg.RatingAvg,
+		&bg.RatingCount,
+		&bg.PopularityScore,
+		&bg.ImageURL,
+		&bg.CreatedAt,
+		&bg.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch board game by external ID: %v", err)
+	}
+
+	return &bg, nil
+}
+
+// UpsertByExternalID inserts a board game or updates the one with the same external ID, returning its ID
+func (r *PostgresBoardgameRepository) UpsertByExternalID(bg *models.BoardGame) (int, error) {
+	if bg.ExternalID == "" {
+		return 0, errors.New("external ID is required for upsert")
+	}
+
+	query := `
+		INSERT INTO boardgames (
+			external_id, title, description, min_players, max_players,
+			play_time_min, play_time_max, categories, rating_avg, rating_count,
+			popularity_score, image_url, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW(), NOW())
+		ON CONFLICT (external_id) DO UPDATE
+		SET title = EXCLUDED.title,
+		    description = EXCLUDED.description,
+		    min_players = EXCLUDED.min_players,
+		    max_players = EXCLUDED.max_players,
+		    play_time_min = EXCLUDED.play_time_min,
+		    play_time_max = EXCLUDED.play_time_max,
+		    categories = EXCLUDED.categories,
+		    image_url = EXCLUDED.image_url,
+		    updated_at = NOW()
+		RETURNING id
+	`
+
+	var id int
+	err := connection.DB.QueryRow(context.Background(), query,
+		bg.ExternalID,
+		bg.Title,
+		bg.Description,
+		bg.MinPlayers,
+		bg.MaxPlayers,
+		bg.PlayTimeMin,
+		bg.PlayTimeMax,
+		bg.Categories,
+		bg.RatingAvg,
+		bg.RatingCount,
+		bg.PopularityScore,
+		bg.ImageURL,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to upsert board game %s: %v", bg.ExternalID, err)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,425 @@
+package collections
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"guru-game/internal/db/repository/boardgame"
+	"guru-game/models"
+)
+
+var (
+	// ErrEntryNotFound is returned when a game does not have the given collection status
+	ErrEntryNotFound = errors.New("collection entry not found")
+	// ErrShelfNotFound is returned when no shelf matches the given ID
+	ErrShelfNotFound = errors.New("shelf not found")
+	// ErrShelfNameTaken is returned when the user already has a shelf with the same name
+	ErrShelfNameTaken = errors.New("a shelf with this name already exists")
+	// ErrBoardgameNotFound is returned when a game that does not exist is added
+	ErrBoardgameNotFound = errors.New("board game not found")
+)
+
+// Sort orders for collection and shelf listings
+const (
+	SortAdded      = "added"
+	SortTitle      = "title"
+	SortRating     = "rating"
+	SortPopularity = "popularity"
+	SortPriority   = "priority" // wishlist priority, most wanted first
+)
+
+// GameQuery lists games in a collection or on a shelf. Status is ignored for shelves.
+type GameQuery struct {
+	UserID int64
+	Status string // empty means any status
+	Filter boardgame.StreamFilter
+	Sort   string
+	Limit  int
+	Offset int
+}
+
+// CollectionRepository defines the interface for collection and shelf database operations
+type CollectionRepository interface {
+	ListGames(ctx context.Context, q GameQuery) ([]models.CollectionGame, error)
+	GetEntries(ctx context.Context, userID int64, boardgameID int) ([]models.CollectionEntry, error)
+	SetEntry(ctx context.Context, userID int64, boardgameID int, e *models.CollectionEntry) error
+	RemoveEntry(ctx context.Context, userID int64, boardgameID int, status string) error
+	OwnedGameIDs(ctx context.Context, userID int64) (map[int]bool, error)
+
+	ListShelves(ctx context.Context, userID int64) ([]models.Shelf, error)
+	GetShelf(ctx context.Context, id int64) (*models.Shelf, error)
+	CreateShelf(ctx context.Context, s *models.Shelf) error
+	UpdateShelf(ctx context.Context, s *models.Shelf) error
+	DeleteShelf(ctx context.Context, id int64) error
+	AddToShelf(ctx context.Context, shelfID int64, boardgameID int) error
+	RemoveFromShelf(ctx context.Context, shelfID int64, boardgameID int) error
+	ListShelfGames(ctx context.Context, shelfID int64, q GameQuery) ([]models.BoardGame, error)
+}
+
+// PostgresCollectionRepository handles database operations for collections using pgxpool
+type PostgresCollectionRepository struct {
+	DB *pgxpool.Pool
+}
+
+// NewPostgresCollectionRepository creates a new PostgresCollectionRepository
+func NewPostgresCollectionRepository(db *pgxpool.Pool) *PostgresCollectionRepository {
+	return &PostgresCollectionRepository{DB: db}
+}
+
+const gameColumns = `b.id, COALESCE(b.external_id, ''), b.title, b.description, b.min_players, b.max_players,
+	b.play_time_min, b.play_time_max, b.categories, b.rating_avg, b.rating_count, b.popularity_score,
+	b.image_url, b.created_at, b.updated_at`
+
+func scanGame(row pgx.CollectableRow) (models.BoardGame, error) {
+	var bg models.BoardGame
+	err := row.Scan(&bg.ID, &bg.ExternalID, &bg.Title, &bg.Description, &bg.MinPlayers, &bg.MaxPlayers,
+		&bg.PlayTimeMin, &bg.PlayTimeMax, &bg.Categories, &bg.RatingAvg, &bg.RatingCount, &bg.PopularityScore,
+		&bg.ImageURL, &bg.CreatedAt, &bg.UpdatedAt)
+	return bg, err
+}
+
+// orderBy returns the ORDER BY clause for sort; the joined listing exposes added_at and priority as c.*
+func orderBy(sort string) string {
+	switch sort {
+	case SortTitle:
+		return "b.title, b.id"
+	case SortRating:
+		return "b.rating_avg DESC, b.id"
+	case SortPopularity:
+		return "b.popularity_score DESC, b.id"
+	case SortPriority:
+		return "c.priority ASC NULLS LAST, c.added_at DESC, b.id"
+	default:
+		return "c.added_at DESC, b.id"
+	}
+}
+
+// ListGames returns the games in a user's collection that match q, with all their statuses
+func (r *PostgresCollectionRepository) ListGames(ctx context.Context, q GameQuery) ([]models.CollectionGame, error) {
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	statusCond := ""
+	userArg := arg(q.UserID)
+	if q.Status != "" {
+		statusCond = " AND status = " + arg(q.Status)
+	}
+	conditions := q.Filter.SQLConditions("b.", arg)
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := `
+		SELECT ` + gameColumns + `
+		FROM boardgames b
+		JOIN (
+			SELECT boardgame_id, MAX(added_at) AS added_at, MIN(wishlist_priority) AS priority
+			FROM user_collection
+			WHERE user_id = ` + userArg + statusCond + `
+			GROUP BY boardgame_id
+		) c ON c.boardgame_id = b.id
+		` + where + `
+		ORDER BY ` + orderBy(q.Sort) + `
+		LIMIT ` + arg(q.Limit) + ` OFFSET ` + arg(q.Offset)
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error listing collection of user %d: %v", q.UserID, err)
+		return nil, fmt.Errorf("failed to list collection: %w", err)
+	}
+	games, err := pgx.CollectRows(rows, scanGame)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan collection: %w", err)
+	}
+	if len(games) == 0 {
+		return []models.CollectionGame{}, nil
+	}
+
+	ids := make([]int, len(games))
+	list := make([]models.CollectionGame, len(games))
+	index := make(map[int]int, len(games))
+	for i, g := range games {
+		ids[i] = g.ID
+		list[i] = models.CollectionGame{Game: g, Entries: []models.CollectionEntry{}}
+		index[g.ID] = i
+	}
+
+	entryRows, err := r.DB.Query(ctx, `
+		SELECT boardgame_id, status, wishlist_priority, notes, added_at, updated_at
+		FROM user_collection
+		WHERE user_id = $1 AND boardgame_id = ANY($2)
+		ORDER BY boardgame_id, status
+	`, q.UserID, ids)
+	if err != nil {
+		log.Printf("Error fetching collection entries of user %d: %v", q.UserID, err)
+		return nil, fmt.Errorf("failed to fetch collection entries: %w", err)
+	}
+	defer entryRows.Close()
+	for entryRows.Next() {
+		var gameID int
+		var e models.CollectionEntry
+		if err := entryRows.Scan(&gameID, &e.Status, &e.WishlistPriority, &e.Notes, &e.AddedAt, &e.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan collection entry: %w", err)
+		}
+		i := index[gameID]
+		list[i].Entries = append(list[i].Entries, e)
+	}
+	return list, entryRows.Err()
+}
+
+// GetEntries returns every status the user gave a game
+func (r *PostgresCollectionRepository) GetEntries(ctx context.Context, userID int64, boardgameID int) ([]models.CollectionEntry, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT status, wishlist_priority, notes, added_at, updated_at
+		FROM user_collection
+		WHERE user_id = $1 AND boardgame_id = $2
+		ORDER BY status
+	`, userID, boardgameID)
+	if err != nil {
+		log.Printf("Error fetching collection entries of user %d for game %d: %v", userID, boardgameID, err)
+		return nil, fmt.Errorf("failed to fetch collection entries: %w", err)
+	}
+
+	entries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.CollectionEntry, error) {
+		var e models.CollectionEntry
+		err := row.Scan(&e.Status, &e.WishlistPriority, &e.Notes, &e.AddedAt, &e.UpdatedAt)
+		return e, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan collection entries: %w", err)
+	}
+	return entries, nil
+}
+
+// SetEntry adds a status to a game in the user's collection or updates its priority and notes
+func (r *PostgresCollectionRepository) SetEntry(ctx context.Context, userID int64, boardgameID int, e *models.CollectionEntry) error {
+	err := r.DB.QueryRow(ctx, `
+		INSERT INTO user_collection (user_id, boardgame_id, status, wishlist_priority, notes)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, boardgame_id, status) DO UPDATE
+		SET wishlist_priority = EXCLUDED.wishlist_priority,
+		    notes = EXCLUDED.notes,
+		    updated_at = NOW()
+		RETURNING added_at, updated_at
+	`, userID, boardgameID, e.Status, e.WishlistPriority, e.Notes).Scan(&e.AddedAt, &e.UpdatedAt)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return ErrBoardgameNotFound
+		}
+		log.Printf("Error saving collection entry for user %d, game %d: %v", userID, boardgameID, err)
+		return fmt.Errorf("failed to save collection entry: %w", err)
+	}
+	return nil
+}
+
+// RemoveEntry removes one status from a game in the user's collection
+func (r *PostgresCollectionRepository) RemoveEntry(ctx context.Context, userID int64, boardgameID int, status string) error {
+	tag, err := r.DB.Exec(ctx, `
+		DELETE FROM user_collection WHERE user_id = $1 AND boardgame_id = $2 AND status = $3
+	`, userID, boardgameID, status)
+	if err != nil {
+		log.Printf("Error removing collection entry for user %d, game %d: %v", userID, boardgameID, err)
+		return fmt.Errorf("failed to remove collection entry: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrEntryNotFound
+	}
+	return nil
+}
+
+// OwnedGameIDs returns the IDs of the games the user currently owns
+func (r *PostgresCollectionRepository) OwnedGameIDs(ctx context.Context, userID int64) (map[int]bool, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT boardgame_id FROM user_collection WHERE user_id = $1 AND status = $2
+	`, userID, models.CollectionOwned)
+	if err != nil {
+		log.Printf("Error fetching owned games of user %d: %v", userID, err)
+		return nil, fmt.Errorf("failed to fetch owned games: %w", err)
+	}
+	defer rows.Close()
+
+	owned := make(map[int]bool)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan owned game: %w", err)
+		}
+		owned[id] = true
+	}
+	return owned, rows.Err()
+}
+
+const shelfColumns = `s.id, s.user_id, s.name, s.description,
+	(SELECT COUNT(*) FROM user_shelf_games g WHERE g.shelf_id = s.id)::int, s.created_at, s.updated_at`
+
+func scanShelf(row pgx.Row) (*models.Shelf, error) {
+	var s models.Shelf
+	err := row.Scan(&s.ID, &s.UserID, &s.Name, &s.Description, &s.GameCount, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrShelfNotFound
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListShelves returns the user's shelves ordered by name
+func (r *PostgresCollectionRepository) ListShelves(ctx context.Context, userID int64) ([]models.Shelf, error) {
+	rows, err := r.DB.Query(ctx, `SELECT `+shelfColumns+` FROM user_shelves s WHERE s.user_id = $1 ORDER BY lower(s.name)`, userID)
+	if err != nil {
+		log.Printf("Error listing shelves of user %d: %v", userID, err)
+		return nil, fmt.Errorf("failed to list shelves: %w", err)
+	}
+	shelves, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Shelf, error) {
+		s, err := scanShelf(row)
+		if err != nil {
+			return models.Shelf{}, err
+		}
+		return *s, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan shelves: %w", err)
+	}
+	return shelves, nil
+}
+
+// GetShelf returns a shelf by ID
+func (r *PostgresCollectionRepository) GetShelf(ctx context.Context, id int64) (*models.Shelf, error) {
+	s, err := scanShelf(r.DB.QueryRow(ctx, `SELECT `+shelfColumns+` FROM user_shelves s WHERE s.id = $1`, id))
+	if err != nil && !errors.Is(err, ErrShelfNotFound) {
+		log.Printf("Error fetching shelf %d: %v", id, err)
+		return nil, fmt.Errorf("failed to fetch shelf: %w", err)
+	}
+	return s, err
+}
+
+// CreateShelf inserts a shelf and sets its ID and timestamps
+func (r *PostgresCollectionRepository) CreateShelf(ctx context.Context, s *models.Shelf) error {
+	err := r.DB.QueryRow(ctx, `
+		INSERT INTO user_shelves (user_id, name, description)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`, s.UserID, s.Name, s.Description).Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return shelfWriteError(err)
+	}
+	return nil
+}
+
+// UpdateShelf renames a shelf or changes its description
+func (r *PostgresCollectionRepository) UpdateShelf(ctx context.Context, s *models.Shelf) error {
+	err := r.DB.QueryRow(ctx, `
+		UPDATE user_shelves SET name = $2, description = $3, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`, s.ID, s.Name, s.Description).Scan(&s.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrShelfNotFound
+		}
+		return shelfWriteError(err)
+	}
+	return nil
+}
+
+// DeleteShelf removes a shelf; the games themselves stay in the collection
+func (r *PostgresCollectionRepository) DeleteShelf(ctx context.Context, id int64) error {
+	tag, err := r.DB.Exec(ctx, `DELETE FROM user_shelves WHERE id = $1`, id)
+	if err != nil {
+		log.Printf("Error deleting shelf %d: %v", id, err)
+		return fmt.Errorf("failed to delete shelf: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrShelfNotFound
+	}
+	return nil
+}
+
+// AddToShelf puts a game on a shelf; adding it twice is a no-op
+func (r *PostgresCollectionRepository) AddToShelf(ctx context.Context, shelfID int64, boardgameID int) error {
+	_, err := r.DB.Exec(ctx, `
+		INSERT INTO user_shelf_games (shelf_id, boardgame_id) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, shelfID, boardgameID)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return ErrBoardgameNotFound
+		}
+		log.Printf("Error adding game %d to shelf %d: %v", boardgameID, shelfID, err)
+		return fmt.Errorf("failed to add game to shelf: %w", err)
+	}
+	_, err = r.DB.Exec(ctx, `UPDATE user_shelves SET updated_at = NOW() WHERE id = $1`, shelfID)
+	return err
+}
+
+// RemoveFromShelf takes a game off a shelf
+func (r *PostgresCollectionRepository) RemoveFromShelf(ctx context.Context, shelfID int64, boardgameID int) error {
+	tag, err := r.DB.Exec(ctx, `DELETE FROM user_shelf_games WHERE shelf_id = $1 AND boardgame_id = $2`, shelfID, boardgameID)
+	if err != nil {
+		log.Printf("Error removing game %d from shelf %d: %v", boardgameID, shelfID, err)
+		return fmt.Errorf("failed to remove game from shelf: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrEntryNotFound
+	}
+	return nil
+}
+
+// ListShelfGames returns the games on a shelf that match q's catalogue filter
+func (r *PostgresCollectionRepository) ListShelfGames(ctx context.Context, shelfID int64, q GameQuery) ([]models.BoardGame, error) {
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := append([]string{"c.shelf_id = " + arg(shelfID)}, q.Filter.SQLConditions("b.", arg)...)
+	sort := q.Sort
+	if sort == SortPriority {
+		sort = SortAdded
+	}
+	query := `
+		SELECT ` + gameColumns + `
+		FROM user_shelf_games c
+		JOIN boardgames b ON b.id = c.boardgame_id
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY ` + orderBy(sort) + `
+		LIMIT ` + arg(q.Limit) + ` OFFSET ` + arg(q.Offset)
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error listing games on shelf %d: %v", shelfID, err)
+		return nil, fmt.Errorf("failed to list shelf games: %w", err)
+	}
+	games, err := pgx.CollectRows(rows, scanGame)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan shelf games: %w", err)
+	}
+	return games, nil
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+func shelfWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrShelfNameTaken
+	}
+	log.Printf("Error saving shelf: %v", err)
+	return fmt.Errorf("failed to save shelf: %w", err)
+}
@@ -2,17 +2,42 @@ package game_rules
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"guru-game/models"
 )
 
+var (
+	// ErrRuleNotFound is returned when no game rule matches the given ID
+	ErrRuleNotFound = errors.New("game rule not found")
+	// ErrVersionNotFound is returned when a rule has no snapshot with the given version
+	ErrVersionNotFound = errors.New("game rule version not found")
+	// ErrBoardgameNotFound is returned when a rule references a boardgame that does not exist
+	ErrBoardgameNotFound = errors.New("boardgame not found")
+)
+
 // GameRuleRepository defines the interface for game rules database operations
 type GameRuleRepository interface {
 	GetRulesByBoardgameID(ctx context.Context, boardgameID int) ([]models.GameRule, error)
+	GetByID(ctx context.Context, id int) (*models.GameRule, error)
+	Create(ctx context.Context, rule *models.GameRule) error
+	Update(ctx context.Context, rule *models.GameRule) error
+	Delete(ctx context.Context, id int) error
+	GetVersions(ctx context.Context, ruleID int) ([]models.GameRuleVersion, error)
+	GetVersion(ctx context.Context, ruleID int, version int) (*models.GameRuleVersion, error)
+	Search(ctx context.Context, params SearchParams) ([]models.RuleSearchHit, error)
+}
+
+const ruleColumns = `id, boardgame_id, title, steps, language, version, updated_by, updated_at`
+
+func scanRule(row pgx.Row, rule *models.GameRule) error {
+	return row.Scan(&rule.ID, &rule.BoardgameID, &rule.Title, &rule.Steps, &rule.Language, &rule.Version, &rule.UpdatedBy, &rule.UpdatedAt)
 }
 
 // PostgresGameRuleRepository handles database operations for GameRule using pgxpool
@@ -28,9 +53,10 @@ func NewPostgresGameRuleRepository(db *pgxpool.Pool) *PostgresGameRuleRepository
 // GetRulesByBoardgameID fetches game rules for a given boardgame ID from PostgreSQL
 func (r *PostgresGameRuleRepository) GetRulesByBoardgameID(ctx context.Context, boardgameID int) ([]models.GameRule, error) {
 	query := `
-		SELECT id, boardgame_id, title, steps
+		SELECT ` + ruleColumns + `
 		FROM game_rules
 		WHERE boardgame_id = $1
+		ORDER BY id
 	`
 
 	rows, err := r.DB.Query(ctx, query, boardgameID)
@@ -43,7 +69,7 @@ func (r *PostgresGameRuleRepository) GetRulesByBoardgameID(ctx context.Context,
 	var gameRules []models.GameRule
 	for rows.Next() {
 		var rule models.GameRule
-		err := rows.Scan(&rule.ID, &rule.BoardgameID, &rule.Title, &rule.Steps)
+		err := scanRule(rows, &rule)
 		if err != nil {
 			log.Printf("Error scanning game rule row for boardgame %d: %v", boardgameID, err)
 			return nil, fmt.Errorf("failed to scan game rule row: %w", err)
@@ -61,3 +87,184 @@ func (r *PostgresGameRuleRepository) GetRulesByBoardgameID(ctx context.Context,
 
 	return gameRules, nil
 }
+
+// GetByID fetches a single game rule by its ID
+func (r *PostgresGameRuleRepository) GetByID(ctx context.Context, id int) (*models.GameRule, error) {
+	query := `
+		SELECT ` + ruleColumns + `
+		FROM game_rules
+		WHERE id = $1
+	`
+
+	var rule models.GameRule
+	err := scanRule(r.DB.QueryRow(ctx, query, id), &rule)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRuleNotFound
+		}
+		log.Printf("Error fetching game rule %d: %v", id, err)
+		return nil, fmt.Errorf("failed to fetch game rule: %w", err)
+	}
+
+	return &rule, nil
+}
+
+// Create inserts a new game rule as version 1 and records it in the version history
+func (r *PostgresGameRuleRepository) Create(ctx context.Context, rule *models.GameRule) error {
+	query := `
+		INSERT INTO game_rules (boardgame_id, title, steps, language, version, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, 1, $5, NOW())
+		RETURNING ` + ruleColumns
+
+	err := r.inTx(ctx, func(tx pgx.Tx) error {
+		row := tx.QueryRow(ctx, query, rule.BoardgameID, rule.Title, rule.Steps, rule.Language, rule.UpdatedBy)
+		if err := scanRule(row, rule); err != nil {
+			return err
+		}
+		if err := insertVersion(ctx, tx, rule); err != nil {
+			return err
+		}
+		return reindexSteps(ctx, tx, rule)
+	})
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return ErrBoardgameNotFound
+		}
+		log.Printf("Error creating game rule for boardgame %d: %v", rule.BoardgameID, err)
+		return fmt.Errorf("failed to create game rule: %w", err)
+	}
+
+	log.Printf("Game rule %d created for boardgame %d", rule.ID, rule.BoardgameID)
+
+	return nil
+}
+
+// Update replaces the title, language and steps of an existing game rule, bumping its version
+func (r *PostgresGameRuleRepository) Update(ctx context.Context, rule *models.GameRule) error {
+	query := `
+		UPDATE game_rules
+		SET title = $2, steps = $3, language = $4, updated_by = $5,
+		    version = version + 1, updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + ruleColumns
+
+	err := r.inTx(ctx, func(tx pgx.Tx) error {
+		row := tx.QueryRow(ctx, query, rule.ID, rule.Title, rule.Steps, rule.Language, rule.UpdatedBy)
+		if err := scanRule(row, rule); err != nil {
+			return err
+		}
+		if err := insertVersion(ctx, tx, rule); err != nil {
+			return err
+		}
+		return reindexSteps(ctx, tx, rule)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrRuleNotFound
+		}
+		log.Printf("Error updating game rule %d: %v", rule.ID, err)
+		return fmt.Errorf("failed to update game rule: %w", err)
+	}
+
+	log.Printf("Game rule %d updated to version %d", rule.ID, rule.Version)
+
+	return nil
+}
+
+// Delete removes a game rule
+func (r *PostgresGameRuleRepository) Delete(ctx context.Context, id int) error {
+	tag, err := r.DB.Exec(ctx, `DELETE FROM game_rules WHERE id = $1`, id)
+	if err != nil {
+		log.Printf("Error deleting game rule %d: %v", id, err)
+		return fmt.Errorf("failed to delete game rule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRuleNotFound
+	}
+
+	log.Printf("Game rule %d deleted", id)
+
+	return nil
+}
+
+// GetVersions lists every stored version of a rule set, newest first
+func (r *PostgresGameRuleRepository) GetVersions(ctx context.Context, ruleID int) ([]models.GameRuleVersion, error) {
+	query := `
+		SELECT rule_id, version, language, title, steps, author_id, created_at
+		FROM game_rule_versions
+		WHERE rule_id = $1
+		ORDER BY version DESC
+	`
+
+	rows, err := r.DB.Query(ctx, query, ruleID)
+	if err != nil {
+		log.Printf("Error fetching versions for game rule %d: %v", ruleID, err)
+		return nil, fmt.Errorf("failed to fetch game rule versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []models.GameRuleVersion
+	for rows.Next() {
+		var v models.GameRuleVersion
+		if err := rows.Scan(&v.RuleID, &v.Version, &v.Language, &v.Title, &v.Steps, &v.AuthorID, &v.CreatedAt); err != nil {
+			log.Printf("Error scanning version row for game rule %d: %v", ruleID, err)
+			return nil, fmt.Errorf("failed to scan game rule version row: %w", err)
+		}
+		versions = append(versions, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("Error after iterating over version rows for game rule %d: %v", ruleID, err)
+		return nil, fmt.Errorf("error after fetching game rule versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// GetVersion fetches one stored version of a rule set
+func (r *PostgresGameRuleRepository) GetVersion(ctx context.Context, ruleID int, version int) (*models.GameRuleVersion, error) {
+	query := `
+		SELECT rule_id, version, language, title, steps, author_id, created_at
+		FROM game_rule_versions
+		WHERE rule_id = $1 AND version = $2
+	`
+
+	var v models.GameRuleVersion
+	err := r.DB.QueryRow(ctx, query, ruleID, version).Scan(&v.RuleID, &v.Version, &v.Language, &v.Title, &v.Steps, &v.AuthorID, &v.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrVersionNotFound
+		}
+		log.Printf("Error fetching version %d of game rule %d: %v", version, ruleID, err)
+		return nil, fmt.Errorf("failed to fetch game rule version: %w", err)
+	}
+
+	return &v, nil
+}
+
+// inTx runs fn in a transaction, committing only if fn succeeds
+func (r *PostgresGameRuleRepository) inTx(ctx context.Context, fn func(pgx.Tx) error) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func insertVersion(ctx context.Context, tx pgx.Tx, rule *models.GameRule) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO game_rule_versions (rule_id, version, language, title, steps, author_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, rule.ID, rule.Version, rule.Language, rule.Title, rule.Steps, rule.UpdatedBy, rule.UpdatedAt)
+	return err
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
@@ -0,0 +1,154 @@
+package game_rules
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+
+	"guru-game/models"
+)
+
+// SearchParams scopes a rule search. BoardgameID 0 and Language "" search everything.
+type SearchParams struct {
+	Query       string
+	BoardgameID int
+	Language    string
+	Limit       int
+	Offset      int
+}
+
+// Search finds rule steps matching a free-text query. English steps are matched with
+// stemmed full-text search; Thai steps (which Postgres cannot tokenize) fall back to
+// substring matching, so both conditions are OR-ed and ranked together.
+func (r *PostgresGameRuleRepository) Search(ctx context.Context, params SearchParams) ([]models.RuleSearchHit, error) {
+	query := `
+		SELECT s.rule_id, g.boardgame_id, g.title, s.language, s.step, s.step_title,
+		       ts_headline(s.config, s.body, q, $7),
+		       ts_rank(s.tsv, q) + CASE WHEN s.body ILIKE $2 THEN 0.1 ELSE 0 END AS rank,
+		       s.body
+		FROM game_rule_step_search s
+		JOIN game_rules g ON g.id = s.rule_id
+		CROSS JOIN LATERAL websearch_to_tsquery(s.config, $1) AS q
+		WHERE (s.tsv @@ q OR s.body ILIKE $2)
+		  AND ($3 = 0 OR g.boardgame_id = $3)
+		  AND ($4 = '' OR s.language = $4)
+		ORDER BY rank DESC, s.rule_id, s.step
+		LIMIT $5 OFFSET $6
+	`
+
+	pattern := "%" + escapeLike(params.Query) + "%"
+	options := fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxWords=35, MinWords=12, MaxFragments=2`, markStart, markStop)
+	rows, err := r.DB.Query(ctx, query, params.Query, pattern, params.BoardgameID, params.Language, params.Limit, params.Offset, options)
+	if err != nil {
+		log.Printf("Error searching game rules for %q: %v", params.Query, err)
+		return nil, fmt.Errorf("failed to search game rules: %w", err)
+	}
+	defer rows.Close()
+
+	var hits []models.RuleSearchHit
+	for rows.Next() {
+		var hit models.RuleSearchHit
+		var body string
+		if err := rows.Scan(&hit.RuleID, &hit.BoardgameID, &hit.RuleTitle, &hit.Language, &hit.Step, &hit.StepTitle, &hit.Snippet, &hit.Rank, &body); err != nil {
+			return nil, fmt.Errorf("failed to scan game rule search row: %w", err)
+		}
+		// ts_headline cannot mark Thai matches, so highlight the substring ourselves
+		if strings.Contains(hit.Snippet, markStart) {
+			hit.Snippet = markedToHTML(hit.Snippet)
+		} else if snippet, ok := highlightSubstring(body, params.Query, 60); ok {
+			hit.Snippet = snippet
+		} else {
+			hit.Snippet = html.EscapeString(hit.Snippet)
+		}
+		hits = append(hits, hit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after searching game rules: %w", err)
+	}
+
+	return hits, nil
+}
+
+// reindexSteps replaces the search rows of a rule set; called inside the create/update transaction
+func reindexSteps(ctx context.Context, tx pgx.Tx, rule *models.GameRule) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM game_rule_step_search WHERE rule_id = $1`, rule.ID); err != nil {
+		return fmt.Errorf("failed to clear rule search index: %w", err)
+	}
+
+	config := "simple"
+	if rule.Language == "en" {
+		config = "english"
+	}
+
+	batch := &pgx.Batch{}
+	for _, step := range rule.Steps {
+		body := strings.Join(append([]string{rule.Title, step.Title, step.Description}, step.Tips...), "\n")
+		batch.Queue(`
+			INSERT INTO game_rule_step_search (rule_id, step, language, config, step_title, body)
+			VALUES ($1, $2, $3, $4::regconfig, $5, $6)
+		`, rule.ID, step.Step, rule.Language, config, step.Title, body)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to index rule steps: %w", err)
+	}
+	return nil
+}
+
+// Placeholders ts_headline puts around matches. They are control characters, so they
+// survive HTML escaping and cannot be confused with markup in the text.
+const (
+	markStart = "\x02"
+	markStop  = "\x03"
+)
+
+// markedToHTML escapes a ts_headline snippet and turns its placeholders into <mark></mark>
+func markedToHTML(snippet string) string {
+	return strings.NewReplacer(markStart, "<mark>", markStop, "</mark>").Replace(html.EscapeString(snippet))
+}
+
+// highlightSubstring wraps the first case-insensitive occurrence of query in <mark></mark>,
+// keeping about radius characters of context on each side. The text is HTML-escaped.
+func highlightSubstring(body, query string, radius int) (string, bool) {
+	text := []rune(body)
+	needle := []rune(strings.TrimSpace(query))
+	if len(needle) == 0 || len(needle) > len(text) {
+		return "", false
+	}
+
+	match := -1
+	for i := 0; i+len(needle) <= len(text) && match < 0; i++ {
+		match = i
+		for j := range needle {
+			if unicode.ToLower(text[i+j]) != unicode.ToLower(needle[j]) {
+				match = -1
+				break
+			}
+		}
+	}
+	if match < 0 {
+		return "", false
+	}
+
+	start, end := match-radius, match+len(needle)+radius
+	prefix, suffix := "…", "…"
+	if start <= 0 {
+		start, prefix = 0, ""
+	}
+	if end >= len(text) {
+		end, suffix = len(text), ""
+	}
+
+	return prefix + html.EscapeString(string(text[start:match])) +
+		"<mark>" + html.EscapeString(string(text[match:match+len(needle)])) + "</mark>" +
+		html.EscapeString(string(text[match+len(needle):end])) + suffix, true
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,45 @@
+package game_rules
+
+import "testing"
+
+func TestMarkedToHTML(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"plain", "roll the " + markStart + "dice" + markStop, "roll the <mark>dice</mark>"},
+		{"escapes markup", `<img src=x onerror=alert(1)> ` + markStart + "draw" + markStop, `&lt;img src=x onerror=alert(1)&gt; <mark>draw</mark>`},
+		{"escapes quotes and ampersands", `"cards" & ` + markStart + "tokens" + markStop, `&#34;cards&#34; &amp; <mark>tokens</mark>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markedToHTML(tt.in); got != tt.want {
+				t.Errorf("markedToHTML(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHighlightSubstring(t *testing.T) {
+	tests := []struct {
+		name, body, query string
+		radius            int
+		want              string
+		ok                bool
+	}{
+		{"case insensitive", "Each player draws a Card", "card", 60, "Each player draws a <mark>Card</mark>", true},
+		{"context is trimmed", "aaaaa match bbbbb", "match", 2, "…a <mark>match</mark> b…", true},
+		{"thai", "ผู้เล่นทอยลูกเต๋า", "ลูกเต๋า", 60, "ผู้เล่นทอย<mark>ลูกเต๋า</mark>", true},
+		{"escapes html around the match", "<b>win</b> the game", "win", 60, "&lt;b&gt;<mark>win</mark>&lt;/b&gt; the game", true},
+		{"escapes html in the match", "use <script> tags", "<script>", 60, "use <mark>&lt;script&gt;</mark> tags", true},
+		{"no match", "roll dice", "cards", 60, "", false},
+		{"blank query", "roll dice", "  ", 60, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := highlightSubstring(tt.body, tt.query, tt.radius)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("highlightSubstring(%q, %q, %d) = %q, %v, want %q, %v", tt.body, tt.query, tt.radius, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,369 @@
+package gamenights
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"guru-game/models"
+)
+
+var (
+	// ErrGameNightNotFound is returned when no game night matches the given ID
+	ErrGameNightNotFound = errors.New("game night not found")
+	// ErrNotInvited is returned when a user answers or votes for a game night they are not invited to
+	ErrNotInvited = errors.New("you are not invited to this game night")
+	// ErrUnknownUser is returned when an invitee is not a registered user
+	ErrUnknownUser = errors.New("invitee is not a registered user")
+)
+
+// ShortlistQuery selects the candidate games of a game night. PlayerCount is the number of
+// confirmed players the games must support; MaxPlayTime 0 means no limit.
+type ShortlistQuery struct {
+	GameNightID int64
+	ViewerID    int64
+	PlayerCount int
+	MaxPlayTime int
+}
+
+// GameNightRepository defines the interface for game night database operations
+type GameNightRepository interface {
+	Create(ctx context.Context, n *models.GameNight, invitees []int64) error
+	GetByID(ctx context.Context, id int64) (*models.GameNight, error)
+	Update(ctx context.Context, n *models.GameNight) error
+	SetStatus(ctx context.Context, id int64, status string) error
+	ListForUser(ctx context.Context, userID int64, from time.Time) ([]models.GameNight, error)
+	AddInvites(ctx context.Context, id int64, userIDs []int64) error
+	RemoveInvite(ctx context.Context, id, userID int64) error
+	SetRSVP(ctx context.Context, id, userID int64, rsvp string) error
+	Shortlist(ctx context.Context, q ShortlistQuery) ([]models.ShortlistGame, error)
+	SetVote(ctx context.Context, id, userID int64, boardgameID int, vote bool) error
+	CoAttendeeIDs(ctx context.Context, userID int64) (map[int64]bool, error)
+}
+
+// PostgresGameNightRepository handles database operations for game nights using pgxpool
+type PostgresGameNightRepository struct {
+	DB *pgxpool.Pool
+}
+
+// NewPostgresGameNightRepository creates a new PostgresGameNightRepository
+func NewPostgresGameNightRepository(db *pgxpool.Pool) *PostgresGameNightRepository {
+	return &PostgresGameNightRepository{DB: db}
+}
+
+const gameNightColumns = `n.id, n.host_id, COALESCE(u.username, ''), n.title, n.starts_at, n.location, n.notes, n.status,
+	(SELECT COUNT(*)::int FROM game_night_invites i WHERE i.game_night_id = n.id AND i.rsvp = 'yes'),
+	n.created_at, n.updated_at`
+
+func scanGameNight(row pgx.Row) (*models.GameNight, error) {
+	var n models.GameNight
+	err := row.Scan(&n.ID, &n.HostID, &n.HostUsername, &n.Title, &n.StartsAt, &n.Location, &n.Notes, &n.Status,
+		&n.ConfirmedCount, &n.CreatedAt, &n.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrGameNightNotFound
+		}
+		return nil, err
+	}
+	n.Invites = []models.GameNightInvite{}
+	return &n, nil
+}
+
+// Create inserts a game night with the host as a confirmed attendee and the other invitees
+func (r *PostgresGameNightRepository) Create(ctx context.Context, n *models.GameNight, invitees []int64) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO game_nights (host_id, title, starts_at, location, notes)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, status, created_at, updated_at
+	`, n.HostID, n.Title, n.StartsAt, n.Location, n.Notes).Scan(&n.ID, &n.Status, &n.CreatedAt, &n.UpdatedAt)
+	if err != nil {
+		log.Printf("Error creating game night for user %d: %v", n.HostID, err)
+		return fmt.Errorf("failed to create game night: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO game_night_invites (game_night_id, user_id, rsvp, responded_at) VALUES ($1, $2, 'yes', NOW())
+	`, n.ID, n.HostID); err != nil {
+		log.Printf("Error adding host to game night %d: %v", n.ID, err)
+		return fmt.Errorf("failed to add host: %w", err)
+	}
+	if err := insertInvites(ctx, tx, n.ID, invitees); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit game night: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a game night with its invitees
+func (r *PostgresGameNightRepository) GetByID(ctx context.Context, id int64) (*models.GameNight, error) {
+	n, err := scanGameNight(r.DB.QueryRow(ctx, `
+		SELECT `+gameNightColumns+`
+		FROM game_nights n
+		LEFT JOIN users u ON u.id = n.host_id
+		WHERE n.id = $1
+	`, id))
+	if err != nil {
+		if !errors.Is(err, ErrGameNightNotFound) {
+			log.Printf("Error fetching game night %d: %v", id, err)
+			return nil, fmt.Errorf("failed to fetch game night: %w", err)
+		}
+		return nil, err
+	}
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT i.user_id, COALESCE(u.username, ''), i.rsvp, i.invited_at, i.responded_at
+		FROM game_night_invites i
+		LEFT JOIN users u ON u.id = i.user_id
+		WHERE i.game_night_id = $1
+		ORDER BY i.invited_at, i.user_id
+	`, id)
+	if err != nil {
+		log.Printf("Error fetching invites of game night %d: %v", id, err)
+		return nil, fmt.Errorf("failed to fetch invites: %w", err)
+	}
+	invites, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.GameNightInvite, error) {
+		var inv models.GameNightInvite
+		err := row.Scan(&inv.UserID, &inv.Username, &inv.RSVP, &inv.InvitedAt, &inv.RespondedAt)
+		return inv, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan invites: %w", err)
+	}
+	n.Invites = invites
+	return n, nil
+}
+
+// Update replaces a game night's title, time, location and notes
+func (r *PostgresGameNightRepository) Update(ctx context.Context, n *models.GameNight) error {
+	tag, err := r.DB.Exec(ctx, `
+		UPDATE game_nights
+		SET title = $2, starts_at = $3, location = $4, notes = $5, updated_at = NOW()
+		WHERE id = $1
+	`, n.ID, n.Title, n.StartsAt, n.Location, n.Notes)
+	if err != nil {
+		log.Printf("Error updating game night %d: %v", n.ID, err)
+		return fmt.Errorf("failed to update game night: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrGameNightNotFound
+	}
+	return nil
+}
+
+// SetStatus changes a game night's status
+func (r *PostgresGameNightRepository) SetStatus(ctx context.Context, id int64, status string) error {
+	tag, err := r.DB.Exec(ctx, `
+		UPDATE game_nights SET status = $2, updated_at = NOW() WHERE id = $1
+	`, id, status)
+	if err != nil {
+		log.Printf("Error setting status of game night %d: %v", id, err)
+		return fmt.Errorf("failed to update game night: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrGameNightNotFound
+	}
+	return nil
+}
+
+// ListForUser returns the game nights userID hosts or is invited to that start at or
+// after from, soonest first. Invitees are not loaded.
+func (r *PostgresGameNightRepository) ListForUser(ctx context.Context, userID int64, from time.Time) ([]models.GameNight, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+gameNightColumns+`
+		FROM game_nights n
+		LEFT JOIN users u ON u.id = n.host_id
+		WHERE n.starts_at >= $2
+		  AND EXISTS (SELECT 1 FROM game_night_invites i WHERE i.game_night_id = n.id AND i.user_id = $1)
+		ORDER BY n.starts_at, n.id
+	`, userID, from)
+	if err != nil {
+		log.Printf("Error listing game nights for user %d: %v", userID, err)
+		return nil, fmt.Errorf("failed to list game nights: %w", err)
+	}
+	list, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.GameNight, error) {
+		n, err := scanGameNight(row)
+		if err != nil {
+			return models.GameNight{}, err
+		}
+		return *n, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan game nights: %w", err)
+	}
+	return list, nil
+}
+
+// AddInvites invites users to a game night; users already invited keep their RSVP
+func (r *PostgresGameNightRepository) AddInvites(ctx context.Context, id int64, userIDs []int64) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := insertInvites(ctx, tx, id, userIDs); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit invites: %w", err)
+	}
+	return nil
+}
+
+// RemoveInvite uninvites a user and drops their votes
+func (r *PostgresGameNightRepository) RemoveInvite(ctx context.Context, id, userID int64) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `DELETE FROM game_night_invites WHERE game_night_id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		log.Printf("Error removing user %d from game night %d: %v", userID, id, err)
+		return fmt.Errorf("failed to remove invite: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotInvited
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM game_night_votes WHERE game_night_id = $1 AND user_id = $2`, id, userID); err != nil {
+		log.Printf("Error removing votes of user %d from game night %d: %v", userID, id, err)
+		return fmt.Errorf("failed to remove votes: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit invite removal: %w", err)
+	}
+	return nil
+}
+
+// SetRSVP records an invitee's answer
+func (r *PostgresGameNightRepository) SetRSVP(ctx context.Context, id, userID int64, rsvp string) error {
+	tag, err := r.DB.Exec(ctx, `
+		UPDATE game_night_invites SET rsvp = $3, responded_at = NOW()
+		WHERE game_night_id = $1 AND user_id = $2
+	`, id, userID, rsvp)
+	if err != nil {
+		log.Printf("Error saving RSVP of user %d for game night %d: %v", userID, id, err)
+		return fmt.Errorf("failed to save RSVP: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotInvited
+	}
+	return nil
+}
+
+// Shortlist returns the games owned by confirmed attendees that support q.PlayerCount
+// players, most voted first. Only votes of confirmed attendees are counted, and votes for
+// games that dropped off the list are not.
+func (r *PostgresGameNightRepository) Shortlist(ctx context.Context, q ShortlistQuery) ([]models.ShortlistGame, error) {
+	rows, err := r.DB.Query(ctx, `
+		WITH owned AS (
+			SELECT c.boardgame_id, ARRAY_AGG(DISTINCT u.username ORDER BY u.username) AS owners
+			FROM game_night_invites i
+			JOIN user_collection c ON c.user_id = i.user_id AND c.status IN ('owned', 'for_trade')
+			JOIN users u ON u.id = i.user_id
+			WHERE i.game_night_id = $1 AND i.rsvp = 'yes'
+			GROUP BY c.boardgame_id
+		)
+		SELECT b.id, b.title, b.min_players, b.max_players, b.play_time_min, b.play_time_max, COALESCE(b.image_url, ''),
+		       o.owners,
+		       (SELECT COUNT(*)::int
+		        FROM game_night_votes v
+		        JOIN game_night_invites i ON i.game_night_id = v.game_night_id AND i.user_id = v.user_id AND i.rsvp = 'yes'
+		        WHERE v.game_night_id = $1 AND v.boardgame_id = b.id),
+		       EXISTS (SELECT 1 FROM game_night_votes v WHERE v.game_night_id = $1 AND v.boardgame_id = b.id AND v.user_id = $2)
+		FROM owned o
+		JOIN boardgames b ON b.id = o.boardgame_id
+		WHERE b.min_players <= $3 AND b.max_players >= $3
+		  AND ($4 = 0 OR b.play_time_min <= $4)
+		ORDER BY 9 DESC, b.title
+	`, q.GameNightID, q.ViewerID, q.PlayerCount, q.MaxPlayTime)
+	if err != nil {
+		log.Printf("Error building shortlist for game night %d: %v", q.GameNightID, err)
+		return nil, fmt.Errorf("failed to build shortlist: %w", err)
+	}
+	games, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.ShortlistGame, error) {
+		var g models.ShortlistGame
+		err := row.Scan(&g.BoardgameID, &g.Title, &g.MinPlayers, &g.MaxPlayers, &g.PlayTimeMin, &g.PlayTimeMax, &g.ImageURL,
+			&g.Owners, &g.Votes, &g.VotedByMe)
+		return g, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan shortlist: %w", err)
+	}
+	return games, nil
+}
+
+// SetVote adds or removes a user's vote for a game
+func (r *PostgresGameNightRepository) SetVote(ctx context.Context, id, userID int64, boardgameID int, vote bool) error {
+	query := `DELETE FROM game_night_votes WHERE game_night_id = $1 AND user_id = $2 AND boardgame_id = $3`
+	if vote {
+		query = `
+			INSERT INTO game_night_votes (game_night_id, user_id, boardgame_id) VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING
+		`
+	}
+	if _, err := r.DB.Exec(ctx, query, id, userID, boardgameID); err != nil {
+		log.Printf("Error saving vote of user %d for game night %d: %v", userID, id, err)
+		return fmt.Errorf("failed to save vote: %w", err)
+	}
+	return nil
+}
+
+// CoAttendeeIDs returns the users who confirmed a game night that userID also confirmed.
+// Cancelled game nights do not count.
+func (r *PostgresGameNightRepository) CoAttendeeIDs(ctx context.Context, userID int64) (map[int64]bool, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT DISTINCT other.user_id
+		FROM game_night_invites mine
+		JOIN game_nights n ON n.id = mine.game_night_id AND n.status <> 'cancelled'
+		JOIN game_night_invites other ON other.game_night_id = mine.game_night_id AND other.rsvp = 'yes'
+		WHERE mine.user_id = $1 AND mine.rsvp = 'yes' AND other.user_id <> $1
+	`, userID)
+	if err != nil {
+		log.Printf("Error fetching co-attendees of user %d: %v", userID, err)
+		return nil, fmt.Errorf("failed to fetch co-attendees: %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan co-attendees: %w", err)
+	}
+	set := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set, nil
+}
+
+func insertInvites(ctx context.Context, tx pgx.Tx, id int64, userIDs []int64) error {
+	for _, userID := range userIDs {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO game_night_invites (game_night_id, user_id) VALUES ($1, $2)
+			ON CONFLICT (game_night_id, user_id) DO NOTHING
+		`, id, userID)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" && pgErr.ConstraintName == "game_night_invites_user_id_fkey" {
+				return ErrUnknownUser
+			}
+			log.Printf("Error inviting user %d to game night %d: %v", userID, id, err)
+			return fmt.Errorf("failed to invite user: %w", err)
+		}
+	}
+	return nil
+}
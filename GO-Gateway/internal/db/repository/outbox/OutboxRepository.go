@@ -0,0 +1,209 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Action types understood by the recommendation service
+const (
+	ActionLike = "like"
+	ActionRate = "rate"
+	ActionView = "view"
+	ActionPlay = "play"
+)
+
+// claimLockKey is the advisory lock that serializes claiming across gateway instances,
+// so two instances never claim messages of the same user at once
+const claimLockKey = 7305001
+
+// Message is one action waiting in recommendation_outbox
+type Message struct {
+	ID          int64
+	UserID      int64
+	BoardgameID int
+	ActionType  string
+	ActionValue float64
+	ActionTime  time.Time
+	Attempts    int
+}
+
+// Result is the outcome of delivering one message. A zero Err marks it delivered;
+// otherwise it is retried at RetryAt, or dead-lettered when Dead is set.
+type Result struct {
+	ID      int64
+	Err     error
+	RetryAt time.Time
+	Dead    bool
+}
+
+// OutboxRepository defines the interface for the delivery side of the outbox
+type OutboxRepository interface {
+	// DeliverBatch claims up to limit due messages for lease and records the results returned
+	// by deliver. Messages queued behind a user's backed-off or leased message are not
+	// claimed, so each user's actions are delivered in order. It returns how many messages
+	// were claimed; 0 with a nil error also means another instance was claiming.
+	DeliverBatch(ctx context.Context, limit int, lease time.Duration, deliver func([]Message) []Result) (int, error)
+	PurgeDelivered(ctx context.Context, olderThan time.Duration) (int64, error)
+}
+
+// Enqueue writes messages to the outbox using the caller's transaction,
+// so they are only persisted if the change that produced them commits
+func Enqueue(ctx context.Context, tx pgx.Tx, msgs ...Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	_, err := tx.CopyFrom(ctx,
+		pgx.Identifier{"recommendation_outbox"},
+		[]string{"user_id", "boardgame_id", "action_type", "action_value", "action_time"},
+		pgx.CopyFromSlice(len(msgs), func(i int) ([]any, error) {
+			m := msgs[i]
+			return []any{m.UserID, m.BoardgameID, m.ActionType, m.ActionValue, m.ActionTime}, nil
+		}),
+	)
+	if err != nil {
+		log.Printf("Error enqueuing %d recommendation actions: %v", len(msgs), err)
+		return fmt.Errorf("failed to enqueue recommendation actions: %w", err)
+	}
+	return nil
+}
+
+// PostgresOutboxRepository handles database operations for the outbox using pgxpool
+type PostgresOutboxRepository struct {
+	DB *pgxpool.Pool
+}
+
+// NewPostgresOutboxRepository creates a new PostgresOutboxRepository
+func NewPostgresOutboxRepository(db *pgxpool.Pool) *PostgresOutboxRepository {
+	return &PostgresOutboxRepository{DB: db}
+}
+
+// DeliverBatch implements OutboxRepository. Messages are claimed with a lease in a short
+// transaction, delivered with no transaction open, and their results recorded in a second
+// short transaction. A message whose lease runs out before its result is recorded (for
+// example because the process died) is claimed again, so delivery is at-least-once.
+func (r *PostgresOutboxRepository) DeliverBatch(ctx context.Context, limit int, lease time.Duration, deliver func([]Message) []Result) (int, error) {
+	msgs, err := r.claim(ctx, limit, lease)
+	if err != nil || len(msgs) == 0 {
+		return 0, err
+	}
+
+	results := deliver(msgs)
+
+	// Results are recorded even if ctx was cancelled during delivery, so finished work is not repeated
+	recordCtx := context.WithoutCancel(ctx)
+	tx, err := r.DB.Begin(recordCtx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(recordCtx)
+
+	for _, res := range results {
+		var err error
+		switch {
+		case res.Err == nil:
+			_, err = tx.Exec(recordCtx, `UPDATE recommendation_outbox SET delivered_at = NOW(), attempts = attempts + 1, last_error = NULL, locked_until = NULL WHERE id = $1`, res.ID)
+		case res.Dead:
+			_, err = tx.Exec(recordCtx, `UPDATE recommendation_outbox SET dead_at = NOW(), attempts = attempts + 1, last_error = $2, locked_until = NULL WHERE id = $1`, res.ID, res.Err.Error())
+		default:
+			_, err = tx.Exec(recordCtx, `UPDATE recommendation_outbox SET next_attempt_at = $2, attempts = attempts + 1, last_error = $3, locked_until = NULL WHERE id = $1`, res.ID, res.RetryAt, res.Err.Error())
+		}
+		if err != nil {
+			log.Printf("Error recording outbox result for message %d: %v", res.ID, err)
+			return 0, fmt.Errorf("failed to record outbox result: %w", err)
+		}
+	}
+
+	// Messages deliver did not report on (a user's queue stopped at a failure) are released
+	claimed := make([]int64, len(msgs))
+	for i, m := range msgs {
+		claimed[i] = m.ID
+	}
+	if _, err := tx.Exec(recordCtx, `UPDATE recommendation_outbox SET locked_until = NULL WHERE id = ANY($1) AND locked_until IS NOT NULL`, claimed); err != nil {
+		log.Printf("Error releasing outbox messages: %v", err)
+		return 0, fmt.Errorf("failed to release outbox messages: %w", err)
+	}
+
+	if err := tx.Commit(recordCtx); err != nil {
+		return 0, fmt.Errorf("failed to commit outbox results: %w", err)
+	}
+	return len(msgs), nil
+}
+
+// claim leases up to limit due messages. Claims are serialized with an advisory lock, and a
+// message is only claimed when no earlier message of the same user is leased or backed
+// off, so each user's actions are delivered in order even with several instances.
+func (r *PostgresOutboxRepository) claim(ctx context.Context, limit int, lease time.Duration) ([]Message, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, claimLockKey).Scan(&locked); err != nil {
+		return nil, fmt.Errorf("failed to take outbox lock: %w", err)
+	}
+	if !locked {
+		return nil, nil
+	}
+
+	rows, err := tx.Query(ctx, `
+		UPDATE recommendation_outbox
+		SET locked_until = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT o.id
+			FROM recommendation_outbox o
+			WHERE o.delivered_at IS NULL AND o.dead_at IS NULL
+			  AND o.next_attempt_at <= NOW()
+			  AND (o.locked_until IS NULL OR o.locked_until <= NOW())
+			  AND NOT EXISTS (
+				SELECT 1 FROM recommendation_outbox b
+				WHERE b.user_id = o.user_id AND b.id < o.id
+				  AND b.delivered_at IS NULL AND b.dead_at IS NULL
+				  AND (b.next_attempt_at > NOW() OR b.locked_until > NOW())
+			  )
+			ORDER BY o.id
+			LIMIT $1
+		)
+		RETURNING id, user_id, boardgame_id, action_type, action_value, action_time, attempts
+	`, limit, lease.Seconds())
+	if err != nil {
+		log.Printf("Error claiming outbox messages: %v", err)
+		return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+	msgs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Message, error) {
+		var m Message
+		err := row.Scan(&m.ID, &m.UserID, &m.BoardgameID, &m.ActionType, &m.ActionValue, &m.ActionTime, &m.Attempts)
+		return m, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan outbox messages: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit outbox claim: %w", err)
+	}
+	// UPDATE ... RETURNING does not keep the subquery's order
+	sort.Slice(msgs, func(i, j int) bool { return msgs[i].ID < msgs[j].ID })
+	return msgs, nil
+}
+
+// PurgeDelivered deletes messages delivered more than olderThan ago
+func (r *PostgresOutboxRepository) PurgeDelivered(ctx context.Context, olderThan time.Duration) (int64, error) {
+	tag, err := r.DB.Exec(ctx, `
+		DELETE FROM recommendation_outbox
+		WHERE delivered_at IS NOT NULL AND delivered_at < NOW() - make_interval(secs => $1)
+	`, olderThan.Seconds())
+	if err != nil {
+		log.Printf("Error purging delivered outbox messages: %v", err)
+		return 0, fmt.Errorf("failed to purge outbox: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
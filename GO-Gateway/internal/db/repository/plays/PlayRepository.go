@@ -0,0 +1,364 @@
+package plays
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"guru-game/internal/db/repository/outbox"
+	"guru-game/models"
+)
+
+var (
+	// ErrPlayNotFound is returned when no play matches the given ID
+	ErrPlayNotFound = errors.New("play not found")
+	// ErrBoardgameNotFound is returned when a play refers to a board game that does not exist
+	ErrBoardgameNotFound = errors.New("board game not found")
+	// ErrUnknownPlayer is returned when a participant refers to a user that does not exist
+	ErrUnknownPlayer = errors.New("participant is not a registered user")
+	// ErrNotFriend is returned when a participant is a registered user who is not a friend of the logger
+	ErrNotFriend = errors.New("registered participants must be friends of the user logging the play")
+)
+
+// PlayQuery filters and pages through a user's plays, newest first. Plays logged by the
+// user and plays the user took part in are both included. BeforeID 0 starts from the latest.
+type PlayQuery struct {
+	UserID      int64
+	BoardgameID int
+	BeforeID    int64
+	Limit       int
+}
+
+// PlayRepository defines the interface for play database operations
+type PlayRepository interface {
+	Create(ctx context.Context, p *models.Play) error
+	GetByID(ctx context.Context, id int64) (*models.Play, error)
+	Update(ctx context.Context, p *models.Play) error
+	Delete(ctx context.Context, id int64) error
+	List(ctx context.Context, q PlayQuery) ([]models.Play, error)
+	GetGameStats(ctx context.Context, userID int64) ([]models.GamePlayStats, error)
+}
+
+// PostgresPlayRepository handles database operations for plays using pgxpool
+type PostgresPlayRepository struct {
+	DB *pgxpool.Pool
+}
+
+// NewPostgresPlayRepository creates a new PostgresPlayRepository
+func NewPostgresPlayRepository(db *pgxpool.Pool) *PostgresPlayRepository {
+	return &PostgresPlayRepository{DB: db}
+}
+
+const playColumns = `p.id, p.user_id, p.boardgame_id, b.title, p.played_at, p.duration_minutes, p.location, p.notes, p.created_at, p.updated_at`
+
+func scanPlay(row pgx.Row) (*models.Play, error) {
+	var p models.Play
+	err := row.Scan(&p.ID, &p.UserID, &p.BoardgameID, &p.GameTitle, &p.PlayedAt, &p.DurationMinutes, &p.Location, &p.Notes, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPlayNotFound
+		}
+		return nil, err
+	}
+	p.Players = []models.PlayPlayer{}
+	return &p, nil
+}
+
+// Create inserts a play with its participants. Registered participants other than the
+// logger must be friends of the logger. Only the logger's own play is queued for the
+// recommendation service, in the same transaction and only when the logger is listed as
+// a participant; participants never have actions forwarded on their behalf.
+func (r *PostgresPlayRepository) Create(ctx context.Context, p *models.Play) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO plays (user_id, boardgame_id, played_at, duration_minutes, location, notes)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`, p.UserID, p.BoardgameID, p.PlayedAt, p.DurationMinutes, p.Location, p.Notes).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		log.Printf("Error creating play for user %d: %v", p.UserID, err)
+		return playWriteError(err)
+	}
+	if err := checkParticipants(ctx, tx, p); err != nil {
+		return err
+	}
+	if err := insertPlayers(ctx, tx, p); err != nil {
+		return err
+	}
+
+	var forward []outbox.Message
+	for _, pl := range p.Players {
+		if pl.UserID != nil && *pl.UserID == p.UserID {
+			forward = append(forward, outbox.Message{
+				UserID:      p.UserID,
+				BoardgameID: p.BoardgameID,
+				ActionType:  outbox.ActionPlay,
+				ActionValue: 1,
+				ActionTime:  p.PlayedAt,
+			})
+		}
+	}
+	if err := outbox.Enqueue(ctx, tx, forward...); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit play: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a play with its participants
+func (r *PostgresPlayRepository) GetByID(ctx context.Context, id int64) (*models.Play, error) {
+	p, err := scanPlay(r.DB.QueryRow(ctx, `
+		SELECT `+playColumns+`
+		FROM plays p
+		JOIN boardgames b ON b.id = p.boardgame_id
+		WHERE p.id = $1
+	`, id))
+	if err != nil {
+		if !errors.Is(err, ErrPlayNotFound) {
+			log.Printf("Error fetching play %d: %v", id, err)
+		}
+		return nil, err
+	}
+
+	list := []models.Play{*p}
+	if err := r.attachPlayers(ctx, list); err != nil {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+// Update replaces a play's details and participants. Newly listed registered participants
+// must be friends of the logger; those already on the play stay allowed. Like Create, edits
+// forward nothing on behalf of participants, and the logger's play was already queued when
+// it was logged.
+func (r *PostgresPlayRepository) Update(ctx context.Context, p *models.Play) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		UPDATE plays
+		SET boardgame_id = $2, played_at = $3, duration_minutes = $4, location = $5, notes = $6, updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`, p.ID, p.BoardgameID, p.PlayedAt, p.DurationMinutes, p.Location, p.Notes).Scan(&p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrPlayNotFound
+		}
+		log.Printf("Error updating play %d: %v", p.ID, err)
+		return playWriteError(err)
+	}
+
+	if err := checkParticipants(ctx, tx, p); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM play_players WHERE play_id = $1`, p.ID); err != nil {
+		return fmt.Errorf("failed to replace participants: %w", err)
+	}
+	if err := insertPlayers(ctx, tx, p); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit play: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a play and its participants
+func (r *PostgresPlayRepository) Delete(ctx context.Context, id int64) error {
+	tag, err := r.DB.Exec(ctx, `DELETE FROM plays WHERE id = $1`, id)
+	if err != nil {
+		log.Printf("Error deleting play %d: %v", id, err)
+		return fmt.Errorf("failed to delete play: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrPlayNotFound
+	}
+	return nil
+}
+
+// List returns the plays matching q with their participants
+func (r *PostgresPlayRepository) List(ctx context.Context, q PlayQuery) ([]models.Play, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+playColumns+`
+		FROM plays p
+		JOIN boardgames b ON b.id = p.boardgame_id
+		WHERE (p.user_id = $1 OR EXISTS (
+			SELECT 1 FROM play_players pp WHERE pp.play_id = p.id AND pp.user_id = $1
+		))
+		  AND ($2 = 0 OR p.boardgame_id = $2)
+		  AND ($3 = 0 OR p.id < $3)
+		ORDER BY p.id DESC
+		LIMIT $4
+	`, q.UserID, q.BoardgameID, q.BeforeID, q.Limit)
+	if err != nil {
+		log.Printf("Error listing plays for user %d: %v", q.UserID, err)
+		return nil, fmt.Errorf("failed to list plays: %w", err)
+	}
+
+	list, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Play, error) {
+		p, err := scanPlay(row)
+		if err != nil {
+			return models.Play{}, err
+		}
+		return *p, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan plays: %w", err)
+	}
+
+	if err := r.attachPlayers(ctx, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetGameStats returns per-game play totals for a user, most played first. Only plays the
+// user took part in as a listed participant count; plays they logged for others do not.
+func (r *PostgresPlayRepository) GetGameStats(ctx context.Context, userID int64) ([]models.GamePlayStats, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT p.boardgame_id, b.title,
+		       COUNT(*)::int,
+		       COUNT(*) FILTER (WHERE pp.winner)::int,
+		       SUM(COALESCE(p.duration_minutes, 0))::int,
+		       MAX(p.played_at)
+		FROM plays p
+		JOIN play_players pp ON pp.play_id = p.id AND pp.user_id = $1
+		JOIN boardgames b ON b.id = p.boardgame_id
+		GROUP BY p.boardgame_id, b.title
+		ORDER BY COUNT(*) DESC, b.title
+	`, userID)
+	if err != nil {
+		log.Printf("Error fetching play stats for user %d: %v", userID, err)
+		return nil, fmt.Errorf("failed to fetch play stats: %w", err)
+	}
+
+	stats, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.GamePlayStats, error) {
+		var s models.GamePlayStats
+		err := row.Scan(&s.BoardgameID, &s.Title, &s.Plays, &s.Wins, &s.TotalMinutes, &s.LastPlayedAt)
+		return s, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan play stats: %w", err)
+	}
+	return stats, nil
+}
+
+// checkParticipants returns ErrNotFriend when a registered participant other than the
+// logger is neither a mutual accepted follow of the logger nor already on the play. It
+// must run before the play's current participants are replaced.
+func checkParticipants(ctx context.Context, tx pgx.Tx, p *models.Play) error {
+	var ids []int64
+	for _, pl := range p.Players {
+		if pl.UserID != nil && *pl.UserID != p.UserID {
+			ids = append(ids, *pl.UserID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var strangers int
+	err := tx.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM unnest($3::bigint[]) AS u(id)
+		WHERE NOT EXISTS (
+			SELECT 1 FROM play_players pp WHERE pp.play_id = $2 AND pp.user_id = u.id
+		) AND NOT EXISTS (
+			SELECT 1
+			FROM user_follows f
+			JOIN user_follows b ON b.follower_id = f.followee_id AND b.followee_id = f.follower_id AND b.status = 'accepted'
+			WHERE f.follower_id = $1 AND f.followee_id = u.id AND f.status = 'accepted'
+		)
+	`, p.UserID, p.ID, ids).Scan(&strangers)
+	if err != nil {
+		log.Printf("Error checking participants of play %d: %v", p.ID, err)
+		return fmt.Errorf("failed to check participants: %w", err)
+	}
+	if strangers > 0 {
+		return ErrNotFriend
+	}
+	return nil
+}
+
+func insertPlayers(ctx context.Context, tx pgx.Tx, p *models.Play) error {
+	for i, pl := range p.Players {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO play_players (play_id, position, user_id, guest_name, score, winner)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, p.ID, i+1, pl.UserID, pl.GuestName, pl.Score, pl.Winner)
+		if err != nil {
+			log.Printf("Error adding participant to play %d: %v", p.ID, err)
+			return playWriteError(err)
+		}
+	}
+	return nil
+}
+
+// attachPlayers loads the participants of plays in one query
+func (r *PostgresPlayRepository) attachPlayers(ctx context.Context, list []models.Play) error {
+	if len(list) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(list))
+	index := make(map[int64]int, len(list))
+	for i, p := range list {
+		ids[i] = p.ID
+		index[p.ID] = i
+	}
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT pp.play_id, pp.user_id, COALESCE(u.username, ''), pp.guest_name, pp.score, pp.winner
+		FROM play_players pp
+		LEFT JOIN users u ON u.id = pp.user_id
+		WHERE pp.play_id = ANY($1)
+		ORDER BY pp.play_id, pp.position
+	`, ids)
+	if err != nil {
+		log.Printf("Error fetching play participants: %v", err)
+		return fmt.Errorf("failed to fetch participants: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var playID int64
+		var pl models.PlayPlayer
+		if err := rows.Scan(&playID, &pl.UserID, &pl.Username, &pl.GuestName, &pl.Score, &pl.Winner); err != nil {
+			return fmt.Errorf("failed to scan participant: %w", err)
+		}
+		i := index[playID]
+		list[i].Players = append(list[i].Players, pl)
+	}
+	return rows.Err()
+}
+
+// playWriteError maps foreign key violations to the matching sentinel error
+func playWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		switch pgErr.ConstraintName {
+		case "plays_boardgame_id_fkey":
+			return ErrBoardgameNotFound
+		case "play_players_user_id_fkey":
+			return ErrUnknownPlayer
+		}
+	}
+	return fmt.Errorf("failed to save play: %w", err)
+}
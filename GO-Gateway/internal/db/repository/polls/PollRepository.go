@@ -0,0 +1,319 @@
+package polls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"guru-game/models"
+)
+
+// ErrBoardgameNotFound is returned when a vote refers to a board game that does not exist
+var ErrBoardgameNotFound = errors.New("board game not found")
+
+// PollRepository defines the interface for weight and player count poll operations
+type PollRepository interface {
+	SetWeight(ctx context.Context, userID int64, boardgameID int, weight *int) error
+	SetPlayerCountVotes(ctx context.Context, userID int64, boardgameID int, votes []models.PlayerCountVote) error
+	GetUserVotes(ctx context.Context, userID int64, boardgameID int) (*models.UserPollVotes, error)
+	GetResults(ctx context.Context, boardgameID int) (*models.GamePollResults, error)
+	GetStats(ctx context.Context, boardgameIDs []int) (map[int]models.GamePollStats, error)
+}
+
+// PostgresPollRepository handles database operations for polls using pgxpool
+type PostgresPollRepository struct {
+	DB *pgxpool.Pool
+}
+
+// NewPostgresPollRepository creates a new PostgresPollRepository
+func NewPostgresPollRepository(db *pgxpool.Pool) *PostgresPollRepository {
+	return &PostgresPollRepository{DB: db}
+}
+
+// SetWeight records the user's weight vote for a game; nil removes it
+func (r *PostgresPollRepository) SetWeight(ctx context.Context, userID int64, boardgameID int, weight *int) error {
+	return r.inTx(ctx, boardgameID, func(tx pgx.Tx) error {
+		var err error
+		if weight == nil {
+			_, err = tx.Exec(ctx, `DELETE FROM game_weight_votes WHERE user_id = $1 AND boardgame_id = $2`, userID, boardgameID)
+		} else {
+			_, err = tx.Exec(ctx, `
+				INSERT INTO game_weight_votes (user_id, boardgame_id, weight)
+				VALUES ($1, $2, $3)
+				ON CONFLICT (user_id, boardgame_id) DO UPDATE SET weight = EXCLUDED.weight, updated_at = NOW()
+			`, userID, boardgameID, *weight)
+		}
+		if err != nil {
+			log.Printf("Error saving weight vote of user %d for boardgame %d: %v", userID, boardgameID, err)
+			return voteWriteError(err)
+		}
+		return nil
+	})
+}
+
+// SetPlayerCountVotes replaces the user's player count votes for a game
+func (r *PostgresPollRepository) SetPlayerCountVotes(ctx context.Context, userID int64, boardgameID int, votes []models.PlayerCountVote) error {
+	return r.inTx(ctx, boardgameID, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `
+			DELETE FROM game_player_count_votes WHERE user_id = $1 AND boardgame_id = $2
+		`, userID, boardgameID); err != nil {
+			log.Printf("Error clearing player count votes of user %d for boardgame %d: %v", userID, boardgameID, err)
+			return fmt.Errorf("failed to replace player count votes: %w", err)
+		}
+		for _, v := range votes {
+			if _, err := tx.Exec(ctx, `
+				INSERT INTO game_player_count_votes (user_id, boardgame_id, player_count, vote)
+				VALUES ($1, $2, $3, $4)
+			`, userID, boardgameID, v.PlayerCount, v.Vote); err != nil {
+				log.Printf("Error saving player count vote of user %d for boardgame %d: %v", userID, boardgameID, err)
+				return voteWriteError(err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetUserVotes returns the user's votes for a game
+func (r *PostgresPollRepository) GetUserVotes(ctx context.Context, userID int64, boardgameID int) (*models.UserPollVotes, error) {
+	votes := &models.UserPollVotes{PlayerCounts: []models.PlayerCountVote{}}
+
+	var weight int
+	err := r.DB.QueryRow(ctx, `
+		SELECT weight FROM game_weight_votes WHERE user_id = $1 AND boardgame_id = $2
+	`, userID, boardgameID).Scan(&weight)
+	switch {
+	case err == nil:
+		votes.Weight = &weight
+	case !errors.Is(err, pgx.ErrNoRows):
+		log.Printf("Error fetching weight vote of user %d for boardgame %d: %v", userID, boardgameID, err)
+		return nil, fmt.Errorf("failed to fetch weight vote: %w", err)
+	}
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT player_count, vote FROM game_player_count_votes
+		WHERE user_id = $1 AND boardgame_id = $2
+		ORDER BY player_count
+	`, userID, boardgameID)
+	if err != nil {
+		log.Printf("Error fetching player count votes of user %d for boardgame %d: %v", userID, boardgameID, err)
+		return nil, fmt.Errorf("failed to fetch player count votes: %w", err)
+	}
+	counts, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.PlayerCountVote, error) {
+		var v models.PlayerCountVote
+		err := row.Scan(&v.PlayerCount, &v.Vote)
+		return v, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan player count votes: %w", err)
+	}
+	votes.PlayerCounts = counts
+	return votes, nil
+}
+
+// GetResults tallies the weight and player count polls of a game
+func (r *PostgresPollRepository) GetResults(ctx context.Context, boardgameID int) (*models.GamePollResults, error) {
+	results, err := tallyResults(ctx, r.DB, boardgameID)
+	if err != nil {
+		log.Printf("Error fetching poll results for boardgame %d: %v", boardgameID, err)
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetStats returns the stored poll summaries of the given games, or of every game with
+// votes when boardgameIDs is nil. Games without votes are left out of the map.
+func (r *PostgresPollRepository) GetStats(ctx context.Context, boardgameIDs []int) (map[int]models.GamePollStats, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT boardgame_id, weight_avg, best_player_counts, recommended_player_counts
+		FROM game_poll_stats
+		WHERE $1::int[] IS NULL OR boardgame_id = ANY($1)
+	`, boardgameIDs)
+	if err != nil {
+		log.Printf("Error fetching poll stats: %v", err)
+		return nil, fmt.Errorf("failed to fetch poll stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make(map[int]models.GamePollStats)
+	for rows.Next() {
+		var s models.GamePollStats
+		if err := rows.Scan(&s.BoardgameID, &s.WeightAvg, &s.BestPlayerCounts, &s.RecommendedPlayerCounts); err != nil {
+			return nil, fmt.Errorf("failed to scan poll stats: %w", err)
+		}
+		stats[s.BoardgameID] = s
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read poll stats: %w", err)
+	}
+	return stats, nil
+}
+
+// inTx runs fn and then refreshes the game's game_poll_stats row in the same transaction.
+// The stats row is locked first so concurrent votes on a game are tallied one at a time.
+func (r *PostgresPollRepository) inTx(ctx context.Context, boardgameID int, fn func(pgx.Tx) error) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO game_poll_stats (boardgame_id) VALUES ($1)
+		ON CONFLICT (boardgame_id) DO UPDATE SET updated_at = NOW()
+	`, boardgameID); err != nil {
+		if isBoardgameFK(err) {
+			return ErrBoardgameNotFound
+		}
+		log.Printf("Error locking poll stats for boardgame %d: %v", boardgameID, err)
+		return fmt.Errorf("failed to lock poll stats: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	results, err := tallyResults(ctx, tx, boardgameID)
+	if err != nil {
+		log.Printf("Error tallying polls for boardgame %d: %v", boardgameID, err)
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE game_poll_stats
+		SET weight_avg = $2, weight_votes = $3, player_count_voters = $4,
+		    best_player_counts = $5, recommended_player_counts = $6, updated_at = NOW()
+		WHERE boardgame_id = $1
+	`, boardgameID, results.WeightAvg, results.WeightVotes, results.PlayerCountVoters,
+		results.BestPlayerCounts, results.RecommendedPlayerCounts); err != nil {
+		log.Printf("Error saving poll stats for boardgame %d: %v", boardgameID, err)
+		return fmt.Errorf("failed to save poll stats: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit vote: %w", err)
+	}
+	return nil
+}
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func tallyResults(ctx context.Context, q querier, boardgameID int) (*models.GamePollResults, error) {
+	results := &models.GamePollResults{
+		BoardgameID:             boardgameID,
+		WeightDistribution:      make(map[int]int),
+		PlayerCounts:            []models.PlayerCountResult{},
+		BestPlayerCounts:        []int{},
+		RecommendedPlayerCounts: []int{},
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT weight, COUNT(*)::int FROM game_weight_votes WHERE boardgame_id = $1 GROUP BY weight
+	`, boardgameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to tally weight votes: %w", err)
+	}
+	var total int
+	for rows.Next() {
+		var weight, count int
+		if err := rows.Scan(&weight, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan weight votes: %w", err)
+		}
+		results.WeightDistribution[weight] = count
+		results.WeightVotes += count
+		total += weight * count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read weight votes: %w", err)
+	}
+	if results.WeightVotes > 0 {
+		avg := math.Round(float64(total)/float64(results.WeightVotes)*100) / 100
+		results.WeightAvg = &avg
+	}
+
+	rows, err = q.Query(ctx, `
+		SELECT player_count,
+		       COUNT(*) FILTER (WHERE vote = 'best')::int,
+		       COUNT(*) FILTER (WHERE vote = 'recommended')::int,
+		       COUNT(*) FILTER (WHERE vote = 'not_recommended')::int
+		FROM game_player_count_votes
+		WHERE boardgame_id = $1
+		GROUP BY player_count
+		ORDER BY player_count
+	`, boardgameID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to tally player count votes: %w", err)
+	}
+	counts, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.PlayerCountResult, error) {
+		var pc models.PlayerCountResult
+		err := row.Scan(&pc.PlayerCount, &pc.Best, &pc.Recommended, &pc.NotRecommended)
+		return pc, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan player count votes: %w", err)
+	}
+	for i := range counts {
+		counts[i].Verdict = Verdict(counts[i])
+		switch counts[i].Verdict {
+		case models.PlayerCountBest:
+			results.BestPlayerCounts = append(results.BestPlayerCounts, counts[i].PlayerCount)
+			results.RecommendedPlayerCounts = append(results.RecommendedPlayerCounts, counts[i].PlayerCount)
+		case models.PlayerCountRecommended:
+			results.RecommendedPlayerCounts = append(results.RecommendedPlayerCounts, counts[i].PlayerCount)
+		}
+	}
+	results.PlayerCounts = counts
+	sort.Ints(results.BestPlayerCounts)
+	sort.Ints(results.RecommendedPlayerCounts)
+
+	err = q.QueryRow(ctx, `
+		SELECT COUNT(DISTINCT user_id)::int FROM game_player_count_votes WHERE boardgame_id = $1
+	`, boardgameID).Scan(&results.PlayerCountVoters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count player count voters: %w", err)
+	}
+	return results, nil
+}
+
+// Verdict is the community answer for one player count. A count is recommended when
+// best and recommended votes together outnumber not recommended votes, and best when
+// it is recommended and best is also the most common answer.
+func Verdict(pc models.PlayerCountResult) string {
+	if pc.Best+pc.Recommended <= pc.NotRecommended {
+		return models.PlayerCountNotRecommended
+	}
+	if pc.Best >= pc.Recommended && pc.Best > pc.NotRecommended {
+		return models.PlayerCountBest
+	}
+	return models.PlayerCountRecommended
+}
+
+// voteWriteError maps a missing board game to ErrBoardgameNotFound
+func voteWriteError(err error) error {
+	if isBoardgameFK(err) {
+		return ErrBoardgameNotFound
+	}
+	return fmt.Errorf("failed to save vote: %w", err)
+}
+
+func isBoardgameFK(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != "23503" {
+		return false
+	}
+	switch pgErr.ConstraintName {
+	case "game_poll_stats_boardgame_id_fkey", "game_weight_votes_boardgame_id_fkey", "game_player_count_votes_boardgame_id_fkey":
+		return true
+	}
+	return false
+}
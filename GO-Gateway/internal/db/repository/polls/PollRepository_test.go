@@ -0,0 +1,31 @@
+package polls
+
+import (
+	"testing"
+
+	"guru-game/models"
+)
+
+func TestVerdict(t *testing.T) {
+	tests := []struct {
+		name                              string
+		best, recommended, notRecommended int
+		want                              string
+	}{
+		{"no votes", 0, 0, 0, models.PlayerCountNotRecommended},
+		{"only not recommended", 0, 0, 3, models.PlayerCountNotRecommended},
+		{"tie with not recommended", 1, 1, 2, models.PlayerCountNotRecommended},
+		{"only best", 2, 0, 0, models.PlayerCountBest},
+		{"best ties recommended", 3, 3, 1, models.PlayerCountBest},
+		{"recommended leads", 2, 4, 1, models.PlayerCountRecommended},
+		{"best not above not recommended", 2, 1, 2, models.PlayerCountRecommended},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pc := models.PlayerCountResult{Best: tt.best, Recommended: tt.recommended, NotRecommended: tt.notRecommended}
+			if got := Verdict(pc); got != tt.want {
+				t.Errorf("Verdict(%+v) = %q, want %q", pc, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,468 @@
+package reviews
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"guru-game/models"
+)
+
+var (
+	// ErrReviewNotFound is returned when no review matches
+	ErrReviewNotFound = errors.New("review not found")
+	// ErrNotRated is returned when a user reviews a game they have not rated
+	ErrNotRated = errors.New("rate the game before writing a review")
+	// ErrAlreadyReported is returned when a user reports the same review twice
+	ErrAlreadyReported = errors.New("you already reported this review")
+)
+
+// Sort orders for ListForGame
+const (
+	SortHelpful    = "helpful"
+	SortNewest     = "newest"
+	SortOldest     = "oldest"
+	SortRatingHigh = "rating_high"
+	SortRatingLow  = "rating_low"
+)
+
+var sortClauses = map[string]string{
+	SortHelpful:    "r.helpful_count DESC, r.created_at DESC",
+	SortNewest:     "r.created_at DESC",
+	SortOldest:     "r.created_at ASC",
+	SortRatingHigh: "s.rating DESC, r.helpful_count DESC",
+	SortRatingLow:  "s.rating ASC, r.helpful_count DESC",
+}
+
+// ValidSort reports whether sort is one of the supported orders
+func ValidSort(sort string) bool {
+	_, ok := sortClauses[sort]
+	return ok
+}
+
+// ReviewQuery pages through the visible reviews of a game
+type ReviewQuery struct {
+	BoardgameID int
+	Sort        string
+	Limit       int
+	Offset      int
+}
+
+// ReviewRepository defines the interface for review database operations
+type ReviewRepository interface {
+	Upsert(ctx context.Context, userID int64, boardgameID int, body string) (*models.Review, error)
+	Delete(ctx context.Context, userID int64, boardgameID int) error
+	GetByID(ctx context.Context, id int64) (*models.Review, error)
+	GetByUser(ctx context.Context, userID int64, boardgameID int) (*models.Review, error)
+	ListForGame(ctx context.Context, q ReviewQuery) ([]models.Review, error)
+	History(ctx context.Context, reviewID int64) ([]models.ReviewRevision, error)
+	SetVote(ctx context.Context, reviewID, userID int64, helpful bool) (int, error)
+	Report(ctx context.Context, reviewID, userID int64, reason string) error
+	ModerationQueue(ctx context.Context, limit, offset int) ([]models.ModerationItem, error)
+	Moderate(ctx context.Context, reviewID, moderatorID int64, status, reason string) (*models.Review, error)
+	Summary(ctx context.Context, boardgameID int) (*models.ReviewSummary, error)
+}
+
+// PostgresReviewRepository handles database operations for reviews using pgxpool
+type PostgresReviewRepository struct {
+	DB *pgxpool.Pool
+}
+
+// NewPostgresReviewRepository creates a new PostgresReviewRepository
+func NewPostgresReviewRepository(db *pgxpool.Pool) *PostgresReviewRepository {
+	return &PostgresReviewRepository{DB: db}
+}
+
+const reviewColumns = `r.id, r.user_id, COALESCE(u.username, ''), r.boardgame_id, s.rating, r.body, r.status,
+	r.hidden_reason, r.moderated_at, r.helpful_count, r.edit_count, r.created_at, r.updated_at`
+
+const reviewFrom = `
+	FROM reviews r
+	JOIN user_states s ON s.user_id = r.user_id AND s.boardgame_id = r.boardgame_id
+	LEFT JOIN users u ON u.id = r.user_id`
+
+func scanReview(row pgx.Row) (*models.Review, error) {
+	var rv models.Review
+	err := row.Scan(&rv.ID, &rv.UserID, &rv.Username, &rv.BoardgameID, &rv.Rating, &rv.Body, &rv.Status,
+		&rv.HiddenReason, &rv.ModeratedAt, &rv.HelpfulCount, &rv.EditCount, &rv.CreatedAt, &rv.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrReviewNotFound
+		}
+		return nil, err
+	}
+	return &rv, nil
+}
+
+func collectReviews(rows pgx.Rows) ([]models.Review, error) {
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Review, error) {
+		rv, err := scanReview(row)
+		if err != nil {
+			return models.Review{}, err
+		}
+		return *rv, nil
+	})
+}
+
+// Upsert writes the user's review of a game. Editing an existing review keeps the
+// previous body in review_revisions; a review can only be written for a rated game.
+// A rating cleared later does not delete the review, it only hides it from listings
+// until the game is rated again.
+func (r *PostgresReviewRepository) Upsert(ctx context.Context, userID int64, boardgameID int, body string) (*models.Review, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// FOR SHARE keeps the rating from being cleared until this transaction commits; after
+	// that, listings hide reviews whose rating was removed
+	var rating float64
+	err = tx.QueryRow(ctx, `
+		SELECT rating FROM user_states WHERE user_id = $1 AND boardgame_id = $2 FOR SHARE
+	`, userID, boardgameID).Scan(&rating)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && rating <= 0) {
+		return nil, ErrNotRated
+	}
+	if err != nil {
+		log.Printf("Error checking rating of user %d for boardgame %d: %v", userID, boardgameID, err)
+		return nil, fmt.Errorf("failed to check rating: %w", err)
+	}
+
+	var id int64
+	var oldBody string
+	var writtenAt time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT id, body, updated_at FROM reviews WHERE user_id = $1 AND boardgame_id = $2 FOR UPDATE
+	`, userID, boardgameID).Scan(&id, &oldBody, &writtenAt)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		err = tx.QueryRow(ctx, `
+			INSERT INTO reviews (user_id, boardgame_id, body) VALUES ($1, $2, $3) RETURNING id
+		`, userID, boardgameID, body).Scan(&id)
+		if err != nil {
+			log.Printf("Error creating review for user %d, boardgame %d: %v", userID, boardgameID, err)
+			return nil, fmt.Errorf("failed to create review: %w", err)
+		}
+	case err != nil:
+		log.Printf("Error fetching review of user %d for boardgame %d: %v", userID, boardgameID, err)
+		return nil, fmt.Errorf("failed to fetch review: %w", err)
+	case oldBody != body:
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO review_revisions (review_id, body, written_at) VALUES ($1, $2, $3)
+		`, id, oldBody, writtenAt); err != nil {
+			log.Printf("Error saving revision of review %d: %v", id, err)
+			return nil, fmt.Errorf("failed to save review revision: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `
+			UPDATE reviews SET body = $2, edit_count = edit_count + 1, updated_at = NOW() WHERE id = $1
+		`, id, body); err != nil {
+			log.Printf("Error updating review %d: %v", id, err)
+			return nil, fmt.Errorf("failed to update review: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit review: %w", err)
+	}
+	return r.GetByID(ctx, id)
+}
+
+// Delete removes the user's review of a game with its history, votes and reports
+func (r *PostgresReviewRepository) Delete(ctx context.Context, userID int64, boardgameID int) error {
+	tag, err := r.DB.Exec(ctx, `DELETE FROM reviews WHERE user_id = $1 AND boardgame_id = $2`, userID, boardgameID)
+	if err != nil {
+		log.Printf("Error deleting review of user %d for boardgame %d: %v", userID, boardgameID, err)
+		return fmt.Errorf("failed to delete review: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrReviewNotFound
+	}
+	return nil
+}
+
+// GetByID returns a review in any status
+func (r *PostgresReviewRepository) GetByID(ctx context.Context, id int64) (*models.Review, error) {
+	rv, err := scanReview(r.DB.QueryRow(ctx, `SELECT `+reviewColumns+reviewFrom+` WHERE r.id = $1`, id))
+	if err != nil && !errors.Is(err, ErrReviewNotFound) {
+		log.Printf("Error fetching review %d: %v", id, err)
+		return nil, fmt.Errorf("failed to fetch review: %w", err)
+	}
+	return rv, err
+}
+
+// GetByUser returns the user's review of a game in any status
+func (r *PostgresReviewRepository) GetByUser(ctx context.Context, userID int64, boardgameID int) (*models.Review, error) {
+	rv, err := scanReview(r.DB.QueryRow(ctx, `
+		SELECT `+reviewColumns+reviewFrom+` WHERE r.user_id = $1 AND r.boardgame_id = $2
+	`, userID, boardgameID))
+	if err != nil && !errors.Is(err, ErrReviewNotFound) {
+		log.Printf("Error fetching review of user %d for boardgame %d: %v", userID, boardgameID, err)
+		return nil, fmt.Errorf("failed to fetch review: %w", err)
+	}
+	return rv, err
+}
+
+// ListForGame returns a page of visible reviews of a game whose authors still rate it
+func (r *PostgresReviewRepository) ListForGame(ctx context.Context, q ReviewQuery) ([]models.Review, error) {
+	order, ok := sortClauses[q.Sort]
+	if !ok {
+		order = sortClauses[SortHelpful]
+	}
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+reviewColumns+reviewFrom+`
+		WHERE r.boardgame_id = $1 AND r.status = 'visible' AND s.rating > 0
+		ORDER BY `+order+`, r.id DESC
+		LIMIT $2 OFFSET $3
+	`, q.BoardgameID, q.Limit, q.Offset)
+	if err != nil {
+		log.Printf("Error listing reviews for boardgame %d: %v", q.BoardgameID, err)
+		return nil, fmt.Errorf("failed to list reviews: %w", err)
+	}
+	list, err := collectReviews(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan reviews: %w", err)
+	}
+	return list, nil
+}
+
+// History returns the earlier bodies of a review, newest first
+func (r *PostgresReviewRepository) History(ctx context.Context, reviewID int64) ([]models.ReviewRevision, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT body, written_at, replaced_at FROM review_revisions WHERE review_id = $1 ORDER BY id DESC
+	`, reviewID)
+	if err != nil {
+		log.Printf("Error fetching history of review %d: %v", reviewID, err)
+		return nil, fmt.Errorf("failed to fetch review history: %w", err)
+	}
+	revisions, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.ReviewRevision, error) {
+		var rev models.ReviewRevision
+		err := row.Scan(&rev.Body, &rev.WrittenAt, &rev.ReplacedAt)
+		return rev, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan review history: %w", err)
+	}
+	return revisions, nil
+}
+
+// SetVote adds or removes the user's helpful vote and returns the new helpful count.
+// Voting twice, or removing a vote that was never cast, leaves the count unchanged.
+func (r *PostgresReviewRepository) SetVote(ctx context.Context, reviewID, userID int64, helpful bool) (int, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var tag pgconn.CommandTag
+	if helpful {
+		tag, err = tx.Exec(ctx, `
+			INSERT INTO review_votes (review_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING
+		`, reviewID, userID)
+	} else {
+		tag, err = tx.Exec(ctx, `DELETE FROM review_votes WHERE review_id = $1 AND user_id = $2`, reviewID, userID)
+	}
+	if err != nil {
+		if isForeignKeyViolation(err, "review_votes_review_id_fkey") {
+			return 0, ErrReviewNotFound
+		}
+		log.Printf("Error saving vote of user %d on review %d: %v", userID, reviewID, err)
+		return 0, fmt.Errorf("failed to save vote: %w", err)
+	}
+
+	delta := 0
+	if tag.RowsAffected() > 0 {
+		delta = 1
+		if !helpful {
+			delta = -1
+		}
+	}
+	var count int
+	err = tx.QueryRow(ctx, `
+		UPDATE reviews SET helpful_count = helpful_count + $2 WHERE id = $1 RETURNING helpful_count
+	`, reviewID, delta).Scan(&count)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrReviewNotFound
+		}
+		log.Printf("Error updating helpful count of review %d: %v", reviewID, err)
+		return 0, fmt.Errorf("failed to update helpful count: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit vote: %w", err)
+	}
+	return count, nil
+}
+
+// Report records a user's report of a review for the moderation queue
+func (r *PostgresReviewRepository) Report(ctx context.Context, reviewID, userID int64, reason string) error {
+	_, err := r.DB.Exec(ctx, `
+		INSERT INTO review_reports (review_id, user_id, reason) VALUES ($1, $2, $3)
+	`, reviewID, userID, reason)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.As(err, &pgErr) && pgErr.Code == "23505":
+			return ErrAlreadyReported
+		case isForeignKeyViolation(err, "review_reports_review_id_fkey"):
+			return ErrReviewNotFound
+		}
+		log.Printf("Error reporting review %d by user %d: %v", reviewID, userID, err)
+		return fmt.Errorf("failed to report review: %w", err)
+	}
+	return nil
+}
+
+// ModerationQueue returns reviews with open reports, most reported first
+func (r *PostgresReviewRepository) ModerationQueue(ctx context.Context, limit, offset int) ([]models.ModerationItem, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+reviewColumns+reviewFrom+`
+		JOIN (
+			SELECT review_id, COUNT(*) AS reports, MIN(created_at) AS first_reported
+			FROM review_reports
+			WHERE resolved_at IS NULL
+			GROUP BY review_id
+		) o ON o.review_id = r.id
+		ORDER BY o.reports DESC, o.first_reported ASC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		log.Printf("Error fetching review moderation queue: %v", err)
+		return nil, fmt.Errorf("failed to fetch moderation queue: %w", err)
+	}
+	list, err := collectReviews(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan moderation queue: %w", err)
+	}
+	if len(list) == 0 {
+		return []models.ModerationItem{}, nil
+	}
+
+	ids := make([]int64, len(list))
+	items := make([]models.ModerationItem, len(list))
+	index := make(map[int64]int, len(list))
+	for i, rv := range list {
+		ids[i] = rv.ID
+		items[i] = models.ModerationItem{Review: rv, Reports: []models.ReviewReport{}}
+		index[rv.ID] = i
+	}
+
+	reportRows, err := r.DB.Query(ctx, `
+		SELECT review_id, user_id, reason, created_at
+		FROM review_reports
+		WHERE review_id = ANY($1) AND resolved_at IS NULL
+		ORDER BY review_id, created_at
+	`, ids)
+	if err != nil {
+		log.Printf("Error fetching review reports: %v", err)
+		return nil, fmt.Errorf("failed to fetch review reports: %w", err)
+	}
+	defer reportRows.Close()
+
+	for reportRows.Next() {
+		var reviewID int64
+		var rep models.ReviewReport
+		if err := reportRows.Scan(&reviewID, &rep.UserID, &rep.Reason, &rep.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan review report: %w", err)
+		}
+		i := index[reviewID]
+		items[i].Reports = append(items[i].Reports, rep)
+	}
+	if err := reportRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read review reports: %w", err)
+	}
+	return items, nil
+}
+
+// Moderate sets a review's status and resolves its open reports
+func (r *PostgresReviewRepository) Moderate(ctx context.Context, reviewID, moderatorID int64, status, reason string) (*models.Review, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE reviews
+		SET status = $2, hidden_reason = $3, moderated_by = $4, moderated_at = NOW()
+		WHERE id = $1
+	`, reviewID, status, reason, moderatorID)
+	if err != nil {
+		log.Printf("Error moderating review %d: %v", reviewID, err)
+		return nil, fmt.Errorf("failed to moderate review: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrReviewNotFound
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE review_reports SET resolved_at = NOW() WHERE review_id = $1 AND resolved_at IS NULL
+	`, reviewID); err != nil {
+		log.Printf("Error resolving reports of review %d: %v", reviewID, err)
+		return nil, fmt.Errorf("failed to resolve reports: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit moderation: %w", err)
+	}
+	return r.GetByID(ctx, reviewID)
+}
+
+// Summary returns the review count, average reviewer rating, rating distribution and
+// most helpful visible review of a game. Reviews whose rating was removed are left out.
+func (r *PostgresReviewRepository) Summary(ctx context.Context, boardgameID int) (*models.ReviewSummary, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT ROUND(s.rating)::int, COUNT(*)::int, SUM(s.rating)
+		FROM reviews r
+		JOIN user_states s ON s.user_id = r.user_id AND s.boardgame_id = r.boardgame_id
+		WHERE r.boardgame_id = $1 AND r.status = 'visible' AND s.rating > 0
+		GROUP BY 1
+	`, boardgameID)
+	if err != nil {
+		log.Printf("Error fetching review summary for boardgame %d: %v", boardgameID, err)
+		return nil, fmt.Errorf("failed to fetch review summary: %w", err)
+	}
+	defer rows.Close()
+
+	summary := &models.ReviewSummary{Distribution: make(map[int]int)}
+	var total float64
+	for rows.Next() {
+		var bucket, count int
+		var sum float64
+		if err := rows.Scan(&bucket, &count, &sum); err != nil {
+			return nil, fmt.Errorf("failed to scan review summary: %w", err)
+		}
+		summary.Distribution[bucket] = count
+		summary.Count += count
+		total += sum
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read review summary: %w", err)
+	}
+	if summary.Count == 0 {
+		return summary, nil
+	}
+	summary.AverageRating = math.Round(total/float64(summary.Count)*100) / 100
+
+	top, err := r.ListForGame(ctx, ReviewQuery{BoardgameID: boardgameID, Sort: SortHelpful, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(top) > 0 && top[0].HelpfulCount > 0 {
+		summary.MostHelpful = &top[0]
+	}
+	return summary, nil
+}
+
+func isForeignKeyViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503" && pgErr.ConstraintName == constraint
+}
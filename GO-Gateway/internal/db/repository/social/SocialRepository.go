@@ -0,0 +1,316 @@
+package social
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"guru-game/models"
+)
+
+var (
+	// ErrUserNotFound is returned when the other user does not exist
+	ErrUserNotFound = errors.New("user not found")
+	// ErrFollowNotFound is returned when there is no such follow or follow request
+	ErrFollowNotFound = errors.New("follow not found")
+)
+
+// FeedCursor is the position of the last item of a feed page. Items are ordered by
+// OccurredAt, then Type, then ID, all descending.
+type FeedCursor struct {
+	OccurredAt time.Time
+	Type       string
+	ID         int64
+}
+
+// FeedQuery pages through a user's feed. A nil Before starts from the newest item.
+type FeedQuery struct {
+	ViewerID int64
+	Before   *FeedCursor
+	Limit    int
+}
+
+// SocialRepository defines the interface for follow graph and feed database operations
+type SocialRepository interface {
+	GetPrivacy(ctx context.Context, userID int64) (*models.PrivacySettings, error)
+	SetPrivacy(ctx context.Context, userID int64, s *models.PrivacySettings) error
+	Follow(ctx context.Context, followerID, followeeID int64) (string, error)
+	Unfollow(ctx context.Context, followerID, followeeID int64) error
+	Respond(ctx context.Context, followeeID, followerID int64, accept bool) error
+	ListFollowers(ctx context.Context, userID int64, status string) ([]models.Follow, error)
+	ListFollowing(ctx context.Context, userID int64) ([]models.Follow, error)
+	ListFriends(ctx context.Context, userID int64) ([]models.Follow, error)
+	Feed(ctx context.Context, q FeedQuery) ([]models.FeedItem, error)
+}
+
+// PostgresSocialRepository handles database operations for the social layer using pgxpool
+type PostgresSocialRepository struct {
+	DB *pgxpool.Pool
+}
+
+// NewPostgresSocialRepository creates a new PostgresSocialRepository
+func NewPostgresSocialRepository(db *pgxpool.Pool) *PostgresSocialRepository {
+	return &PostgresSocialRepository{DB: db}
+}
+
+// GetPrivacy returns a user's privacy settings, or the defaults when they never changed them
+func (r *PostgresSocialRepository) GetPrivacy(ctx context.Context, userID int64) (*models.PrivacySettings, error) {
+	s := &models.PrivacySettings{FeedVisibility: models.FeedFollowers}
+	err := r.DB.QueryRow(ctx, `
+		SELECT private_account, feed_visibility FROM user_privacy_settings WHERE user_id = $1
+	`, userID).Scan(&s.PrivateAccount, &s.FeedVisibility)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Error fetching privacy settings of user %d: %v", userID, err)
+		return nil, fmt.Errorf("failed to fetch privacy settings: %w", err)
+	}
+	return s, nil
+}
+
+// SetPrivacy saves a user's privacy settings. Making the account public accepts every
+// pending follow request.
+func (r *PostgresSocialRepository) SetPrivacy(ctx context.Context, userID int64, s *models.PrivacySettings) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO user_privacy_settings (user_id, private_account, feed_visibility)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET private_account = EXCLUDED.private_account, feed_visibility = EXCLUDED.feed_visibility, updated_at = NOW()
+	`, userID, s.PrivateAccount, s.FeedVisibility); err != nil {
+		log.Printf("Error saving privacy settings of user %d: %v", userID, err)
+		return fmt.Errorf("failed to save privacy settings: %w", err)
+	}
+	if !s.PrivateAccount {
+		if _, err := tx.Exec(ctx, `
+			UPDATE user_follows SET status = 'accepted', accepted_at = NOW()
+			WHERE followee_id = $1 AND status = 'pending'
+		`, userID); err != nil {
+			log.Printf("Error accepting pending follows of user %d: %v", userID, err)
+			return fmt.Errorf("failed to accept pending follows: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit privacy settings: %w", err)
+	}
+	return nil
+}
+
+// Follow makes followerID follow followeeID and returns the status of the follow. Following
+// a private account creates a pending request. Following again keeps the existing follow;
+// the no-op update locks and returns it, including a row a concurrent follow just inserted.
+func (r *PostgresSocialRepository) Follow(ctx context.Context, followerID, followeeID int64) (string, error) {
+	var status string
+	err := r.DB.QueryRow(ctx, `
+		INSERT INTO user_follows (follower_id, followee_id, status, accepted_at)
+		SELECT $1, $2, s.status, CASE WHEN s.status = 'accepted' THEN NOW() END
+		FROM (
+			SELECT CASE WHEN COALESCE(p.private_account, FALSE) THEN 'pending' ELSE 'accepted' END AS status
+			FROM (SELECT $2::bigint AS user_id) u
+			LEFT JOIN user_privacy_settings p ON p.user_id = u.user_id
+		) s
+		ON CONFLICT (follower_id, followee_id) DO UPDATE SET status = user_follows.status
+		RETURNING status
+	`, followerID, followeeID).Scan(&status)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return "", ErrUserNotFound
+		}
+		log.Printf("Error following user %d by user %d: %v", followeeID, followerID, err)
+		return "", fmt.Errorf("failed to follow user: %w", err)
+	}
+	return status, nil
+}
+
+// Unfollow removes a follow or withdraws a pending request
+func (r *PostgresSocialRepository) Unfollow(ctx context.Context, followerID, followeeID int64) error {
+	tag, err := r.DB.Exec(ctx, `
+		DELETE FROM user_follows WHERE follower_id = $1 AND followee_id = $2
+	`, followerID, followeeID)
+	if err != nil {
+		log.Printf("Error removing follow %d -> %d: %v", followerID, followeeID, err)
+		return fmt.Errorf("failed to remove follow: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrFollowNotFound
+	}
+	return nil
+}
+
+// Respond accepts or declines a pending follow request sent to followeeID
+func (r *PostgresSocialRepository) Respond(ctx context.Context, followeeID, followerID int64, accept bool) error {
+	query := `DELETE FROM user_follows WHERE follower_id = $1 AND followee_id = $2 AND status = 'pending'`
+	if accept {
+		query = `
+			UPDATE user_follows SET status = 'accepted', accepted_at = NOW()
+			WHERE follower_id = $1 AND followee_id = $2 AND status = 'pending'
+		`
+	}
+	tag, err := r.DB.Exec(ctx, query, followerID, followeeID)
+	if err != nil {
+		log.Printf("Error answering follow request %d -> %d: %v", followerID, followeeID, err)
+		return fmt.Errorf("failed to answer follow request: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrFollowNotFound
+	}
+	return nil
+}
+
+// ListFollowers returns the users following userID with the given status, newest first
+func (r *PostgresSocialRepository) ListFollowers(ctx context.Context, userID int64, status string) ([]models.Follow, error) {
+	return r.listFollows(ctx, `
+		SELECT f.follower_id, u.username, COALESCE(u.avatar_url, ''), f.status,
+		       EXISTS (
+				SELECT 1 FROM user_follows b
+				WHERE b.follower_id = $1 AND b.followee_id = f.follower_id AND b.status = 'accepted'
+			   ) AND f.status = 'accepted',
+		       f.created_at, f.accepted_at
+		FROM user_follows f
+		JOIN users u ON u.id = f.follower_id
+		WHERE f.followee_id = $1 AND f.status = $2
+		ORDER BY f.created_at DESC
+	`, userID, status)
+}
+
+// ListFollowing returns the users userID follows or asked to follow, newest first
+func (r *PostgresSocialRepository) ListFollowing(ctx context.Context, userID int64) ([]models.Follow, error) {
+	return r.listFollows(ctx, `
+		SELECT f.followee_id, u.username, COALESCE(u.avatar_url, ''), f.status,
+		       EXISTS (
+				SELECT 1 FROM user_follows b
+				WHERE b.follower_id = f.followee_id AND b.followee_id = $1 AND b.status = 'accepted'
+			   ) AND f.status = 'accepted',
+		       f.created_at, f.accepted_at
+		FROM user_follows f
+		JOIN users u ON u.id = f.followee_id
+		WHERE f.follower_id = $1
+		ORDER BY f.created_at DESC
+	`, userID)
+}
+
+// ListFriends returns the users who follow userID and are followed back
+func (r *PostgresSocialRepository) ListFriends(ctx context.Context, userID int64) ([]models.Follow, error) {
+	return r.listFollows(ctx, `
+		SELECT f.followee_id, u.username, COALESCE(u.avatar_url, ''), f.status, TRUE,
+		       f.created_at, GREATEST(f.accepted_at, b.accepted_at)
+		FROM user_follows f
+		JOIN user_follows b ON b.follower_id = f.followee_id AND b.followee_id = f.follower_id AND b.status = 'accepted'
+		JOIN users u ON u.id = f.followee_id
+		WHERE f.follower_id = $1 AND f.status = 'accepted'
+		ORDER BY u.username
+	`, userID)
+}
+
+func (r *PostgresSocialRepository) listFollows(ctx context.Context, query string, args ...any) ([]models.Follow, error) {
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		log.Printf("Error listing follows: %v", err)
+		return nil, fmt.Errorf("failed to list follows: %w", err)
+	}
+	follows, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Follow, error) {
+		var f models.Follow
+		err := row.Scan(&f.UserID, &f.Username, &f.AvatarURL, &f.Status, &f.Friend, &f.CreatedAt, &f.AcceptedAt)
+		return f, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan follows: %w", err)
+	}
+	return follows, nil
+}
+
+// Feed returns a page of the ratings, reviews, plays and new favorites of the users
+// ViewerID follows, newest first. Authors whose feed is limited to friends only appear
+// when they follow the viewer back; authors who share with nobody never appear.
+// A play item only says that the author logged a play of the game: its notes and
+// participants stay visible to the people in the play alone. Each source is paged
+// on its own before the pages are merged, so the cursor and limit use the indexes.
+func (r *PostgresSocialRepository) Feed(ctx context.Context, q FeedQuery) ([]models.FeedItem, error) {
+	var beforeAt *time.Time
+	var beforeType string
+	var beforeID int64
+	if q.Before != nil {
+		beforeAt, beforeType, beforeID = &q.Before.OccurredAt, q.Before.Type, q.Before.ID
+	}
+
+	rows, err := r.DB.Query(ctx, `
+		WITH authors AS (
+			SELECT f.followee_id AS user_id
+			FROM user_follows f
+			LEFT JOIN user_privacy_settings p ON p.user_id = f.followee_id
+			WHERE f.follower_id = $1 AND f.status = 'accepted'
+			  AND (
+				COALESCE(p.feed_visibility, 'followers') = 'followers'
+				OR (p.feed_visibility = 'friends' AND EXISTS (
+					SELECT 1 FROM user_follows b
+					WHERE b.follower_id = f.followee_id AND b.followee_id = $1 AND b.status = 'accepted'
+				))
+			  )
+		), items AS (
+			(SELECT 'rating'::text AS type, e.id, e.user_id, e.boardgame_id, e.rating, ''::text AS text, e.created_at AS occurred_at
+			FROM user_state_events e
+			JOIN authors a ON a.user_id = e.user_id
+			WHERE 'rating' = ANY(e.changed_fields) AND e.rating > 0
+			  AND ($2::timestamptz IS NULL OR (e.created_at, 'rating'::text, e.id::bigint) < ($2, $3::text, $4::bigint))
+			ORDER BY e.created_at DESC, e.id DESC
+			LIMIT $5)
+			UNION ALL
+			(SELECT 'favorite', e.id, e.user_id, e.boardgame_id, NULL, '', e.created_at
+			FROM user_state_events e
+			JOIN authors a ON a.user_id = e.user_id
+			WHERE 'favorited' = ANY(e.changed_fields) AND e.favorited
+			  AND ($2::timestamptz IS NULL OR (e.created_at, 'favorite'::text, e.id::bigint) < ($2, $3::text, $4::bigint))
+			ORDER BY e.created_at DESC, e.id DESC
+			LIMIT $5)
+			UNION ALL
+			(SELECT 'review', rv.id, rv.user_id, rv.boardgame_id, s.rating, LEFT(rv.body, 280), rv.created_at
+			FROM reviews rv
+			JOIN authors a ON a.user_id = rv.user_id
+			JOIN user_states s ON s.user_id = rv.user_id AND s.boardgame_id = rv.boardgame_id
+			WHERE rv.status = 'visible' AND s.rating > 0
+			  AND ($2::timestamptz IS NULL OR (rv.created_at, 'review'::text, rv.id::bigint) < ($2, $3::text, $4::bigint))
+			ORDER BY rv.created_at DESC, rv.id DESC
+			LIMIT $5)
+			UNION ALL
+			(SELECT 'play', p.id, p.user_id, p.boardgame_id, NULL, '', p.created_at
+			FROM plays p
+			JOIN authors a ON a.user_id = p.user_id
+			WHERE $2::timestamptz IS NULL OR (p.created_at, 'play'::text, p.id::bigint) < ($2, $3::text, $4::bigint)
+			ORDER BY p.created_at DESC, p.id DESC
+			LIMIT $5)
+		)
+		SELECT i.type, i.id, i.user_id, COALESCE(u.username, ''), i.boardgame_id, COALESCE(b.title, ''),
+		       i.rating, i.text, i.occurred_at
+		FROM items i
+		LEFT JOIN users u ON u.id = i.user_id
+		LEFT JOIN boardgames b ON b.id = i.boardgame_id
+		ORDER BY i.occurred_at DESC, i.type DESC, i.id DESC
+		LIMIT $5
+	`, q.ViewerID, beforeAt, beforeType, beforeID, q.Limit)
+	if err != nil {
+		log.Printf("Error fetching feed for user %d: %v", q.ViewerID, err)
+		return nil, fmt.Errorf("failed to fetch feed: %w", err)
+	}
+
+	items, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.FeedItem, error) {
+		var it models.FeedItem
+		err := row.Scan(&it.Type, &it.SourceID, &it.UserID, &it.Username, &it.BoardgameID, &it.GameTitle,
+			&it.Rating, &it.Text, &it.OccurredAt)
+		return it, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan feed: %w", err)
+	}
+	return items, nil
+}
@@ -0,0 +1,336 @@
+package user_states
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"guru-game/internal/db/repository/outbox"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Sources recorded on user_state_events
+const (
+	SourceGameState = "game_state"
+	SourceActivity  = "activity"
+)
+
+// StateChange is a partial update to a user state; nil fields are left unchanged
+type StateChange struct {
+	Liked     *bool
+	Favorited *bool
+	Rating    *float64
+	Source    string
+
+	// ExpectedVersion, when set, makes the change conditional on the stored version
+	// (0 = the state must not exist yet). A mismatch returns a *VersionConflictError.
+	ExpectedVersion *int64
+
+	// Log, when set, is appended to user_activity_log in the same transaction. If its
+	// event ID is already logged for the user, nothing is changed and ErrDuplicateEvent
+	// is returned, so a resent activity is never applied twice.
+	Log *LoggedActivity
+}
+
+// LoggedActivity is the user_activity_log row recorded together with a state change
+type LoggedActivity struct {
+	Type       string
+	SessionID  string
+	EventID    *string
+	Data       interface{}
+	OccurredAt time.Time
+}
+
+// ErrDuplicateEvent is returned by ApplyChange when the change's event ID was already logged
+var ErrDuplicateEvent = errors.New("event already recorded")
+
+// VersionConflictError is returned by ApplyChange when ExpectedVersion is stale
+type VersionConflictError struct {
+	Current UserState
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("user state for user %d, boardgame %d is at version %d", e.Current.UserID, e.Current.BoardgameID, e.Current.Version)
+}
+
+// UserStateEvent is one row of the append-only user_state_events log
+type UserStateEvent struct {
+	ID            int64     `json:"id"`
+	UserID        int       `json:"user_id"`
+	BoardgameID   int       `json:"boardgame_id"`
+	Liked         bool      `json:"liked"`
+	Favorited     bool      `json:"favorited"`
+	Rating        float64   `json:"rating"`
+	PrevLiked     *bool     `json:"prev_liked"`
+	PrevFavorited *bool     `json:"prev_favorited"`
+	PrevRating    *float64  `json:"prev_rating"`
+	ChangedFields []string  `json:"changed_fields"`
+	Source        string    `json:"source"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// EventQuery filters and pages through events newest first. Zero UserID/BoardgameID
+// match any user/game; BeforeID 0 starts from the latest event.
+type EventQuery struct {
+	UserID      int
+	BoardgameID int
+	BeforeID    int64
+	Limit       int
+}
+
+// ApplyChange merges change into the user's state for a board game and appends a
+// user_state_events row in the same transaction, together with the recommendation_outbox
+// messages that forward the change to the recommendation service. A missing row is first
+// inserted with default values, so there is always a row to lock while merging and
+// concurrent changes to different fields (including the first ones) do not overwrite
+// each other. If nothing changes, nothing is written.
+func (r *PostgresUserStateRepository) ApplyChange(ctx context.Context, userID, boardgameID int, change StateChange) (*UserState, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if change.Log != nil {
+		// A concurrent resend blocks on the unique index until this commits, then skips
+		logged, err := logActivity(ctx, tx, userID, boardgameID, change.Log)
+		if err != nil {
+			return nil, err
+		}
+		if !logged {
+			return nil, ErrDuplicateEvent
+		}
+	}
+
+	// A concurrent first write blocks here until it commits, then this one conflicts
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO user_states (user_id, boardgame_id, liked, favorited, rating, updated_at)
+		VALUES ($1, $2, FALSE, FALSE, 0, NOW())
+		ON CONFLICT (user_id, boardgame_id) DO NOTHING
+	`, userID, boardgameID)
+	if err != nil {
+		log.Printf("Error creating user state for user %d, boardgame %d: %v", userID, boardgameID, err)
+		return nil, fmt.Errorf("failed to create user state: %w", err)
+	}
+	created := tag.RowsAffected() == 1
+
+	var existing UserState
+	err = tx.QueryRow(ctx, `
+		SELECT user_id, boardgame_id, liked, favorited, rating, version, updated_at
+		FROM user_states
+		WHERE user_id = $1 AND boardgame_id = $2
+		FOR UPDATE
+	`, userID, boardgameID).Scan(&existing.UserID, &existing.BoardgameID, &existing.Liked, &existing.Favorited, &existing.Rating, &existing.Version, &existing.UpdatedAt)
+	if err != nil {
+		log.Printf("Error loading user state for user %d, boardgame %d: %v", userID, boardgameID, err)
+		return nil, fmt.Errorf("failed to load user state: %w", err)
+	}
+
+	// prev is nil when the row did not exist before this change; the default row
+	// inserted above is rolled back unless something is saved
+	var prev *UserState
+	next := UserState{UserID: userID, BoardgameID: boardgameID}
+	if !created {
+		prev = &existing
+		next = existing
+	}
+	if expected := change.ExpectedVersion; expected != nil && !versionMatches(*expected, created, existing.Version) {
+		return nil, &VersionConflictError{Current: next}
+	}
+	var changed []string
+	if change.Liked != nil && *change.Liked != next.Liked {
+		next.Liked = *change.Liked
+		changed = append(changed, "liked")
+	}
+	if change.Favorited != nil && *change.Favorited != next.Favorited {
+		next.Favorited = *change.Favorited
+		changed = append(changed, "favorited")
+	}
+	if change.Rating != nil && *change.Rating != next.Rating {
+		next.Rating = *change.Rating
+		changed = append(changed, "rating")
+	}
+
+	if len(changed) == 0 {
+		// Without a log row the rollback drops the default row; with one, the activity is
+		// still committed, so the default row has to go explicitly
+		if change.Log != nil {
+			if created {
+				if _, err := tx.Exec(ctx, `DELETE FROM user_states WHERE user_id = $1 AND boardgame_id = $2`, userID, boardgameID); err != nil {
+					return nil, fmt.Errorf("failed to drop default user state: %w", err)
+				}
+			}
+			if err := tx.Commit(ctx); err != nil {
+				return nil, fmt.Errorf("failed to commit activity: %w", err)
+			}
+		}
+		return &next, nil
+	}
+
+	// The version stays at its default of 1 for a row created by this change
+	err = tx.QueryRow(ctx, `
+		UPDATE user_states
+		SET liked = $3,
+		    favorited = $4,
+		    rating = $5,
+		    version = version + CASE WHEN $6 THEN 0 ELSE 1 END,
+		    updated_at = NOW()
+		WHERE user_id = $1 AND boardgame_id = $2
+		RETURNING version, updated_at
+	`, userID, boardgameID, next.Liked, next.Favorited, next.Rating, created).Scan(&next.Version, &next.UpdatedAt)
+	if err != nil {
+		log.Printf("Error saving or updating user state: %v", err)
+		return nil, fmt.Errorf("failed to save or update user state: %w", err)
+	}
+
+	event := UserStateEvent{
+		UserID:        userID,
+		BoardgameID:   boardgameID,
+		Liked:         next.Liked,
+		Favorited:     next.Favorited,
+		Rating:        next.Rating,
+		ChangedFields: changed,
+		Source:        change.Source,
+	}
+	if event.ChangedFields == nil {
+		event.ChangedFields = []string{}
+	}
+	if prev != nil {
+		event.PrevLiked, event.PrevFavorited, event.PrevRating = &prev.Liked, &prev.Favorited, &prev.Rating
+	}
+	if err := insertEvent(ctx, tx, &event); err != nil {
+		return nil, err
+	}
+	if err := outbox.Enqueue(ctx, tx, recommendationActions(next, changed)...); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit user state: %w", err)
+	}
+
+	log.Printf("User state saved/updated successfully for user_id: %d, boardgame_id: %d (%v)", userID, boardgameID, changed)
+
+	return &next, nil
+}
+
+// versionMatches checks an expected version under the row lock. Version 0 means the state
+// must not exist yet, which only holds for the transaction that inserted the default row:
+// a concurrent creator waits on that insert and then sees the row as existing.
+func versionMatches(expected int64, created bool, current int64) bool {
+	if created {
+		return expected == 0
+	}
+	return expected == current
+}
+
+// recommendationActions maps the changed fields to recommender actions. The recommender
+// only learns from positive signals, so unlikes, cleared ratings and favorites are not sent.
+// Ratings are scaled from 0–10 to its 1–5 range.
+func recommendationActions(s UserState, changed []string) []outbox.Message {
+	var msgs []outbox.Message
+	for _, field := range changed {
+		msg := outbox.Message{UserID: int64(s.UserID), BoardgameID: s.BoardgameID, ActionTime: s.UpdatedAt, ActionValue: 1}
+		switch {
+		case field == "liked" && s.Liked:
+			msg.ActionType = outbox.ActionLike
+		case field == "rating" && s.Rating > 0:
+			msg.ActionType = outbox.ActionRate
+			msg.ActionValue = math.Max(1, math.Min(5, s.Rating/2))
+		default:
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+// logActivity appends a to user_activity_log and reports whether it was new. Activities
+// without an event ID are always new.
+func logActivity(ctx context.Context, tx pgx.Tx, userID, boardgameID int, a *LoggedActivity) (bool, error) {
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO user_activity_log (user_id, type, boardgame_id, session_id, event_id, data, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id, event_id) WHERE event_id IS NOT NULL DO NOTHING
+	`, userID, a.Type, boardgameID, a.SessionID, a.EventID, a.Data, a.OccurredAt)
+	if err != nil {
+		log.Printf("Error logging activity for user %d: %v", userID, err)
+		return false, fmt.Errorf("failed to log activity: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func insertEvent(ctx context.Context, tx pgx.Tx, e *UserStateEvent) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO user_state_events (
+			user_id, boardgame_id, liked, favorited, rating,
+			prev_liked, prev_favorited, prev_rating, changed_fields, source
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, e.UserID, e.BoardgameID, e.Liked, e.Favorited, e.Rating,
+		e.PrevLiked, e.PrevFavorited, e.PrevRating, e.ChangedFields, e.Source)
+	if err != nil {
+		log.Printf("Error appending user state event: %v", err)
+		return fmt.Errorf("failed to append user state event: %w", err)
+	}
+	return nil
+}
+
+// Get returns a user's state for a board game. A missing row is returned as a zero state with Version 0.
+func (r *PostgresUserStateRepository) Get(ctx context.Context, userID, boardgameID int) (*UserState, error) {
+	state := UserState{UserID: userID, BoardgameID: boardgameID}
+	err := r.DB.QueryRow(ctx, `
+		SELECT liked, favorited, rating, version, updated_at
+		FROM user_states
+		WHERE user_id = $1 AND boardgame_id = $2
+	`, userID, boardgameID).Scan(&state.Liked, &state.Favorited, &state.Rating, &state.Version, &state.UpdatedAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Error fetching user state for user %d, boardgame %d: %v", userID, boardgameID, err)
+		return nil, fmt.Errorf("failed to fetch user state: %w", err)
+	}
+	return &state, nil
+}
+
+// GetEvents returns user state events matching q, newest first
+func (r *PostgresUserStateRepository) GetEvents(ctx context.Context, q EventQuery) ([]UserStateEvent, error) {
+	query := `
+		SELECT id, user_id, boardgame_id, liked, favorited, rating,
+		       prev_liked, prev_favorited, prev_rating, changed_fields, source, created_at
+		FROM user_state_events
+		WHERE ($1 = 0 OR user_id = $1)
+		  AND ($2 = 0 OR boardgame_id = $2)
+		  AND ($3 = 0 OR id < $3)
+		ORDER BY id DESC
+		LIMIT $4
+	`
+
+	rows, err := r.DB.Query(ctx, query, q.UserID, q.BoardgameID, q.BeforeID, q.Limit)
+	if err != nil {
+		log.Printf("Error fetching user state events (user %d, boardgame %d): %v", q.UserID, q.BoardgameID, err)
+		return nil, fmt.Errorf("failed to fetch user state events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []UserStateEvent{}
+	for rows.Next() {
+		var e UserStateEvent
+		err := rows.Scan(&e.ID, &e.UserID, &e.BoardgameID, &e.Liked, &e.Favorited, &e.Rating,
+			&e.PrevLiked, &e.PrevFavorited, &e.PrevRating, &e.ChangedFields, &e.Source, &e.CreatedAt)
+		if err != nil {
+			log.Printf("Error scanning user state event row: %v", err)
+			return nil, fmt.Errorf("failed to scan user state event row: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("Error after iterating over user state event rows: %v", err)
+		return nil, fmt.Errorf("error after fetching user state events: %w", err)
+	}
+
+	return events, nil
+}
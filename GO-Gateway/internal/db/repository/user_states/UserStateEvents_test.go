@@ -0,0 +1,66 @@
+package user_states
+
+import (
+	"testing"
+
+	"guru-game/internal/db/repository/outbox"
+)
+
+func TestVersionMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected int64
+		created  bool
+		current  int64
+		want     bool
+	}{
+		{"create a missing state", 0, true, 1, true},
+		{"create when another request created it first", 0, false, 1, false},
+		{"update a missing state", 3, true, 1, false},
+		{"update the current version", 3, false, 3, true},
+		{"update a stale version", 2, false, 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := versionMatches(tt.expected, tt.created, tt.current); got != tt.want {
+				t.Errorf("versionMatches(%d, %v, %d) = %v, want %v", tt.expected, tt.created, tt.current, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecommendationActions(t *testing.T) {
+	tests := []struct {
+		name    string
+		state   UserState
+		changed []string
+		want    []string  // action types
+		values  []float64 // action values
+	}{
+		{"like", UserState{Liked: true}, []string{"liked"}, []string{outbox.ActionLike}, []float64{1}},
+		{"unlike is not sent", UserState{Liked: false}, []string{"liked"}, nil, nil},
+		{"favorite is not sent", UserState{Favorited: true}, []string{"favorited"}, nil, nil},
+		{"rating is scaled to 1-5", UserState{Rating: 8}, []string{"rating"}, []string{outbox.ActionRate}, []float64{4}},
+		{"low rating is raised to 1", UserState{Rating: 1}, []string{"rating"}, []string{outbox.ActionRate}, []float64{1}},
+		{"cleared rating is not sent", UserState{Rating: 0}, []string{"rating"}, nil, nil},
+		{"several fields", UserState{Liked: true, Rating: 10}, []string{"liked", "favorited", "rating"}, []string{outbox.ActionLike, outbox.ActionRate}, []float64{1, 5}},
+		{"nothing changed", UserState{Liked: true}, nil, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.state.UserID, tt.state.BoardgameID = 7, 42
+			msgs := recommendationActions(tt.state, tt.changed)
+			if len(msgs) != len(tt.want) {
+				t.Fatalf("got %d messages, want %d: %+v", len(msgs), len(tt.want), msgs)
+			}
+			for i, m := range msgs {
+				if m.ActionType != tt.want[i] || m.ActionValue != tt.values[i] {
+					t.Errorf("message %d = %s %v, want %s %v", i, m.ActionType, m.ActionValue, tt.want[i], tt.values[i])
+				}
+				if m.UserID != 7 || m.BoardgameID != 42 {
+					t.Errorf("message %d is for user %d, game %d", i, m.UserID, m.BoardgameID)
+				}
+			}
+		})
+	}
+}
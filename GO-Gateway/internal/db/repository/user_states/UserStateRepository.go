@@ -16,6 +16,7 @@ type UserState struct {
 	Liked       bool      `json:"liked"`
 	Favorited   bool      `json:"favorited"`
 	Rating      float64   `json:"rating"`
+	Version     int64     `json:"version"` // 0 means the row does not exist yet
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
@@ -24,6 +25,10 @@ type UserStateRepository interface {
 	SaveOrUpdate(ctx context.Context, userState *UserState) error
 	GetFavoritedByUserID(ctx context.Context, userID int) ([]UserState, error)
 	GetAllByUserID(ctx context.Context, userID int) ([]UserState, error)
+	Get(ctx context.Context, userID, boardgameID int) (*UserState, error)
+	ApplyChange(ctx context.Context, userID, boardgameID int, change StateChange) (*UserState, error)
+	GetEvents(ctx context.Context, q EventQuery) ([]UserStateEvent, error)
+	SimilarGames(ctx context.Context, userID, limit int) (map[int]float64, error)
 }
 
 // PostgresUserStateRepository handles database operations for UserState using pgxpool
@@ -36,42 +41,28 @@ func NewPostgresUserStateRepository(db *pgxpool.Pool) *PostgresUserStateReposito
 	return &PostgresUserStateRepository{DB: db}
 }
 
-// SaveOrUpdate saves or updates a user state in PostgreSQL
+// SaveOrUpdate saves or updates a user state in PostgreSQL, overwriting all fields,
+// and records the change in user_state_events
 func (r *PostgresUserStateRepository) SaveOrUpdate(ctx context.Context, userState *UserState) error {
-	// Use INSERT ... ON CONFLICT (user_id, boardgame_id) DO UPDATE to upsert
-	query := `
-		INSERT INTO user_states (user_id, boardgame_id, liked, favorited, rating, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		ON CONFLICT (user_id, boardgame_id) DO UPDATE
-		SET liked = EXCLUDED.liked,
-		    favorited = EXCLUDED.favorited,
-		    rating = EXCLUDED.rating,
-		    updated_at = EXCLUDED.updated_at
-	`
-
-	_, err := r.DB.Exec(ctx, query,
-		userState.UserID,
-		userState.BoardgameID,
-		userState.Liked,
-		userState.Favorited,
-		userState.Rating,
-		time.Now(),
-	)
-
+	saved, err := r.ApplyChange(ctx, userState.UserID, userState.BoardgameID, StateChange{
+		Liked:     &userState.Liked,
+		Favorited: &userState.Favorited,
+		Rating:    &userState.Rating,
+		Source:    SourceGameState,
+	})
 	if err != nil {
-		log.Printf("Error saving or updating user state: %v", err)
-		return fmt.Errorf("failed to save or update user state: %w", err)
+		return err
 	}
 
-	log.Printf("User state saved/updated successfully for user_id: %d, boardgame_id: %d", userState.UserID, userState.BoardgameID)
-
+	userState.Version = saved.Version
+	userState.UpdatedAt = saved.UpdatedAt
 	return nil
 }
 
 // GetFavoritedByUserID fetches favorited user states for a given user ID from PostgreSQL
 func (r *PostgresUserStateRepository) GetFavoritedByUserID(ctx context.Context, userID int) ([]UserState, error) {
 	query := `
-		SELECT user_id, boardgame_id, liked, favorited, rating, updated_at
+		SELECT user_id, boardgame_id, liked, favorited, rating, version, updated_at
 		FROM user_states
 		WHERE user_id = $1 AND favorited = TRUE
 	`
@@ -86,7 +77,7 @@ func (r *PostgresUserStateRepository) GetFavoritedByUserID(ctx context.Context,
 	var favoritedStates []UserState
 	for rows.Next() {
 		var state UserState
-		err := rows.Scan(&state.UserID, &state.BoardgameID, &state.Liked, &state.Favorited, &state.Rating, &state.UpdatedAt)
+		err := rows.Scan(&state.UserID, &state.BoardgameID, &state.Liked, &state.Favorited, &state.Rating, &state.Version, &state.UpdatedAt)
 		if err != nil {
 			log.Printf("Error scanning favorited user state row: %v", err)
 			return nil, fmt.Errorf("failed to scan favorited user state row: %w", err)
@@ -108,7 +99,7 @@ func (r *PostgresUserStateRepository) GetFavoritedByUserID(ctx context.Context,
 // GetAllByUserID fetches all user states for a given user ID from PostgreSQL
 func (r *PostgresUserStateRepository) GetAllByUserID(ctx context.Context, userID int) ([]UserState, error) {
 	query := `
-		SELECT user_id, boardgame_id, liked, favorited, rating, updated_at
+		SELECT user_id, boardgame_id, liked, favorited, rating, version, updated_at
 		FROM user_states
 		WHERE user_id = $1
 	`
@@ -123,7 +114,7 @@ func (r *PostgresUserStateRepository) GetAllByUserID(ctx context.Context, userID
 	var userStates []UserState
 	for rows.Next() {
 		var state UserState
-		err := rows.Scan(&state.UserID, &state.BoardgameID, &state.Liked, &state.Favorited, &state.Rating, &state.UpdatedAt)
+		err := rows.Scan(&state.UserID, &state.BoardgameID, &state.Liked, &state.Favorited, &state.Rating, &state.Version, &state.UpdatedAt)
 		if err != nil {
 			log.Printf("Error scanning user state row: %v", err)
 			return nil, fmt.Errorf("failed to scan user state row: %w", err)
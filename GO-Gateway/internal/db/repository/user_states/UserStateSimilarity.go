@@ -0,0 +1,56 @@
+package user_states
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// PositiveRating is the lowest rating that counts as the user liking a game when
+// computing similar games
+const PositiveRating = 7
+
+// SimilarGames scores games the user has no state for by item-item collaborative filtering.
+// A game's score is the sum of its similarity to each game the user likes, favorited or
+// rated at least PositiveRating. Similarities are precomputed into game_similarities by the
+// aggregation service, so scores lag user_states by up to one recompute interval.
+// At most limit games are returned, keyed by boardgame ID.
+func (r *PostgresUserStateRepository) SimilarGames(ctx context.Context, userID, limit int) (map[int]float64, error) {
+	query := `
+		SELECT s.candidate_id, SUM(s.similarity) AS score
+		FROM user_states mine
+		JOIN game_similarities s ON s.source_id = mine.boardgame_id
+		WHERE mine.user_id = $1 AND (mine.liked OR mine.favorited OR mine.rating >= $2)
+			AND NOT EXISTS (
+				SELECT 1 FROM user_states known
+				WHERE known.user_id = $1 AND known.boardgame_id = s.candidate_id
+			)
+		GROUP BY s.candidate_id
+		ORDER BY score DESC, s.candidate_id
+		LIMIT $3
+	`
+
+	rows, err := r.DB.Query(ctx, query, userID, PositiveRating, limit)
+	if err != nil {
+		log.Printf("Error computing similar games for user %d: %v", userID, err)
+		return nil, fmt.Errorf("failed to compute similar games: %w", err)
+	}
+	defer rows.Close()
+
+	scores := make(map[int]float64)
+	for rows.Next() {
+		var id int
+		var score float64
+		if err := rows.Scan(&id, &score); err != nil {
+			log.Printf("Error scanning similar game row: %v", err)
+			return nil, fmt.Errorf("failed to scan similar game row: %w", err)
+		}
+		scores[id] = score
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error after iterating over similar game rows: %v", err)
+		return nil, fmt.Errorf("error after computing similar games: %w", err)
+	}
+
+	return scores, nil
+}
@@ -0,0 +1,267 @@
+package walkthroughs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"guru-game/models"
+)
+
+// ErrWalkthroughNotFound is returned when no walkthrough matches the given ID or join code
+var ErrWalkthroughNotFound = errors.New("walkthrough not found")
+
+// Event actions recorded in rule_walkthrough_events
+const (
+	ActionStart    = "start"
+	ActionNext     = "next"
+	ActionPrevious = "previous"
+	ActionJump     = "jump"
+	ActionComplete = "complete"
+)
+
+// WalkthroughRepository defines the interface for walkthrough database operations
+type WalkthroughRepository interface {
+	Create(ctx context.Context, w *models.Walkthrough) error
+	GetByID(ctx context.Context, id int64) (*models.Walkthrough, error)
+	GetByJoinCode(ctx context.Context, code string) (*models.Walkthrough, error)
+	AddParticipant(ctx context.Context, walkthroughID, userID int64) error
+	IsParticipant(ctx context.Context, walkthroughID, userID int64) (bool, error)
+	MoveToStep(ctx context.Context, walkthroughID, userID int64, step int, action string) (*models.Walkthrough, error)
+	Complete(ctx context.Context, walkthroughID, userID int64) (*models.Walkthrough, error)
+	GetStepStats(ctx context.Context, ruleID int) ([]models.WalkthroughStepStat, error)
+}
+
+// PostgresWalkthroughRepository handles database operations for walkthroughs using pgxpool
+type PostgresWalkthroughRepository struct {
+	DB *pgxpool.Pool
+}
+
+// NewPostgresWalkthroughRepository creates a new PostgresWalkthroughRepository
+func NewPostgresWalkthroughRepository(db *pgxpool.Pool) *PostgresWalkthroughRepository {
+	return &PostgresWalkthroughRepository{DB: db}
+}
+
+const walkthroughColumns = `id, rule_id, rule_version, owner_id, join_code, current_step, total_steps, started_at, updated_at, completed_at`
+
+func scanWalkthrough(row pgx.Row) (*models.Walkthrough, error) {
+	var w models.Walkthrough
+	err := row.Scan(&w.ID, &w.RuleID, &w.RuleVersion, &w.OwnerID, &w.JoinCode, &w.CurrentStep, &w.TotalSteps, &w.StartedAt, &w.UpdatedAt, &w.CompletedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWalkthroughNotFound
+		}
+		return nil, err
+	}
+	return &w, nil
+}
+
+// Create starts a walkthrough at step 1, adds the owner as a participant and records the first view
+func (r *PostgresWalkthroughRepository) Create(ctx context.Context, w *models.Walkthrough) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	created, err := scanWalkthrough(tx.QueryRow(ctx, `
+		INSERT INTO rule_walkthroughs (rule_id, rule_version, owner_id, join_code, current_step, total_steps)
+		VALUES ($1, $2, $3, $4, 1, $5)
+		RETURNING `+walkthroughColumns,
+		w.RuleID, w.RuleVersion, w.OwnerID, w.JoinCode, w.TotalSteps))
+	if err != nil {
+		log.Printf("Error creating walkthrough for rule %d: %v", w.RuleID, err)
+		return fmt.Errorf("failed to create walkthrough: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `INSERT INTO rule_walkthrough_participants (walkthrough_id, user_id) VALUES ($1, $2)`, created.ID, created.OwnerID); err != nil {
+		return fmt.Errorf("failed to add walkthrough owner: %w", err)
+	}
+	if err := insertEvent(ctx, tx, created.ID, created.OwnerID, 1, ActionStart); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit walkthrough: %w", err)
+	}
+
+	*w = *created
+	log.Printf("Walkthrough %d started for rule %d v%d by user %d", w.ID, w.RuleID, w.RuleVersion, w.OwnerID)
+
+	return nil
+}
+
+// GetByID fetches a walkthrough by ID
+func (r *PostgresWalkthroughRepository) GetByID(ctx context.Context, id int64) (*models.Walkthrough, error) {
+	w, err := scanWalkthrough(r.DB.QueryRow(ctx, `SELECT `+walkthroughColumns+` FROM rule_walkthroughs WHERE id = $1`, id))
+	if err != nil && !errors.Is(err, ErrWalkthroughNotFound) {
+		log.Printf("Error fetching walkthrough %d: %v", id, err)
+		return nil, fmt.Errorf("failed to fetch walkthrough: %w", err)
+	}
+	return w, err
+}
+
+// GetByJoinCode fetches a walkthrough by its join code
+func (r *PostgresWalkthroughRepository) GetByJoinCode(ctx context.Context, code string) (*models.Walkthrough, error) {
+	w, err := scanWalkthrough(r.DB.QueryRow(ctx, `SELECT `+walkthroughColumns+` FROM rule_walkthroughs WHERE join_code = $1`, code))
+	if err != nil && !errors.Is(err, ErrWalkthroughNotFound) {
+		log.Printf("Error fetching walkthrough by join code: %v", err)
+		return nil, fmt.Errorf("failed to fetch walkthrough: %w", err)
+	}
+	return w, err
+}
+
+// AddParticipant adds a user to a walkthrough; joining twice is a no-op
+func (r *PostgresWalkthroughRepository) AddParticipant(ctx context.Context, walkthroughID, userID int64) error {
+	_, err := r.DB.Exec(ctx, `
+		INSERT INTO rule_walkthrough_participants (walkthrough_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, walkthroughID, userID)
+	if err != nil {
+		log.Printf("Error adding user %d to walkthrough %d: %v", userID, walkthroughID, err)
+		return fmt.Errorf("failed to join walkthrough: %w", err)
+	}
+	return nil
+}
+
+// IsParticipant reports whether a user has joined a walkthrough
+func (r *PostgresWalkthroughRepository) IsParticipant(ctx context.Context, walkthroughID, userID int64) (bool, error) {
+	var exists bool
+	err := r.DB.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM rule_walkthrough_participants WHERE walkthrough_id = $1 AND user_id = $2)
+	`, walkthroughID, userID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check walkthrough participant: %w", err)
+	}
+	return exists, nil
+}
+
+// MoveToStep sets the current step and records the view, flagging it as a re-read
+// when the step was already shown earlier in this walkthrough
+func (r *PostgresWalkthroughRepository) MoveToStep(ctx context.Context, walkthroughID, userID int64, step int, action string) (*models.Walkthrough, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	w, err := scanWalkthrough(tx.QueryRow(ctx, `
+		UPDATE rule_walkthroughs
+		SET current_step = $2, updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+walkthroughColumns, walkthroughID, step))
+	if err != nil {
+		if errors.Is(err, ErrWalkthroughNotFound) {
+			return nil, err
+		}
+		log.Printf("Error moving walkthrough %d to step %d: %v", walkthroughID, step, err)
+		return nil, fmt.Errorf("failed to update walkthrough: %w", err)
+	}
+
+	if err := insertEvent(ctx, tx, walkthroughID, userID, step, action); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit walkthrough step: %w", err)
+	}
+
+	return w, nil
+}
+
+// Complete marks a walkthrough as finished
+func (r *PostgresWalkthroughRepository) Complete(ctx context.Context, walkthroughID, userID int64) (*models.Walkthrough, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	w, err := scanWalkthrough(tx.QueryRow(ctx, `
+		UPDATE rule_walkthroughs
+		SET completed_at = COALESCE(completed_at, NOW()), updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+walkthroughColumns, walkthroughID))
+	if err != nil {
+		if errors.Is(err, ErrWalkthroughNotFound) {
+			return nil, err
+		}
+		log.Printf("Error completing walkthrough %d: %v", walkthroughID, err)
+		return nil, fmt.Errorf("failed to complete walkthrough: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO rule_walkthrough_events (walkthrough_id, user_id, step, action)
+		VALUES ($1, $2, $3, $4)
+	`, walkthroughID, userID, w.CurrentStep, ActionComplete); err != nil {
+		return nil, fmt.Errorf("failed to record walkthrough completion: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit walkthrough completion: %w", err)
+	}
+
+	log.Printf("Walkthrough %d completed", walkthroughID)
+
+	return w, nil
+}
+
+// GetStepStats aggregates views and re-reads per version and step across all walkthroughs
+// of a rule set, since step N of two versions can be different text. The newest version
+// comes first, most re-read steps first within it.
+func (r *PostgresWalkthroughRepository) GetStepStats(ctx context.Context, ruleID int) ([]models.WalkthroughStepStat, error) {
+	query := `
+		SELECT w.rule_version,
+		       e.step,
+		       COUNT(*) AS views,
+		       COUNT(*) FILTER (WHERE e.reread) AS rereads,
+		       COUNT(DISTINCT e.walkthrough_id) AS sessions
+		FROM rule_walkthrough_events e
+		JOIN rule_walkthroughs w ON w.id = e.walkthrough_id
+		WHERE w.rule_id = $1 AND e.action <> $2
+		GROUP BY w.rule_version, e.step
+		ORDER BY w.rule_version DESC, rereads DESC, e.step
+	`
+
+	rows, err := r.DB.Query(ctx, query, ruleID, ActionComplete)
+	if err != nil {
+		log.Printf("Error fetching walkthrough stats for rule %d: %v", ruleID, err)
+		return nil, fmt.Errorf("failed to fetch walkthrough stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []models.WalkthroughStepStat
+	for rows.Next() {
+		var s models.WalkthroughStepStat
+		if err := rows.Scan(&s.RuleVersion, &s.Step, &s.Views, &s.Rereads, &s.Sessions); err != nil {
+			return nil, fmt.Errorf("failed to scan walkthrough stat row: %w", err)
+		}
+		stats = append(stats, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after fetching walkthrough stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+func insertEvent(ctx context.Context, tx pgx.Tx, walkthroughID, userID int64, step int, action string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO rule_walkthrough_events (walkthrough_id, user_id, step, action, reread)
+		VALUES ($1, $2, $3, $4, EXISTS (
+			SELECT 1 FROM rule_walkthrough_events
+			WHERE walkthrough_id = $1 AND step = $3 AND action <> 'complete'
+		))
+	`, walkthroughID, userID, step, action)
+	if err != nil {
+		log.Printf("Error recording walkthrough %d event: %v", walkthroughID, err)
+		return fmt.Errorf("failed to record walkthrough event: %w", err)
+	}
+	return nil
+}
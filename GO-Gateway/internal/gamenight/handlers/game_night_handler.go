@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"guru-game/internal/auth/jwt"
+	"guru-game/internal/db/repository/gamenights"
+	"guru-game/internal/gamenight"
+	"guru-game/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GameNightHandlers holds the dependencies for game night handlers
+type GameNightHandlers struct {
+	Service *gamenight.Service
+}
+
+// NewGameNightHandlers creates a new GameNightHandlers instance
+func NewGameNightHandlers(service *gamenight.Service) *GameNightHandlers {
+	return &GameNightHandlers{Service: service}
+}
+
+type gameNightInput struct {
+	Title    string    `json:"title"`
+	StartsAt time.Time `json:"starts_at"`
+	Location string    `json:"location"`
+	Notes    string    `json:"notes"`
+	Invitees []int64   `json:"invitees"`
+}
+
+func (in gameNightInput) gameNight() *models.GameNight {
+	return &models.GameNight{Title: in.Title, StartsAt: in.StartsAt, Location: in.Location, Notes: in.Notes}
+}
+
+// HandleCreateGameNight plans a game night hosted by the caller
+func (h *GameNightHandlers) HandleCreateGameNight(c *fiber.Ctx) error {
+	userID, ok := jwt.UserIDFromCtx(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var in gameNightInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	n, err := h.Service.Create(c.Context(), userID, in.gameNight(), in.Invitees)
+	if err != nil {
+		return gameNightError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(n)
+}
+
+// HandleListGameNights lists the game nights the caller hosts or is invited to: ?include_past=true
+func (h *GameNightHandlers) HandleListGameNights(c *fiber.Ctx) error {
+	userID, ok := jwt.UserIDFromCtx(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	list, err := h.Service.List(c.Context(), userID, c.QueryBool("include_past"))
+	if err != nil {
+		return gameNightError(c, err)
+	}
+	return c.JSON(fiber.Map{"game_nights": list})
+}
+
+// HandleGetGameNight returns a game night with its invitees
+func (h *GameNightHandlers) HandleGetGameNight(c *fiber.Ctx) error {
+	userID, id, ok := jwt.UserAndIDParam[int64](c, "id", "game night")
+	if !ok {
+		return nil
+	}
+
+	n, err := h.Service.Get(c.Context(), userID, id)
+	if err != nil {
+		return gameNightError(c, err)
+	}
+	return c.JSON(n)
+}
+
+// HandleUpdateGameNight replaces the details of a game night the caller hosts
+func (h *GameNightHandlers) HandleUpdateGameNight(c *fiber.Ctx) error {
+	userID, id, ok := jwt.UserAndIDParam[int64](c, "id", "game night")
+	if !ok {
+		return nil
+	}
+
+	var in gameNightInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	n, err := h.Service.Update(c.Context(), userID, id, in.gameNight())
+	if err != nil {
+		return gameNightError(c, err)
+	}
+	return c.JSON(n)
+}
+
+// HandleCancelGameNight cancels a game night the caller hosts
+func (h *GameNightHandlers) HandleCancelGameNight(c *fiber.Ctx) error {
+	userID, id, ok := jwt.UserAndIDParam[int64](c, "id", "game night")
+	if !ok {
+		return nil
+	}
+
+	n, err := h.Service.Cancel(c.Context(), userID, id)
+	if err != nil {
+		return gameNightError(c, err)
+	}
+	return c.JSON(n)
+}
+
+// HandleInvite invites users to a game night the caller hosts: {"user_ids": [...]}
+func (h *GameNightHandlers) HandleInvite(c *fiber.Ctx) error {
+	userID, id, ok := jwt.UserAndIDParam[int64](c, "id", "game night")
+	if !ok {
+		return nil
+	}
+
+	var in struct {
+		UserIDs []int64 `json:"user_ids"`
+	}
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	n, err := h.Service.Invite(c.Context(), userID, id, in.UserIDs)
+	if err != nil {
+		return gameNightError(c, err)
+	}
+	return c.JSON(n)
+}
+
+// HandleUninvite removes the :user_id invitee from a game night the caller hosts
+func (h *GameNightHandlers) HandleUninvite(c *fiber.Ctx) error {
+	userID, id, ok := jwt.UserAndIDParam[int64](c, "id", "game night")
+	if !ok {
+		return nil
+	}
+	inviteeID, err := strconv.ParseInt(c.Params("user_id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+
+	if err := h.Service.Uninvite(c.Context(), userID, id, inviteeID); err != nil {
+		return gameNightError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleRSVP records the caller's answer: {"rsvp": "yes|maybe|no"}
+func (h *GameNightHandlers) HandleRSVP(c *fiber.Ctx) error {
+	userID, id, ok := jwt.UserAndIDParam[int64](c, "id", "game night")
+	if !ok {
+		return nil
+	}
+
+	var in struct {
+		RSVP string `json:"rsvp"`
+	}
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	n, err := h.Service.RSVP(c.Context(), userID, id, in.RSVP)
+	if err != nil {
+		return gameNightError(c, err)
+	}
+	return c.JSON(n)
+}
+
+// HandleShortlist returns the candidate games for a game night: ?max_time=
+func (h *GameNightHandlers) HandleShortlist(c *fiber.Ctx) error {
+	userID, id, ok := jwt.UserAndIDParam[int64](c, "id", "game night")
+	if !ok {
+		return nil
+	}
+
+	games, err := h.Service.Shortlist(c.Context(), userID, id, c.QueryInt("max_time"))
+	if err != nil {
+		return gameNightError(c, err)
+	}
+	return c.JSON(fiber.Map{"shortlist": games})
+}
+
+// HandleVote votes for the :game_id shortlisted game
+func (h *GameNightHandlers) HandleVote(c *fiber.Ctx) error {
+	return h.vote(c, true)
+}
+
+// HandleUnvote takes back the caller's vote for the :game_id game
+func (h *GameNightHandlers) HandleUnvote(c *fiber.Ctx) error {
+	return h.vote(c, false)
+}
+
+func (h *GameNightHandlers) vote(c *fiber.Ctx, vote bool) error {
+	userID, id, ok := jwt.UserAndIDParam[int64](c, "id", "game night")
+	if !ok {
+		return nil
+	}
+	gameID, err := strconv.Atoi(c.Params("game_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid board game ID"})
+	}
+
+	games, err := h.Service.Vote(c.Context(), userID, id, gameID, vote)
+	if err != nil {
+		return gameNightError(c, err)
+	}
+	return c.JSON(fiber.Map{"shortlist": games})
+}
+
+func gameNightError(c *fiber.Ctx, err error) error {
+	var validationErr *gamenight.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": validationErr.Error()})
+	case errors.Is(err, gamenights.ErrUnknownUser):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, gamenights.ErrGameNightNotFound), errors.Is(err, gamenight.ErrNotVisible):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Game night not found"})
+	case errors.Is(err, gamenights.ErrNotInvited):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Invite not found"})
+	case errors.Is(err, gamenight.ErrNotHost):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, gamenight.ErrCancelled):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	default:
+		log.Println("Game night operation failed ->", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Game night operation failed"})
+	}
+}
@@ -0,0 +1,308 @@
+package gamenight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"guru-game/internal/db/repository/gamenights"
+	"guru-game/models"
+)
+
+var (
+	// ErrNotHost is returned when someone other than the host changes a game night
+	ErrNotHost = errors.New("only the host can change this game night")
+	// ErrNotVisible is returned when a user reads a game night they are not invited to
+	ErrNotVisible = errors.New("you are not invited to this game night")
+	// ErrCancelled is returned when a cancelled game night is changed
+	ErrCancelled = errors.New("this game night was cancelled")
+)
+
+// Limits on game night input
+const (
+	MaxTitleLength    = 200
+	MaxLocationLength = 200
+	MaxNotesLength    = 5000
+	MaxInvitees       = 50
+	maxPastSkew       = time.Hour
+	// Game nights stay in the default list until this long after they start
+	listGracePeriod = 12 * time.Hour
+)
+
+// ValidationError reports invalid game night input
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string { return e.Err.Error() }
+
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// Service manages game nights, invitations, RSVPs and shortlist voting
+type Service struct {
+	repo gamenights.GameNightRepository
+}
+
+// NewService creates a new game night Service
+func NewService(repo gamenights.GameNightRepository) *Service {
+	return &Service{repo: repo}
+}
+
+// Create plans a game night hosted by hostID and invites the given users
+func (s *Service) Create(ctx context.Context, hostID int64, n *models.GameNight, invitees []int64) (*models.GameNight, error) {
+	n.HostID = hostID
+	if err := validate(n, nil, time.Now()); err != nil {
+		return nil, err
+	}
+	invitees, err := normalizeInvitees(hostID, invitees)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, n, invitees); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, n.ID)
+}
+
+// Get returns a game night userID hosts or is invited to
+func (s *Service) Get(ctx context.Context, userID, id int64) (*models.GameNight, error) {
+	n, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if invite(n, userID) == nil {
+		return nil, ErrNotVisible
+	}
+	return n, nil
+}
+
+// List returns the game nights userID hosts or is invited to, soonest first. Unless
+// includePast is set, only game nights that started less than 12 hours ago or later are listed.
+func (s *Service) List(ctx context.Context, userID int64, includePast bool) ([]models.GameNight, error) {
+	from := time.Now().Add(-listGracePeriod)
+	if includePast {
+		from = time.Time{}
+	}
+	return s.repo.ListForUser(ctx, userID, from)
+}
+
+// Update replaces the details of a game night hosted by userID. The start time only has
+// to lie ahead when it changes, so a game night that already started can still be edited.
+func (s *Service) Update(ctx context.Context, userID, id int64, n *models.GameNight) (*models.GameNight, error) {
+	current, err := s.hosted(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	n.ID, n.HostID = id, userID
+	if err := validate(n, current, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, n); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// Cancel cancels a game night hosted by userID
+func (s *Service) Cancel(ctx context.Context, userID, id int64) (*models.GameNight, error) {
+	if _, err := s.hosted(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetStatus(ctx, id, models.GameNightCancelled); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// Invite adds invitees to a game night hosted by userID
+func (s *Service) Invite(ctx context.Context, userID, id int64, invitees []int64) (*models.GameNight, error) {
+	n, err := s.hosted(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	invitees, err = normalizeInvitees(userID, invitees)
+	if err != nil {
+		return nil, err
+	}
+	added := 0
+	for _, inviteeID := range invitees {
+		if invite(n, inviteeID) == nil {
+			added++
+		}
+	}
+	// The host is stored as an invitee too
+	if len(n.Invites)-1+added > MaxInvitees {
+		return nil, &ValidationError{Err: fmt.Errorf("a game night can have at most %d invitees", MaxInvitees)}
+	}
+	if err := s.repo.AddInvites(ctx, id, invitees); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// Uninvite removes an invitee from a game night hosted by userID
+func (s *Service) Uninvite(ctx context.Context, userID, id, inviteeID int64) error {
+	if _, err := s.hosted(ctx, userID, id); err != nil {
+		return err
+	}
+	if inviteeID == userID {
+		return &ValidationError{Err: errors.New("the host cannot be uninvited")}
+	}
+	return s.repo.RemoveInvite(ctx, id, inviteeID)
+}
+
+// RSVP records userID's answer to a game night invitation
+func (s *Service) RSVP(ctx context.Context, userID, id int64, rsvp string) (*models.GameNight, error) {
+	switch rsvp {
+	case models.RSVPYes, models.RSVPMaybe, models.RSVPNo:
+	default:
+		return nil, &ValidationError{Err: fmt.Errorf("rsvp must be one of %s, %s, %s", models.RSVPYes, models.RSVPMaybe, models.RSVPNo)}
+	}
+	n, err := s.open(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if n.HostID == userID && rsvp != models.RSVPYes {
+		return nil, &ValidationError{Err: errors.New("the host always attends; cancel the game night instead")}
+	}
+	if err := s.repo.SetRSVP(ctx, id, userID, rsvp); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// Shortlist returns the games confirmed attendees own that fit the confirmed player count,
+// most voted first. maxPlayTime 0 means no limit.
+func (s *Service) Shortlist(ctx context.Context, userID, id int64, maxPlayTime int) ([]models.ShortlistGame, error) {
+	n, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if maxPlayTime < 0 {
+		maxPlayTime = 0
+	}
+	return s.repo.Shortlist(ctx, gamenights.ShortlistQuery{
+		GameNightID: id,
+		ViewerID:    userID,
+		PlayerCount: n.ConfirmedCount,
+		MaxPlayTime: maxPlayTime,
+	})
+}
+
+// Vote adds or removes userID's vote for a shortlisted game. Only confirmed attendees
+// can vote; anyone invited can take their vote back.
+func (s *Service) Vote(ctx context.Context, userID, id int64, boardgameID int, vote bool) ([]models.ShortlistGame, error) {
+	n, err := s.open(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if vote {
+		if invite(n, userID).RSVP != models.RSVPYes {
+			return nil, &ValidationError{Err: errors.New("only confirmed attendees can vote; RSVP yes first")}
+		}
+		shortlist, err := s.Shortlist(ctx, userID, id, 0)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, g := range shortlist {
+			if g.BoardgameID == boardgameID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, &ValidationError{Err: errors.New("the game is not on the shortlist")}
+		}
+	}
+
+	if err := s.repo.SetVote(ctx, id, userID, boardgameID, vote); err != nil {
+		return nil, err
+	}
+	return s.Shortlist(ctx, userID, id, 0)
+}
+
+// CoAttendeeIDs returns the users who confirmed a game night userID also confirmed
+func (s *Service) CoAttendeeIDs(ctx context.Context, userID int64) (map[int64]bool, error) {
+	return s.repo.CoAttendeeIDs(ctx, userID)
+}
+
+// hosted returns a planned game night hosted by userID
+func (s *Service) hosted(ctx context.Context, userID, id int64) (*models.GameNight, error) {
+	n, err := s.open(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if n.HostID != userID {
+		return nil, ErrNotHost
+	}
+	return n, nil
+}
+
+// open returns a planned game night userID is invited to
+func (s *Service) open(ctx context.Context, userID, id int64) (*models.GameNight, error) {
+	n, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if n.Status == models.GameNightCancelled {
+		return nil, ErrCancelled
+	}
+	return n, nil
+}
+
+func invite(n *models.GameNight, userID int64) *models.GameNightInvite {
+	for i := range n.Invites {
+		if n.Invites[i].UserID == userID {
+			return &n.Invites[i]
+		}
+	}
+	return nil
+}
+
+// validate checks game night input. current is the stored game night when updating, or nil.
+func validate(n *models.GameNight, current *models.GameNight, now time.Time) error {
+	var problems []string
+	n.Title = strings.TrimSpace(n.Title)
+	if n.Title == "" || utf8.RuneCountInString(n.Title) > MaxTitleLength {
+		problems = append(problems, fmt.Sprintf("title is required and must be at most %d characters", MaxTitleLength))
+	}
+	if n.StartsAt.IsZero() {
+		problems = append(problems, "starts_at is required")
+	} else if (current == nil || !n.StartsAt.Equal(current.StartsAt)) && n.StartsAt.Before(now.Add(-maxPastSkew)) {
+		problems = append(problems, "starts_at cannot be in the past")
+	}
+	if utf8.RuneCountInString(n.Location) > MaxLocationLength {
+		problems = append(problems, fmt.Sprintf("location must be at most %d characters", MaxLocationLength))
+	}
+	if utf8.RuneCountInString(n.Notes) > MaxNotesLength {
+		problems = append(problems, fmt.Sprintf("notes must be at most %d characters", MaxNotesLength))
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Err: errors.New(strings.Join(problems, "; "))}
+	}
+	return nil
+}
+
+// normalizeInvitees drops duplicates and the host from invitees
+func normalizeInvitees(hostID int64, invitees []int64) ([]int64, error) {
+	seen := map[int64]bool{hostID: true}
+	var out []int64
+	for _, id := range invitees {
+		if id <= 0 {
+			return nil, &ValidationError{Err: errors.New("invitee IDs must be positive")}
+		}
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	if len(out) > MaxInvitees {
+		return nil, &ValidationError{Err: fmt.Errorf("a game night can have at most %d invitees", MaxInvitees)}
+	}
+	return out, nil
+}
@@ -0,0 +1,78 @@
+package gamenight
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"guru-game/models"
+)
+
+func TestValidateStartsAt(t *testing.T) {
+	now := time.Date(2026, 6, 1, 20, 0, 0, 0, time.UTC)
+	started := &models.GameNight{Title: "Friday games", StartsAt: now.Add(-3 * time.Hour)}
+
+	tests := []struct {
+		name     string
+		startsAt time.Time
+		current  *models.GameNight
+		wantErr  string
+	}{
+		{"new in the future", now.Add(24 * time.Hour), nil, ""},
+		{"new within skew", now.Add(-30 * time.Minute), nil, ""},
+		{"new in the past", now.Add(-2 * time.Hour), nil, "starts_at cannot be in the past"},
+		{"missing", time.Time{}, nil, "starts_at is required"},
+		{"started, time unchanged", started.StartsAt, started, ""},
+		{"started, same instant in another zone", started.StartsAt.In(time.FixedZone("ICT", 7*3600)), started, ""},
+		{"started, moved to another past time", started.StartsAt.Add(-time.Hour), started, "starts_at cannot be in the past"},
+		{"started, moved to the future", now.Add(time.Hour), started, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &models.GameNight{Title: "Friday games", StartsAt: tt.startsAt, Notes: "Bring snacks"}
+			err := validate(n, tt.current, now)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTextLengths(t *testing.T) {
+	now := time.Date(2026, 6, 1, 20, 0, 0, 0, time.UTC)
+	thai := func(n int) string { return strings.Repeat("ก", n) }
+
+	tests := []struct {
+		name    string
+		night   models.GameNight
+		wantErr string
+	}{
+		{"thai text at the limits", models.GameNight{Title: thai(MaxTitleLength), Location: thai(MaxLocationLength), Notes: thai(MaxNotesLength)}, ""},
+		{"title required", models.GameNight{Title: "   "}, "title is required"},
+		{"thai title too long", models.GameNight{Title: thai(MaxTitleLength + 1)}, "title is required and must be at most"},
+		{"thai location too long", models.GameNight{Title: "Friday games", Location: thai(MaxLocationLength + 1)}, "location must be at most"},
+		{"thai notes too long", models.GameNight{Title: "Friday games", Notes: thai(MaxNotesLength + 1)}, "notes must be at most"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := tt.night
+			n.StartsAt = now.Add(24 * time.Hour)
+			err := validate(&n, nil, now)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
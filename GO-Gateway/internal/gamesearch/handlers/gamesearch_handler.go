@@ -5,6 +5,7 @@ import (
 
 	"encoding/json"
 	"fmt"
+	"guru-game/internal/poll"
 	"guru-game/models"
 	"net/http"
 	"strings"
@@ -36,6 +37,11 @@ func (h *GameSearchHandlers) HandleGameSearch(c *fiber.Ctx) error {
 
 	log.Printf("Received Game Search Query: %+v", query)
 
+	filter := poll.Filter{MinWeight: query.MinWeight, MaxWeight: query.MaxWeight, BestPlayerCount: query.BestAt}
+	if err := filter.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
 	// Construct the URL for the Python service search endpoint
 	pythonSearchURL := fmt.Sprintf("%s/api/search?searchQuery=%s&playerCount=%d&playTime=%d",
 		h.PythonServiceURL, query.SearchQuery, query.PlayerCount, query.PlayTime)
@@ -53,6 +59,17 @@ func (h *GameSearchHandlers) HandleGameSearch(c *fiber.Ctx) error {
 		pythonSearchURL = fmt.Sprintf("%s&page=%d", pythonSearchURL, query.Page)
 	}
 
+	// Poll filters are applied by the search service before it pages the results
+	if filter.MinWeight > 0 {
+		pythonSearchURL = fmt.Sprintf("%s&minWeight=%g", pythonSearchURL, filter.MinWeight)
+	}
+	if filter.MaxWeight > 0 {
+		pythonSearchURL = fmt.Sprintf("%s&maxWeight=%g", pythonSearchURL, filter.MaxWeight)
+	}
+	if filter.BestPlayerCount > 0 {
+		pythonSearchURL = fmt.Sprintf("%s&bestAt=%d", pythonSearchURL, filter.BestPlayerCount)
+	}
+
 	log.Printf("Forwarding search request to Python service: %s", pythonSearchURL)
 
 	// Make the HTTP GET request to the Python service
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"guru-game/internal/db/repository/user_states"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// stateETag formats a user state version as a strong ETag
+func stateETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ifMatch is a parsed If-Match header
+type ifMatch struct {
+	any      bool // If-Match: *
+	versions []int64
+}
+
+// parseIfMatch parses a comma-separated list of version ETags. Weak validators
+// (W/"3") are accepted as their strong equivalent since clients never modify the body.
+func parseIfMatch(header string) (*ifMatch, error) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return nil, nil
+	}
+	if header == "*" {
+		return &ifMatch{any: true}, nil
+	}
+
+	m := &ifMatch{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			return nil, errors.New("If-Match must contain quoted ETags")
+		}
+		v, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+		if err != nil || v < 0 {
+			return nil, errors.New("If-Match contains an unknown ETag")
+		}
+		m.versions = append(m.versions, v)
+	}
+	return m, nil
+}
+
+// matches reports whether the precondition holds for the given stored version
+func (m *ifMatch) matches(version int64) bool {
+	if m.any {
+		return version > 0
+	}
+	for _, v := range m.versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// stateResponse renders a user state in the shape used by the game state API and sets its ETag
+func stateResponse(c *fiber.Ctx, state *user_states.UserState) fiber.Map {
+	c.Set(fiber.HeaderETag, stateETag(state.Version))
+	return fiber.Map{
+		"user_id": state.UserID,
+		"game_id": state.BoardgameID,
+		"version": state.Version,
+		"state": fiber.Map{
+			"is_liked":    state.Liked,
+			"is_favorite": state.Favorited,
+			"userRating":  state.Rating,
+			"updated_at":  state.UpdatedAt,
+		},
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    *ifMatch
+		wantErr bool
+	}{
+		{"missing", "", nil, false},
+		{"blank", "   ", nil, false},
+		{"any", "*", &ifMatch{any: true}, false},
+		{"single", `"3"`, &ifMatch{versions: []int64{3}}, false},
+		{"zero", `"0"`, &ifMatch{versions: []int64{0}}, false},
+		{"weak", `W/"4"`, &ifMatch{versions: []int64{4}}, false},
+		{"list", `"1", W/"2" ,"3"`, &ifMatch{versions: []int64{1, 2, 3}}, false},
+		{"unquoted", `3`, nil, true},
+		{"half quoted", `"3`, nil, true},
+		{"not a number", `"abc"`, nil, true},
+		{"negative", `"-1"`, nil, true},
+		{"empty element", `"1",`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseIfMatch(tt.header)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseIfMatch(%q) error = %v, wantErr %v", tt.header, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseIfMatch(%q) = %+v, want %+v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIfMatchMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		m       ifMatch
+		version int64
+		want    bool
+	}{
+		{"any matches an existing state", ifMatch{any: true}, 2, true},
+		{"any does not match a missing state", ifMatch{any: true}, 0, false},
+		{"listed version", ifMatch{versions: []int64{1, 2}}, 2, true},
+		{"unlisted version", ifMatch{versions: []int64{1, 2}}, 3, false},
+		{"zero matches a missing state", ifMatch{versions: []int64{0}}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.m.matches(tt.version); got != tt.want {
+				t.Errorf("matches(%d) = %v, want %v", tt.version, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,109 +1,191 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"strconv"
+	"strings"
 	"time"
 
+	"guru-game/internal/auth/jwt"
 	"guru-game/internal/db/repository/user_states"
+	"guru-game/internal/userstate"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-// GameStateUpdateData represents the nested data structure for updates
-type GameStateUpdateData map[string]interface{}
+// GameID accepts the game_id as either a JSON number or a numeric string,
+// since older clients send it as a string
+type GameID int
+
+// UnmarshalJSON implements json.Unmarshaler
+func (g *GameID) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	id, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("game_id must be an integer, got %s", string(data))
+	}
+	*g = GameID(id)
+	return nil
+}
+
+// GameStatePatch holds the fields to change; omitted (null) fields keep their current value
+type GameStatePatch struct {
+	IsLiked    *bool    `json:"is_liked"`
+	IsFavorite *bool    `json:"is_favorite"`
+	UserRating *float64 `json:"userRating"`
+}
 
 // GameStateUpdate represents the expected structure of the incoming request body
 type GameStateUpdate struct {
-	UserID     int                 `json:"user_id"` // Changed to int based on DB schema
-	GameIDStr  string              `json:"game_id"` // Temporarily read as string
-	UpdateData GameStateUpdateData `json:"state"`   // Changed json tag to "state"
+	UserID int            `json:"user_id"` // Optional; must match the token's user when given
+	GameID GameID         `json:"game_id"`
+	State  GameStatePatch `json:"state"`
+}
+
+// Validate checks the update against the rules that don't need the database
+func (u *GameStateUpdate) Validate() error {
+	var problems []string
+	if u.GameID <= 0 {
+		problems = append(problems, "game_id is required")
+	}
+	if u.State.IsLiked == nil && u.State.IsFavorite == nil && u.State.UserRating == nil {
+		problems = append(problems, "state must set at least one of is_liked, is_favorite or userRating")
+	}
+	if r := u.State.UserRating; r != nil && (*r < userstate.MinRating || *r > userstate.MaxRating) {
+		problems = append(problems, fmt.Sprintf("userRating must be between %d and %d", userstate.MinRating, userstate.MaxRating))
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
 }
 
 // GameStateHandlers holds the necessary dependencies for game state handlers
 type GameStateHandlers struct {
-	UserStateRepo user_states.UserStateRepository
+	States *userstate.Service
 }
 
 // NewGameStateHandlers creates a new GameStateHandlers instance
-func NewGameStateHandlers(userStateRepo user_states.UserStateRepository) *GameStateHandlers {
-	return &GameStateHandlers{
-		UserStateRepo: userStateRepo,
+func NewGameStateHandlers(states *userstate.Service) *GameStateHandlers {
+	return &GameStateHandlers{States: states}
+}
+
+// HandleGetGameState returns the caller's state for ?game_id= with its ETag
+func (h *GameStateHandlers) HandleGetGameState(c *fiber.Ctx) error {
+	userID, ok := jwt.UserIDFromCtx(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+	gameID := c.QueryInt("game_id")
+	if gameID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "game_id is required"})
 	}
+
+	state, err := h.States.Get(c.Context(), int(userID), gameID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch user state"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(stateResponse(c, state))
 }
 
-// HandleGameStateUpdate receives and processes game state updates
+// HandleGameStateUpdate applies a partial update to the caller's state for a game.
+// Only the fields present in "state" are changed. The request must carry the state's
+// current ETag in If-Match ("0" when the state does not exist yet); a stale ETag gets
+// 412 with the current state so the client can merge and retry.
 func (h *GameStateHandlers) HandleGameStateUpdate(c *fiber.Ctx) error {
 	timestamp := time.Now().Format("2006-01-02 15:04:05")
 	log.Printf("\n\n[%s] ===== GAME STATE UPDATE RECEIVED =====\n", timestamp)
 
-	// Log raw request body
-	rawBody := c.Body()
-	log.Printf("Raw Request Body: %s", string(rawBody))
+	tokenUserID, ok := jwt.UserIDFromCtx(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
 
 	var update GameStateUpdate
-
-	// Parse the request body
-	if err := c.BodyParser(&update); err != nil {
+	if err := json.Unmarshal(c.Body(), &update); err != nil {
 		log.Printf("[%s] Error parsing game state update request body: %v", timestamp, err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "failed to parse request body",
+			"error": "failed to parse request body: " + err.Error(),
+		})
+	}
+
+	if update.UserID == 0 {
+		update.UserID = int(tokenUserID)
+	} else if int64(update.UserID) != tokenUserID {
+		log.Printf("[%s] User %d tried to update state of user %d", timestamp, tokenUserID, update.UserID)
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "you can only update your own game state",
 		})
 	}
 
-	// Convert game_id from string to int
-	gameID, err := strconv.Atoi(update.GameIDStr)
+	if err := update.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	precondition, err := parseIfMatch(c.Get(fiber.HeaderIfMatch))
 	if err != nil {
-		log.Printf("[%s] Error converting game_id to int: %v", timestamp, err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid game_id format",
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	gameID := int(update.GameID)
+	current, err := h.States.Get(c.Context(), update.UserID, gameID)
+	if err != nil {
+		log.Printf("[%s] Error loading user state: %v", timestamp, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load user state"})
+	}
+	if precondition == nil {
+		return c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{
+			"error":   "If-Match header with the current ETag is required",
+			"current": stateResponse(c, current),
+		})
+	}
+	if !precondition.matches(current.Version) {
+		return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+			"error":   "game state was modified by another request",
+			"current": stateResponse(c, current),
 		})
 	}
 
-	// Extract data from updateData (state)
-	// Safely extract boolean and float values with type assertions
-	liked, _ := update.UpdateData["is_liked"].(bool)
-	favorited, _ := update.UpdateData["is_favorite"].(bool)
-	// Need to handle both int and float64 for rating if necessary, though float64 is safer
-	rating, ok := update.UpdateData["userRating"].(float64)
-	if !ok {
-		// If it's not a float64, try int (e.g., if 0 was sent as 0 instead of 0.0)
-		intRating, ok := update.UpdateData["userRating"].(int)
-		if ok {
-			rating = float64(intRating)
-		} else {
-			log.Printf("[%s] Warning: userRating is not a number type: %T", timestamp, update.UpdateData["userRating"])
-			// Default rating to 0.0 or handle as an error if rating is mandatory
-			rating = 0.0
-		}
-	}
-
-	// Create UserState object
-	userState := &user_states.UserState{
-		UserID:      update.UserID,
-		BoardgameID: gameID, // Use the converted int gameID
-		Liked:       liked,
-		Favorited:   favorited,
-		Rating:      rating,
-		UpdatedAt:   time.Now(),
-	}
-
-	// Save or update user state in the database
-	err = h.UserStateRepo.SaveOrUpdate(c.Context(), userState)
+	// Re-checked under the row lock, so a write that lands between Get and here still conflicts
+	expected := current.Version
+	state, err := h.States.ApplyChange(c.Context(), update.UserID, gameID, user_states.StateChange{
+		Liked:           update.State.IsLiked,
+		Favorited:       update.State.IsFavorite,
+		Rating:          update.State.UserRating,
+		Source:          user_states.SourceGameState,
+		ExpectedVersion: &expected,
+	})
+	var conflict *user_states.VersionConflictError
+	if errors.As(err, &conflict) {
+		return c.Status(fiber.StatusPreconditionFailed).JSON(fiber.Map{
+			"error":   "game state was modified by another request",
+			"current": stateResponse(c, &conflict.Current),
+		})
+	}
 	if err != nil {
 		log.Printf("[%s] Error saving or updating user state: %v", timestamp, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to save or update user state",
-		})
+		return stateError(c, err)
 	}
 
 	log.Printf("[%s] ===== GAME STATE UPDATE PROCESSED =====\n", timestamp)
 
-	// Return the processed data in the desired format (using original string game_id for frontend consistency if needed, or converted int)
-	// Returning the converted int game_id might be better for frontend consistency with type
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"user_id": update.UserID,
-		"game_id": gameID, // Return as int
-		"state":   update.UpdateData,
-	})
+	// Return the full state after the update, with its new ETag, so clients can resync
+	return c.Status(fiber.StatusOK).JSON(stateResponse(c, state))
+}
+
+// stateError maps userstate.Service errors to HTTP responses
+func stateError(c *fiber.Ctx, err error) error {
+	var validationErr *userstate.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": validationErr.Error()})
+	case errors.Is(err, userstate.ErrBoardgameNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "board game not found"})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to save or update user state"})
+	}
 }
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"strconv"
+
+	"guru-game/internal/auth/jwt"
+	"guru-game/internal/db/repository/user_states"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultEventPageSize = 50
+	maxEventPageSize     = 500
+)
+
+// HandleGetMyStateEvents returns the logged-in user's state history: ?game_id=&before_id=&limit=
+func (h *GameStateHandlers) HandleGetMyStateEvents(c *fiber.Ctx) error {
+	userID, ok := jwt.UserIDFromCtx(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	q := eventQuery(c)
+	q.UserID = int(userID)
+	q.BoardgameID = c.QueryInt("game_id")
+
+	events, err := h.States.Events(c.Context(), q)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch state history"})
+	}
+
+	return c.JSON(eventPage(events))
+}
+
+// HandleGetBoardgameStateEvents returns the state history of a board game across all users: ?user_id=&before_id=&limit=
+func (h *GameStateHandlers) HandleGetBoardgameStateEvents(c *fiber.Ctx) error {
+	boardgameID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid boardgame ID"})
+	}
+
+	q := eventQuery(c)
+	q.BoardgameID = boardgameID
+	q.UserID = c.QueryInt("user_id")
+
+	events, err := h.States.Events(c.Context(), q)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch state history"})
+	}
+
+	return c.JSON(eventPage(events))
+}
+
+func eventQuery(c *fiber.Ctx) user_states.EventQuery {
+	limit := c.QueryInt("limit", defaultEventPageSize)
+	if limit <= 0 || limit > maxEventPageSize {
+		limit = defaultEventPageSize
+	}
+	beforeID, _ := strconv.ParseInt(c.Query("before_id"), 10, 64)
+	return user_states.EventQuery{BeforeID: beforeID, Limit: limit}
+}
+
+// eventPage wraps events with the cursor for the next (older) page
+func eventPage(events []user_states.UserStateEvent) fiber.Map {
+	page := fiber.Map{"events": events}
+	if len(events) > 0 {
+		page["next_before_id"] = events[len(events)-1].ID
+	}
+	return page
+}
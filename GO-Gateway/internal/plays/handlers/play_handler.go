@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"guru-game/internal/auth/jwt"
+	"guru-game/internal/db/repository/plays"
+	playservice "guru-game/internal/plays"
+	"guru-game/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PlayHandlers holds the dependencies for play handlers
+type PlayHandlers struct {
+	Service *playservice.Service
+}
+
+// NewPlayHandlers creates a new PlayHandlers instance
+func NewPlayHandlers(service *playservice.Service) *PlayHandlers {
+	return &PlayHandlers{Service: service}
+}
+
+// HandleCreatePlay logs a play for the caller
+func (h *PlayHandlers) HandleCreatePlay(c *fiber.Ctx) error {
+	userID, ok := jwt.UserIDFromCtx(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var p models.Play
+	if err := c.BodyParser(&p); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	created, err := h.Service.Create(c.Context(), userID, &p)
+	if err != nil {
+		return playError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(created)
+}
+
+// HandleListPlays lists plays the caller logged or took part in: ?game_id=&before_id=&limit=
+func (h *PlayHandlers) HandleListPlays(c *fiber.Ctx) error {
+	userID, ok := jwt.UserIDFromCtx(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	beforeID, _ := strconv.ParseInt(c.Query("before_id"), 10, 64)
+	list, err := h.Service.List(c.Context(), plays.PlayQuery{
+		UserID:      userID,
+		BoardgameID: c.QueryInt("game_id"),
+		BeforeID:    beforeID,
+		Limit:       c.QueryInt("limit", playservice.DefaultPageSize),
+	})
+	if err != nil {
+		return playError(c, err)
+	}
+
+	page := fiber.Map{"plays": list}
+	if len(list) > 0 {
+		page["next_before_id"] = list[len(list)-1].ID
+	}
+	return c.JSON(page)
+}
+
+// HandleGetPlay returns a single play
+func (h *PlayHandlers) HandleGetPlay(c *fiber.Ctx) error {
+	userID, playID, ok := jwt.UserAndIDParam[int64](c, "id", "play")
+	if !ok {
+		return nil
+	}
+
+	p, err := h.Service.Get(c.Context(), userID, playID)
+	if err != nil {
+		return playError(c, err)
+	}
+	return c.JSON(p)
+}
+
+// HandleUpdatePlay replaces a play the caller logged
+func (h *PlayHandlers) HandleUpdatePlay(c *fiber.Ctx) error {
+	userID, playID, ok := jwt.UserAndIDParam[int64](c, "id", "play")
+	if !ok {
+		return nil
+	}
+
+	var p models.Play
+	if err := c.BodyParser(&p); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	updated, err := h.Service.Update(c.Context(), userID, playID, &p)
+	if err != nil {
+		return playError(c, err)
+	}
+	return c.JSON(updated)
+}
+
+// HandleDeletePlay deletes a play the caller logged
+func (h *PlayHandlers) HandleDeletePlay(c *fiber.Ctx) error {
+	userID, playID, ok := jwt.UserAndIDParam[int64](c, "id", "play")
+	if !ok {
+		return nil
+	}
+
+	if err := h.Service.Delete(c.Context(), userID, playID); err != nil {
+		return playError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandlePlayStats returns the caller's play statistics
+func (h *PlayHandlers) HandlePlayStats(c *fiber.Ctx) error {
+	userID, ok := jwt.UserIDFromCtx(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	stats, err := h.Service.Stats(c.Context(), userID)
+	if err != nil {
+		return playError(c, err)
+	}
+	return c.JSON(stats)
+}
+
+func playError(c *fiber.Ctx, err error) error {
+	var validationErr *playservice.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid play", "details": validationErr.Problems})
+	case errors.Is(err, plays.ErrUnknownPlayer), errors.Is(err, plays.ErrNotFriend):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, plays.ErrPlayNotFound), errors.Is(err, playservice.ErrNotVisible):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Play not found"})
+	case errors.Is(err, plays.ErrBoardgameNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Board game not found"})
+	case errors.Is(err, playservice.ErrNotOwner):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	default:
+		log.Println("Play operation failed ->", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Play operation failed"})
+	}
+}
@@ -0,0 +1,226 @@
+package plays
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"guru-game/internal/db/repository/plays"
+	"guru-game/models"
+)
+
+var (
+	// ErrNotOwner is returned when a user changes a play someone else logged
+	ErrNotOwner = errors.New("only the user who logged this play can change it")
+	// ErrNotVisible is returned when a user reads a play they neither logged nor took part in
+	ErrNotVisible = errors.New("you are not part of this play")
+)
+
+// Limits on play input
+const (
+	MaxPlayers         = 100
+	MaxDurationMinutes = 7 * 24 * 60
+	MaxLocationLength  = 200
+	MaxNotesLength     = 5000
+	MaxGuestNameLength = 100
+	maxFutureSkew      = 24 * time.Hour
+)
+
+// Page sizes for List
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// ValidationError reports invalid play input
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string { return strings.Join(e.Problems, "; ") }
+
+// Service manages logged plays and play statistics
+type Service struct {
+	repo plays.PlayRepository
+}
+
+// NewService creates a new plays Service
+func NewService(repo plays.PlayRepository) *Service {
+	return &Service{repo: repo}
+}
+
+// Create logs a play for userID
+func (s *Service) Create(ctx context.Context, userID int64, p *models.Play) (*models.Play, error) {
+	p.UserID = userID
+	if err := validate(p, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, p); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, p.ID)
+}
+
+// Get returns a play that userID logged or took part in
+func (s *Service) Get(ctx context.Context, userID, playID int64) (*models.Play, error) {
+	p, err := s.repo.GetByID(ctx, playID)
+	if err != nil {
+		return nil, err
+	}
+	if !canSee(p, userID) {
+		return nil, ErrNotVisible
+	}
+	return p, nil
+}
+
+// Update replaces a play logged by userID
+func (s *Service) Update(ctx context.Context, userID, playID int64, p *models.Play) (*models.Play, error) {
+	current, err := s.repo.GetByID(ctx, playID)
+	if err != nil {
+		return nil, err
+	}
+	if current.UserID != userID {
+		return nil, ErrNotOwner
+	}
+
+	p.ID, p.UserID = playID, userID
+	if err := validate(p, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, p); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, playID)
+}
+
+// Delete removes a play logged by userID
+func (s *Service) Delete(ctx context.Context, userID, playID int64) error {
+	current, err := s.repo.GetByID(ctx, playID)
+	if err != nil {
+		return err
+	}
+	if current.UserID != userID {
+		return ErrNotOwner
+	}
+	return s.repo.Delete(ctx, playID)
+}
+
+// List returns plays userID logged or took part in, newest first
+func (s *Service) List(ctx context.Context, q plays.PlayQuery) ([]models.Play, error) {
+	if q.Limit <= 0 || q.Limit > MaxPageSize {
+		q.Limit = DefaultPageSize
+	}
+	return s.repo.List(ctx, q)
+}
+
+// Stats returns play statistics for userID over the plays they took part in
+func (s *Service) Stats(ctx context.Context, userID int64) (*models.PlayStats, error) {
+	games, err := s.repo.GetGameStats(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.PlayStats{UserID: userID, GamesPlayed: len(games), Games: games}
+	counts := make([]int, len(games))
+	for i := range games {
+		g := &stats.Games[i]
+		g.WinRate = winRate(g.Wins, g.Plays)
+		stats.TotalPlays += g.Plays
+		stats.Wins += g.Wins
+		stats.TotalMinutes += g.TotalMinutes
+		counts[i] = g.Plays
+	}
+	stats.WinRate = winRate(stats.Wins, stats.TotalPlays)
+	stats.HIndex = hIndex(counts)
+	if stats.Games == nil {
+		stats.Games = []models.GamePlayStats{}
+	}
+
+	return stats, nil
+}
+
+func canSee(p *models.Play, userID int64) bool {
+	if p.UserID == userID {
+		return true
+	}
+	for _, pl := range p.Players {
+		if pl.UserID != nil && *pl.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func validate(p *models.Play, now time.Time) error {
+	var problems []string
+	if p.BoardgameID <= 0 {
+		problems = append(problems, "boardgame_id is required")
+	}
+	if p.PlayedAt.IsZero() {
+		problems = append(problems, "played_at is required")
+	} else if p.PlayedAt.After(now.Add(maxFutureSkew)) {
+		problems = append(problems, "played_at cannot be in the future")
+	}
+	if d := p.DurationMinutes; d != nil && (*d < 0 || *d > MaxDurationMinutes) {
+		problems = append(problems, fmt.Sprintf("duration_minutes must be between 0 and %d", MaxDurationMinutes))
+	}
+	p.Location = strings.TrimSpace(p.Location)
+	if utf8.RuneCountInString(p.Location) > MaxLocationLength {
+		problems = append(problems, fmt.Sprintf("location must be at most %d characters", MaxLocationLength))
+	}
+	if utf8.RuneCountInString(p.Notes) > MaxNotesLength {
+		problems = append(problems, fmt.Sprintf("notes must be at most %d characters", MaxNotesLength))
+	}
+	if len(p.Players) > MaxPlayers {
+		problems = append(problems, fmt.Sprintf("a play can have at most %d players", MaxPlayers))
+	}
+
+	seen := make(map[int64]bool)
+	for i := range p.Players {
+		pl := &p.Players[i]
+		pl.GuestName = strings.TrimSpace(pl.GuestName)
+		pl.Username = ""
+		switch {
+		case pl.UserID != nil && pl.GuestName != "":
+			problems = append(problems, fmt.Sprintf("players[%d]: set either user_id or guest_name, not both", i))
+		case pl.UserID == nil && pl.GuestName == "":
+			problems = append(problems, fmt.Sprintf("players[%d]: user_id or guest_name is required", i))
+		case pl.UserID != nil && seen[*pl.UserID]:
+			problems = append(problems, fmt.Sprintf("players[%d]: user %d is listed twice", i, *pl.UserID))
+		case pl.UserID != nil:
+			seen[*pl.UserID] = true
+		case utf8.RuneCountInString(pl.GuestName) > MaxGuestNameLength:
+			problems = append(problems, fmt.Sprintf("players[%d]: guest_name must be at most %d characters", i, MaxGuestNameLength))
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func winRate(wins, plays int) float64 {
+	if plays == 0 {
+		return 0
+	}
+	return float64(wins) / float64(plays)
+}
+
+// hIndex returns the largest h such that h games were played at least h times each
+func hIndex(counts []int) int {
+	sorted := append([]int(nil), counts...)
+	sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+	h := 0
+	for i, n := range sorted {
+		if n < i+1 {
+			break
+		}
+		h = i + 1
+	}
+	return h
+}
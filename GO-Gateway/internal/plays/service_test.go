@@ -0,0 +1,181 @@
+package plays
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"guru-game/internal/db/repository/plays"
+	"guru-game/models"
+)
+
+func TestHIndex(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts []int
+		want   int
+	}{
+		{"no games", nil, 0},
+		{"single play", []int{1}, 1},
+		{"unsorted", []int{1, 5, 3, 2, 4}, 3},
+		{"all high", []int{10, 10, 10}, 3},
+		{"all zero", []int{0, 0}, 0},
+		{"ties at the boundary", []int{2, 2, 2, 1}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts := append([]int(nil), tt.counts...)
+			if got := hIndex(counts); got != tt.want {
+				t.Errorf("hIndex(%v) = %d, want %d", tt.counts, got, tt.want)
+			}
+			for i := range counts {
+				if counts[i] != tt.counts[i] {
+					t.Fatalf("hIndex modified its input: %v", counts)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	id := func(v int64) *int64 { return &v }
+	minutes := func(v int) *int { return &v }
+	valid := func() models.Play {
+		return models.Play{BoardgameID: 7, PlayedAt: now.Add(-time.Hour)}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(p *models.Play)
+		want   []string
+	}{
+		{"valid", func(p *models.Play) {}, nil},
+		{"missing game and date", func(p *models.Play) {
+			p.BoardgameID, p.PlayedAt = 0, time.Time{}
+		}, []string{"boardgame_id is required", "played_at is required"}},
+		{"within clock skew", func(p *models.Play) { p.PlayedAt = now.Add(23 * time.Hour) }, nil},
+		{"future", func(p *models.Play) { p.PlayedAt = now.Add(25 * time.Hour) }, []string{"played_at cannot be in the future"}},
+		{"negative duration", func(p *models.Play) { p.DurationMinutes = minutes(-1) }, []string{"duration_minutes"}},
+		{"duration too long", func(p *models.Play) { p.DurationMinutes = minutes(MaxDurationMinutes + 1) }, []string{"duration_minutes"}},
+		{"location too long", func(p *models.Play) { p.Location = strings.Repeat("x", MaxLocationLength+1) }, []string{"location"}},
+		{"notes too long", func(p *models.Play) { p.Notes = strings.Repeat("x", MaxNotesLength+1) }, []string{"notes"}},
+		{"thai location at the limit", func(p *models.Play) { p.Location = strings.Repeat("บ", MaxLocationLength) }, nil},
+		{"thai location too long", func(p *models.Play) { p.Location = strings.Repeat("บ", MaxLocationLength+1) }, []string{"location"}},
+		{"thai notes at the limit", func(p *models.Play) { p.Notes = strings.Repeat("บ", MaxNotesLength) }, nil},
+		{"thai guest name at the limit", func(p *models.Play) {
+			p.Players = []models.PlayPlayer{{GuestName: strings.Repeat("บ", MaxGuestNameLength)}}
+		}, nil},
+		{"thai guest name too long", func(p *models.Play) {
+			p.Players = []models.PlayPlayer{{GuestName: strings.Repeat("บ", MaxGuestNameLength+1)}}
+		}, []string{"players[0]: guest_name"}},
+		{"too many players", func(p *models.Play) {
+			p.Players = make([]models.PlayPlayer, MaxPlayers+1)
+			for i := range p.Players {
+				p.Players[i].GuestName = "guest"
+			}
+		}, []string{"at most 100 players"}},
+		{"user and guest", func(p *models.Play) {
+			p.Players = []models.PlayPlayer{{UserID: id(1), GuestName: "Ann"}}
+		}, []string{"players[0]: set either user_id or guest_name"}},
+		{"neither user nor guest", func(p *models.Play) {
+			p.Players = []models.PlayPlayer{{GuestName: "   "}}
+		}, []string{"players[0]: user_id or guest_name is required"}},
+		{"duplicate user", func(p *models.Play) {
+			p.Players = []models.PlayPlayer{{UserID: id(1)}, {UserID: id(2)}, {UserID: id(1)}}
+		}, []string{"players[2]: user 1 is listed twice"}},
+		{"guest name too long", func(p *models.Play) {
+			p.Players = []models.PlayPlayer{{GuestName: strings.Repeat("x", MaxGuestNameLength+1)}}
+		}, []string{"players[0]: guest_name"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid()
+			tt.modify(&p)
+			err := validate(&p, now)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("validate() = %v, want nil", err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("validate() = %v, want *ValidationError", err)
+			}
+			if len(validationErr.Problems) != len(tt.want) {
+				t.Fatalf("problems = %q, want %d matching %q", validationErr.Problems, len(tt.want), tt.want)
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(validationErr.Problems[i], want) {
+					t.Errorf("problem %d = %q, want it to contain %q", i, validationErr.Problems[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateNormalizesInput(t *testing.T) {
+	p := models.Play{
+		BoardgameID: 7,
+		PlayedAt:    time.Now(),
+		Location:    "  Kitchen table ",
+		Players:     []models.PlayPlayer{{GuestName: " Ann ", Username: "spoofed"}},
+	}
+	if err := validate(&p, time.Now()); err != nil {
+		t.Fatalf("validate() = %v", err)
+	}
+	if p.Location != "Kitchen table" {
+		t.Errorf("Location = %q, want trimmed", p.Location)
+	}
+	if p.Players[0].GuestName != "Ann" || p.Players[0].Username != "" {
+		t.Errorf("player = %+v, want trimmed guest name and no username", p.Players[0])
+	}
+}
+
+// statsRepo serves fixed per-game stats; other PlayRepository methods are not used
+type statsRepo struct {
+	plays.PlayRepository
+	games []models.GamePlayStats
+}
+
+func (r statsRepo) GetGameStats(ctx context.Context, userID int64) ([]models.GamePlayStats, error) {
+	return r.games, nil
+}
+
+func TestStats(t *testing.T) {
+	tests := []struct {
+		name  string
+		games []models.GamePlayStats
+		want  models.PlayStats
+	}{
+		{"no plays", nil, models.PlayStats{UserID: 1, Games: []models.GamePlayStats{}}},
+		{"totals and win rates", []models.GamePlayStats{
+			{BoardgameID: 1, Plays: 4, Wins: 1, TotalMinutes: 200},
+			{BoardgameID: 2, Plays: 2, Wins: 2, TotalMinutes: 60},
+			{BoardgameID: 3, Plays: 1, TotalMinutes: 30},
+		}, models.PlayStats{
+			UserID: 1, TotalPlays: 7, GamesPlayed: 3, Wins: 3, WinRate: winRate(3, 7), TotalMinutes: 290, HIndex: 2,
+			Games: []models.GamePlayStats{
+				{BoardgameID: 1, Plays: 4, Wins: 1, WinRate: 0.25, TotalMinutes: 200},
+				{BoardgameID: 2, Plays: 2, Wins: 2, WinRate: 1, TotalMinutes: 60},
+				{BoardgameID: 3, Plays: 1, WinRate: 0, TotalMinutes: 30},
+			},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(statsRepo{games: tt.games})
+			got, err := s.Stats(context.Background(), 1)
+			if err != nil {
+				t.Fatalf("Stats() = %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Stats() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"guru-game/internal/auth/jwt"
+	"guru-game/internal/db/repository/polls"
+	"guru-game/internal/poll"
+	"guru-game/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PollHandlers holds the dependencies for poll handlers
+type PollHandlers struct {
+	Service *poll.Service
+}
+
+// NewPollHandlers creates a new PollHandlers instance
+func NewPollHandlers(service *poll.Service) *PollHandlers {
+	return &PollHandlers{Service: service}
+}
+
+type weightInput struct {
+	Weight *int `json:"weight"`
+}
+
+type playerCountInput struct {
+	Votes []models.PlayerCountVote `json:"votes"`
+}
+
+// HandleGetPollResults returns the aggregated weight and player count polls of a game
+func (h *PollHandlers) HandleGetPollResults(c *fiber.Ctx) error {
+	gameID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid board game ID"})
+	}
+
+	results, err := h.Service.Results(c.Context(), gameID)
+	if err != nil {
+		return pollError(c, err)
+	}
+	return c.JSON(results)
+}
+
+// HandleGetMyVotes returns the caller's votes for a game
+func (h *PollHandlers) HandleGetMyVotes(c *fiber.Ctx) error {
+	userID, gameID, ok := jwt.UserAndIDParam[int](c, "id", "board game")
+	if !ok {
+		return nil
+	}
+
+	votes, err := h.Service.Mine(c.Context(), userID, gameID)
+	if err != nil {
+		return pollError(c, err)
+	}
+	return c.JSON(votes)
+}
+
+// HandleVoteWeight records the caller's complexity vote: {"weight": 1-5}
+func (h *PollHandlers) HandleVoteWeight(c *fiber.Ctx) error {
+	userID, gameID, ok := jwt.UserAndIDParam[int](c, "id", "board game")
+	if !ok {
+		return nil
+	}
+
+	var in weightInput
+	if err := c.BodyParser(&in); err != nil || in.Weight == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "weight is required"})
+	}
+
+	results, err := h.Service.VoteWeight(c.Context(), userID, gameID, in.Weight)
+	if err != nil {
+		return pollError(c, err)
+	}
+	return c.JSON(results)
+}
+
+// HandleDeleteWeightVote removes the caller's complexity vote
+func (h *PollHandlers) HandleDeleteWeightVote(c *fiber.Ctx) error {
+	userID, gameID, ok := jwt.UserAndIDParam[int](c, "id", "board game")
+	if !ok {
+		return nil
+	}
+
+	results, err := h.Service.VoteWeight(c.Context(), userID, gameID, nil)
+	if err != nil {
+		return pollError(c, err)
+	}
+	return c.JSON(results)
+}
+
+// HandleVotePlayerCounts replaces the caller's player count votes:
+// {"votes": [{"player_count": 3, "vote": "best"}, ...]}
+func (h *PollHandlers) HandleVotePlayerCounts(c *fiber.Ctx) error {
+	userID, gameID, ok := jwt.UserAndIDParam[int](c, "id", "board game")
+	if !ok {
+		return nil
+	}
+
+	var in playerCountInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	results, err := h.Service.VotePlayerCounts(c.Context(), userID, gameID, in.Votes)
+	if err != nil {
+		return pollError(c, err)
+	}
+	return c.JSON(results)
+}
+
+func pollError(c *fiber.Ctx, err error) error {
+	var validationErr *poll.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": validationErr.Error()})
+	case errors.Is(err, polls.ErrBoardgameNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Board game not found"})
+	default:
+		log.Println("Poll operation failed ->", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Poll operation failed"})
+	}
+}
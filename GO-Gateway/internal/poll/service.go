@@ -0,0 +1,156 @@
+package poll
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"guru-game/internal/db/repository/boardgame"
+	"guru-game/internal/db/repository/polls"
+	"guru-game/models"
+)
+
+// Limits on poll input
+const (
+	MinWeight = 1
+	MaxWeight = 5
+)
+
+// ValidationError reports invalid poll input
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string { return e.Err.Error() }
+
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// StatsListener is told after a game's poll summary changed, e.g. to refresh the search index
+// It is called on the vote request, so slow work belongs in the background.
+type StatsListener interface {
+	PollStatsChanged(ctx context.Context, stats models.GamePollStats)
+}
+
+// Service manages the community weight and player count polls
+type Service struct {
+	repo       polls.PollRepository
+	boardgames boardgame.BoardGameRepository
+	listener   StatsListener
+}
+
+// NewService creates a new poll Service
+func NewService(repo polls.PollRepository, boardgames boardgame.BoardGameRepository, listener StatsListener) *Service {
+	return &Service{repo: repo, boardgames: boardgames, listener: listener}
+}
+
+// Results returns the aggregated polls of a game
+func (s *Service) Results(ctx context.Context, boardgameID int) (*models.GamePollResults, error) {
+	if _, err := s.game(boardgameID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetResults(ctx, boardgameID)
+}
+
+// Mine returns userID's votes for a game
+func (s *Service) Mine(ctx context.Context, userID int64, boardgameID int) (*models.UserPollVotes, error) {
+	return s.repo.GetUserVotes(ctx, userID, boardgameID)
+}
+
+// VoteWeight records userID's complexity vote for a game; nil removes it
+func (s *Service) VoteWeight(ctx context.Context, userID int64, boardgameID int, weight *int) (*models.GamePollResults, error) {
+	if weight != nil && (*weight < MinWeight || *weight > MaxWeight) {
+		return nil, &ValidationError{Err: fmt.Errorf("weight must be between %d and %d", MinWeight, MaxWeight)}
+	}
+	if err := s.repo.SetWeight(ctx, userID, boardgameID, weight); err != nil {
+		return nil, err
+	}
+	return s.changed(ctx, boardgameID)
+}
+
+// VotePlayerCounts replaces userID's player count votes for a game. Only counts the
+// game supports can be voted on, each at most once; an empty list removes the votes.
+func (s *Service) VotePlayerCounts(ctx context.Context, userID int64, boardgameID int, votes []models.PlayerCountVote) (*models.GamePollResults, error) {
+	bg, err := s.game(boardgameID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int]bool, len(votes))
+	for _, v := range votes {
+		if v.PlayerCount < bg.MinPlayers || v.PlayerCount > bg.MaxPlayers {
+			return nil, &ValidationError{Err: fmt.Errorf("player_count must be between %d and %d for this game", bg.MinPlayers, bg.MaxPlayers)}
+		}
+		if seen[v.PlayerCount] {
+			return nil, &ValidationError{Err: fmt.Errorf("player_count %d is voted on more than once", v.PlayerCount)}
+		}
+		seen[v.PlayerCount] = true
+		switch v.Vote {
+		case models.PlayerCountBest, models.PlayerCountRecommended, models.PlayerCountNotRecommended:
+		default:
+			return nil, &ValidationError{Err: fmt.Errorf("vote must be one of %s, %s, %s",
+				models.PlayerCountBest, models.PlayerCountRecommended, models.PlayerCountNotRecommended)}
+		}
+	}
+
+	if err := s.repo.SetPlayerCountVotes(ctx, userID, boardgameID, votes); err != nil {
+		return nil, err
+	}
+	return s.changed(ctx, boardgameID)
+}
+
+// Stats returns the poll summaries of the given games, or of every voted game when ids is nil
+func (s *Service) Stats(ctx context.Context, ids []int) (map[int]models.GamePollStats, error) {
+	return s.repo.GetStats(ctx, ids)
+}
+
+// changed returns the new results of a game after a vote and passes its summary to the listener
+func (s *Service) changed(ctx context.Context, boardgameID int) (*models.GamePollResults, error) {
+	results, err := s.repo.GetResults(ctx, boardgameID)
+	if err != nil {
+		return nil, err
+	}
+	if s.listener != nil {
+		s.listener.PollStatsChanged(ctx, models.GamePollStats{
+			BoardgameID:             boardgameID,
+			WeightAvg:               results.WeightAvg,
+			BestPlayerCounts:        results.BestPlayerCounts,
+			RecommendedPlayerCounts: results.RecommendedPlayerCounts,
+		})
+	}
+	return results, nil
+}
+
+func (s *Service) game(boardgameID int) (*models.BoardGame, error) {
+	bg, err := s.boardgames.GetByID(boardgameID)
+	if err != nil {
+		if errors.Is(err, boardgame.ErrNotFound) {
+			return nil, polls.ErrBoardgameNotFound
+		}
+		return nil, err
+	}
+	return bg, nil
+}
+
+// Filter narrows search results by poll results. Zero values are ignored, and games
+// without votes never match a set filter. The search service applies it before paging.
+type Filter struct {
+	MinWeight       float64
+	MaxWeight       float64
+	BestPlayerCount int
+}
+
+// Validate checks that the set bounds are on the weight scale and in order
+func (f Filter) Validate() error {
+	for _, w := range []float64{f.MinWeight, f.MaxWeight} {
+		if w != 0 && (w < MinWeight || w > MaxWeight) {
+			return &ValidationError{Err: fmt.Errorf("weight filters must be between %d and %d", MinWeight, MaxWeight)}
+		}
+	}
+	if f.MinWeight > 0 && f.MaxWeight > 0 && f.MinWeight > f.MaxWeight {
+		return &ValidationError{Err: errors.New("minWeight cannot be greater than maxWeight")}
+	}
+	if f.BestPlayerCount < 0 {
+		return &ValidationError{Err: errors.New("bestAt must be a positive player count")}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package poll
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFilterValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  Filter
+		wantErr bool
+	}{
+		{"empty", Filter{}, false},
+		{"full range", Filter{MinWeight: 1, MaxWeight: 5, BestPlayerCount: 4}, false},
+		{"equal bounds", Filter{MinWeight: 2.5, MaxWeight: 2.5}, false},
+		{"min only", Filter{MinWeight: 3.2}, false},
+		{"min below scale", Filter{MinWeight: 0.5}, true},
+		{"max above scale", Filter{MaxWeight: 5.5}, true},
+		{"negative max", Filter{MaxWeight: -1}, true},
+		{"min above max", Filter{MinWeight: 4, MaxWeight: 2}, true},
+		{"negative player count", Filter{BestPlayerCount: -2}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+			var validationErr *ValidationError
+			if err != nil && !errors.As(err, &validationErr) {
+				t.Errorf("Validate() = %T, want *ValidationError", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package recommendation
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Circuit breaker states
+const (
+	circuitClosed = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitBreaker stops calls to the ML service after threshold consecutive failures.
+// Once cooldown has passed a single probe call is let through (half-open): if it
+// succeeds the circuit closes, otherwise it opens for another cooldown.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    int
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// allow reports whether a call may go out. A true result must be followed by record.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = circuitHalfOpen
+		log.Println("🔌 Recommendation service circuit half-open, probing")
+		fallthrough
+	case circuitHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+	}
+	return true
+}
+
+// record reports the outcome of an allowed call. Only failures that mean the service is
+// unavailable should be recorded as failures; a rejected request is still a live service.
+func (b *circuitBreaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == circuitHalfOpen {
+		b.probing = false
+		if failed {
+			b.state, b.openedAt = circuitOpen, b.now()
+			log.Printf("🔌 Recommendation service probe failed, circuit open for %v", b.cooldown)
+			return
+		}
+		b.state, b.failures = circuitClosed, 0
+		log.Println("🔌 Recommendation service recovered, circuit closed")
+		return
+	}
+
+	if !failed {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == circuitClosed && b.failures >= b.threshold {
+		b.state, b.openedAt = circuitOpen, b.now()
+		log.Printf("🔌 Recommendation service failed %d times in a row, circuit open for %v", b.failures, b.cooldown)
+	}
+}
@@ -0,0 +1,95 @@
+package recommendation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	const cooldown = 30 * time.Second
+
+	// A step either asks allow (and expects want) and, when allowed, records the outcome,
+	// or only moves the clock forward
+	type step struct {
+		advance time.Duration
+		want    bool
+		failed  bool
+		record  bool
+	}
+	call := func(want, failed bool) step { return step{want: want, failed: failed, record: want} }
+	wait := func(d time.Duration) step { return step{advance: d} }
+
+	tests := []struct {
+		name      string
+		threshold int
+		steps     []step
+		wantState int
+	}{
+		{"stays closed below threshold", 3, []step{
+			call(true, true), call(true, true), call(true, false), call(true, true), call(true, true),
+		}, circuitClosed},
+		{"opens at threshold", 2, []step{
+			call(true, true), call(true, true), call(false, false),
+		}, circuitOpen},
+		{"stays open during cooldown", 1, []step{
+			call(true, true), wait(cooldown - time.Second), call(false, false),
+		}, circuitOpen},
+		{"successful probe closes", 1, []step{
+			call(true, true), wait(cooldown), call(true, false), call(true, false),
+		}, circuitClosed},
+		{"failed probe reopens", 1, []step{
+			call(true, true), wait(cooldown), call(true, true), call(false, false),
+			wait(cooldown - time.Second), call(false, false),
+		}, circuitOpen},
+		{"reopened circuit probes again after cooldown", 1, []step{
+			call(true, true), wait(cooldown), call(true, true), wait(cooldown), call(true, false),
+		}, circuitClosed},
+		{"closing resets the failure count", 2, []step{
+			call(true, true), call(true, true), wait(cooldown), call(true, false), call(true, true), call(true, false),
+		}, circuitClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+			b := newCircuitBreaker(tt.threshold, cooldown)
+			b.now = func() time.Time { return clock }
+
+			for i, s := range tt.steps {
+				if s.advance > 0 {
+					clock = clock.Add(s.advance)
+					continue
+				}
+				if got := b.allow(); got != s.want {
+					t.Fatalf("step %d: allow() = %v, want %v", i, got, s.want)
+				}
+				if s.record {
+					b.record(s.failed)
+				}
+			}
+			if b.state != tt.wantState {
+				t.Errorf("state = %d, want %d", b.state, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerSingleProbe(t *testing.T) {
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := newCircuitBreaker(1, time.Second)
+	b.now = func() time.Time { return clock }
+
+	b.allow()
+	b.record(true)
+	clock = clock.Add(time.Second)
+
+	if !b.allow() {
+		t.Fatal("first call after cooldown was not let through as a probe")
+	}
+	if b.allow() {
+		t.Fatal("a second call was let through while the probe is in flight")
+	}
+	b.record(false)
+	if !b.allow() {
+		t.Fatal("calls are still rejected after the probe succeeded")
+	}
+}
@@ -0,0 +1,219 @@
+package recommendation
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recommendation cache defaults, overridable with RECO_CACHE_* environment variables
+const (
+	defaultRecoCacheTTL        = 10 * time.Minute
+	defaultRecoPopularCacheTTL = 5 * time.Minute
+	defaultRecoCacheMaxBytes   = 64 << 20
+	cacheKeyPrefix             = "reco:"
+	cacheGenerationKey         = cacheKeyPrefix + "generation"
+)
+
+// CacheStore is the key/value store behind Cache. Get returns nil, nil on a miss. A key set
+// with a ttl of 0 never expires and must not be evicted, as it holds the cache generation.
+type CacheStore interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// cachedList is a stored recommendation list. Limit is how many games were asked for;
+// a shorter list means that was everything, so it also answers larger requests.
+type cachedList struct {
+	Generation int64       `json:"generation"`
+	Limit      int         `json:"limit"`
+	Boardgames []Boardgame `json:"boardgames"`
+}
+
+// Cache keeps per-user and popular recommendation lists. A catalogue sync bumps a
+// generation number stored next to the lists, which retires every list at once without
+// having to enumerate keys. The cache is best effort: store errors are logged and
+// treated as misses.
+type Cache struct {
+	store      CacheStore
+	ttl        time.Duration
+	popularTTL time.Duration
+}
+
+// NewCache creates a Cache over store
+func NewCache(store CacheStore) *Cache {
+	return &Cache{
+		store:      store,
+		ttl:        envDuration("RECO_CACHE_TTL", defaultRecoCacheTTL),
+		popularTTL: envDuration("RECO_POPULAR_CACHE_TTL", defaultRecoPopularCacheTTL),
+	}
+}
+
+// NewCacheFromEnv creates a Cache backed by the Redis-compatible server at
+// RECO_CACHE_REDIS_ADDR, or by an in-memory LRU of at most RECO_CACHE_MAX_BYTES when it is
+// unset
+func NewCacheFromEnv() *Cache {
+	addr := os.Getenv("RECO_CACHE_REDIS_ADDR")
+	if addr == "" {
+		log.Println("🗃️ Recommendation cache: in-memory LRU")
+		return NewCache(NewLRUStore(envInt("RECO_CACHE_MAX_BYTES", defaultRecoCacheMaxBytes)))
+	}
+	log.Printf("🗃️ Recommendation cache: Redis at %s", addr)
+	return NewCache(NewRedisStore(addr, os.Getenv("RECO_CACHE_REDIS_PASSWORD"), envInt("RECO_CACHE_REDIS_DB", 0)))
+}
+
+// UserStateChanged drops the user's cached recommendations
+func (c *Cache) UserStateChanged(ctx context.Context, userID int) {
+	c.InvalidateUser(ctx, strconv.Itoa(userID))
+}
+
+// InvalidateUser drops the user's cached recommendations
+func (c *Cache) InvalidateUser(ctx context.Context, userID string) {
+	if err := c.store.Delete(ctx, userKey(userID)); err != nil {
+		log.Printf("⚠️ Could not invalidate cached recommendations for user %s: %v", userID, err)
+	}
+}
+
+// InvalidateAll retires every cached list, for when the catalogue changed
+func (c *Cache) InvalidateAll(ctx context.Context) {
+	generation := strconv.FormatInt(time.Now().UnixNano(), 10)
+	if err := c.store.Set(ctx, cacheGenerationKey, []byte(generation), 0); err != nil {
+		log.Printf("⚠️ Could not invalidate cached recommendations: %v", err)
+	}
+}
+
+// get returns the first limit games of the list under key, if a current one covers limit.
+// A limit below 1 is never served from the cache.
+func (c *Cache) get(ctx context.Context, key string, limit int) ([]Boardgame, bool) {
+	if limit < 1 {
+		return nil, false
+	}
+	raw, err := c.store.Get(ctx, key)
+	if err != nil {
+		log.Printf("⚠️ Recommendation cache read failed: %v", err)
+		return nil, false
+	}
+	if raw == nil {
+		return nil, false
+	}
+
+	var list cachedList
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, false
+	}
+	generation, ok := c.generation(ctx)
+	if !ok || list.Generation != generation {
+		return nil, false
+	}
+	if list.Limit < limit && len(list.Boardgames) >= list.Limit {
+		return nil, false
+	}
+	if len(list.Boardgames) > limit {
+		return list.Boardgames[:limit], true
+	}
+	return list.Boardgames, true
+}
+
+func (c *Cache) set(ctx context.Context, key string, limit int, boardgames []Boardgame, ttl time.Duration) {
+	if limit < 1 {
+		return
+	}
+	generation, ok := c.generation(ctx)
+	if !ok {
+		return
+	}
+	raw, err := json.Marshal(cachedList{Generation: generation, Limit: limit, Boardgames: boardgames})
+	if err != nil {
+		return
+	}
+	if err := c.store.Set(ctx, key, raw, ttl); err != nil {
+		log.Printf("⚠️ Recommendation cache write failed: %v", err)
+	}
+}
+
+// generation returns the current catalogue generation; 0 until the first sync
+func (c *Cache) generation(ctx context.Context) (int64, bool) {
+	raw, err := c.store.Get(ctx, cacheGenerationKey)
+	if err != nil {
+		log.Printf("⚠️ Recommendation cache read failed: %v", err)
+		return 0, false
+	}
+	if raw == nil {
+		return 0, true
+	}
+	generation, err := strconv.ParseInt(string(raw), 10, 64)
+	return generation, err == nil
+}
+
+// userKey returns the key of the user's list. Numeric IDs are normalised so "007" and "7"
+// share the list that InvalidateUser drops.
+func userKey(userID string) string {
+	if id, err := strconv.ParseInt(strings.TrimSpace(userID), 10, 64); err == nil {
+		userID = strconv.FormatInt(id, 10)
+	}
+	return cacheKeyPrefix + "user:" + userID
+}
+
+const popularKey = cacheKeyPrefix + "popular"
+
+// CachingRecommendationClient serves recommendation and popular lists from a Cache and
+// keeps it fresh: a delivered user action drops that user's list, and a catalogue sync
+// drops them all. Other calls pass straight through.
+type CachingRecommendationClient struct {
+	RecommendationClient
+	cache *Cache
+}
+
+// NewCachingRecommendationClient wraps next with cache
+func NewCachingRecommendationClient(next RecommendationClient, cache *Cache) *CachingRecommendationClient {
+	return &CachingRecommendationClient{RecommendationClient: next, cache: cache}
+}
+
+func (c *CachingRecommendationClient) GetRecommendations(ctx context.Context, userID string, limit int) ([]Boardgame, error) {
+	key := userKey(userID)
+	if boardgames, ok := c.cache.get(ctx, key, limit); ok {
+		return boardgames, nil
+	}
+	boardgames, err := c.RecommendationClient.GetRecommendations(ctx, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.set(ctx, key, limit, boardgames, c.cache.ttl)
+	return boardgames, nil
+}
+
+func (c *CachingRecommendationClient) GetPopularBoardgames(ctx context.Context, limit int) ([]Boardgame, error) {
+	if boardgames, ok := c.cache.get(ctx, popularKey, limit); ok {
+		return boardgames, nil
+	}
+	boardgames, err := c.RecommendationClient.GetPopularBoardgames(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.set(ctx, popularKey, limit, boardgames, c.cache.popularTTL)
+	return boardgames, nil
+}
+
+// SendUserAction forwards the action and drops the user's list, which the ML service
+// will now rank differently
+func (c *CachingRecommendationClient) SendUserAction(ctx context.Context, action UserAction) error {
+	if err := c.RecommendationClient.SendUserAction(ctx, action); err != nil {
+		return err
+	}
+	c.cache.InvalidateUser(ctx, action.UserID)
+	return nil
+}
+
+// SendAllBoardgames forwards the catalogue and retires every cached list
+func (c *CachingRecommendationClient) SendAllBoardgames(ctx context.Context, boardgames []Boardgame) error {
+	if err := c.RecommendationClient.SendAllBoardgames(ctx, boardgames); err != nil {
+		return err
+	}
+	c.cache.InvalidateAll(ctx)
+	return nil
+}
@@ -0,0 +1,105 @@
+package recommendation
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// LRUStore is an in-memory CacheStore holding at most maxBytes of expiring keys and their
+// values; the least recently used ones are evicted first. A value that alone exceeds
+// maxBytes is not stored. Keys set without a TTL are never evicted and not counted.
+type LRUStore struct {
+	maxBytes int
+	now      func() time.Time
+
+	mu      sync.Mutex
+	size    int        // bytes held by entries
+	order   *list.List // front is most recently used
+	entries map[string]*list.Element
+	pinned  map[string][]byte
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+func (e *lruEntry) size() int { return len(e.key) + len(e.value) }
+
+// NewLRUStore creates an LRUStore
+func NewLRUStore(maxBytes int) *LRUStore {
+	return &LRUStore{
+		maxBytes: maxBytes,
+		now:      time.Now,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+		pinned:   make(map[string][]byte),
+	}
+}
+
+func (s *LRUStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if value, ok := s.pinned[key]; ok {
+		return value, nil
+	}
+	el, ok := s.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	entry := el.Value.(*lruEntry)
+	if s.now().After(entry.expiresAt) {
+		s.removeElement(el)
+		return nil, nil
+	}
+	s.order.MoveToFront(el)
+	return entry.value, nil
+}
+
+func (s *LRUStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(key)
+	if ttl <= 0 {
+		s.pinned[key] = value
+		return nil
+	}
+
+	entry := &lruEntry{key: key, value: value, expiresAt: s.now().Add(ttl)}
+	if entry.size() > s.maxBytes {
+		return nil
+	}
+	s.entries[key] = s.order.PushFront(entry)
+	s.size += entry.size()
+	for s.size > s.maxBytes {
+		s.removeElement(s.order.Back())
+	}
+	return nil
+}
+
+func (s *LRUStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(key)
+	return nil
+}
+
+func (s *LRUStore) remove(key string) {
+	delete(s.pinned, key)
+	if el, ok := s.entries[key]; ok {
+		s.removeElement(el)
+	}
+}
+
+func (s *LRUStore) removeElement(el *list.Element) {
+	entry := el.Value.(*lruEntry)
+	s.order.Remove(el)
+	delete(s.entries, entry.key)
+	s.size -= entry.size()
+}
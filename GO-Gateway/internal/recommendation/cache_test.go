@@ -0,0 +1,164 @@
+package recommendation
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLRUStore(t *testing.T) {
+	// Each key is one byte and each value nine, so an entry takes 10 bytes
+	value := func(c byte) []byte { return []byte(strings.Repeat(string(c), 9)) }
+
+	// An op sets key (with ttl, 0 pins it), gets it, deletes it or moves the clock forward
+	type op struct {
+		kind    string
+		key     string
+		ttl     time.Duration
+		advance time.Duration
+	}
+	set := func(key string) op { return op{kind: "set", key: key, ttl: time.Minute} }
+	pin := func(key string) op { return op{kind: "set", key: key} }
+	get := func(key string) op { return op{kind: "get", key: key} }
+	del := func(key string) op { return op{kind: "delete", key: key} }
+	wait := func(d time.Duration) op { return op{kind: "wait", advance: d} }
+
+	tests := []struct {
+		name     string
+		maxBytes int
+		ops      []op
+		want     []string // keys still held
+		gone     []string // keys evicted or expired
+		wantSize int
+	}{
+		{"holds entries within the budget", 30, []op{set("a"), set("b"), set("c")}, []string{"a", "b", "c"}, nil, 30},
+		{"evicts the least recently set", 30, []op{set("a"), set("b"), set("c"), set("d")}, []string{"b", "c", "d"}, []string{"a"}, 30},
+		{"get refreshes recency", 30, []op{set("a"), set("b"), set("c"), get("a"), set("d")}, []string{"a", "c", "d"}, []string{"b"}, 30},
+		{"overwrite does not double count", 30, []op{set("a"), set("a"), set("a")}, []string{"a"}, nil, 10},
+		{"pinned keys are not counted or evicted", 20, []op{pin("g"), set("a"), set("b"), set("c")}, []string{"g", "b", "c"}, []string{"a"}, 20},
+		{"value over the budget is not stored", 5, []op{set("a")}, nil, []string{"a"}, 0},
+		{"delete frees its bytes", 30, []op{set("a"), set("b"), del("a")}, []string{"b"}, []string{"a"}, 10},
+		{"expired entry is dropped", 30, []op{set("a"), wait(2 * time.Minute), set("b")}, []string{"b"}, []string{"a"}, 10},
+		{"pinned key outlives any ttl", 30, []op{pin("g"), wait(24 * time.Hour)}, []string{"g"}, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+			s := NewLRUStore(tt.maxBytes)
+			s.now = func() time.Time { return now }
+
+			for _, o := range tt.ops {
+				switch o.kind {
+				case "set":
+					s.Set(ctx, o.key, value(o.key[0]), o.ttl)
+				case "get":
+					s.Get(ctx, o.key)
+				case "delete":
+					s.Delete(ctx, o.key)
+				case "wait":
+					now = now.Add(o.advance)
+				}
+			}
+
+			for _, key := range tt.want {
+				if got, _ := s.Get(ctx, key); string(got) != string(value(key[0])) {
+					t.Errorf("Get(%q) = %q, want it held", key, got)
+				}
+			}
+			for _, key := range tt.gone {
+				if got, _ := s.Get(ctx, key); got != nil {
+					t.Errorf("Get(%q) = %q, want nil", key, got)
+				}
+			}
+			if s.size != tt.wantSize {
+				t.Errorf("size = %d, want %d", s.size, tt.wantSize)
+			}
+		})
+	}
+}
+
+func TestCacheGet(t *testing.T) {
+	games := func(n int) []Boardgame {
+		boardgames := make([]Boardgame, n)
+		for i := range boardgames {
+			boardgames[i].ID = i + 1
+		}
+		return boardgames
+	}
+
+	tests := []struct {
+		name       string
+		storedAt   int // limit the list was fetched with
+		stored     int // games the service returned
+		invalidate bool
+		limit      int
+		want       int
+		wantHit    bool
+	}{
+		{"same limit", 10, 10, false, 10, 10, true},
+		{"smaller limit is cut", 10, 10, false, 3, 3, true},
+		{"larger limit misses a full list", 10, 10, false, 20, 0, false},
+		{"short list answers any limit", 10, 4, false, 20, 4, true},
+		{"short list cut to a smaller limit", 10, 4, false, 2, 2, true},
+		{"empty list is a hit", 10, 0, false, 5, 0, true},
+		{"catalogue sync retires the list", 10, 10, true, 5, 0, false},
+		{"zero limit is a miss", 10, 10, false, 0, 0, false},
+		{"negative limit is a miss", 10, 10, false, -1, 0, false},
+		{"list fetched with a negative limit is not stored", -1, 10, false, 5, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := &Cache{store: NewLRUStore(1 << 20), ttl: time.Minute}
+			c.set(ctx, popularKey, tt.storedAt, games(tt.stored), c.ttl)
+			if tt.invalidate {
+				c.InvalidateAll(ctx)
+			}
+
+			got, ok := c.get(ctx, popularKey, tt.limit)
+			if ok != tt.wantHit {
+				t.Fatalf("get(limit %d) hit = %v, want %v", tt.limit, ok, tt.wantHit)
+			}
+			if len(got) != tt.want {
+				t.Fatalf("get(limit %d) returned %d games, want %d", tt.limit, len(got), tt.want)
+			}
+			for i, bg := range got {
+				if bg.ID != i+1 {
+					t.Fatalf("game %d has ID %d, want the ranking kept", i, bg.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestCacheGetMiss(t *testing.T) {
+	ctx := context.Background()
+	c := &Cache{store: NewLRUStore(1 << 20), ttl: time.Minute}
+	if _, ok := c.get(ctx, popularKey, 5); ok {
+		t.Error("get() on an empty cache hit")
+	}
+	c.store.Set(ctx, popularKey, []byte("not json"), time.Minute)
+	if _, ok := c.get(ctx, popularKey, 5); ok {
+		t.Error("get() on a corrupt entry hit")
+	}
+}
+
+func TestUserKey(t *testing.T) {
+	tests := []struct {
+		userID string
+		want   string
+	}{
+		{"7", "reco:user:7"},
+		{"007", "reco:user:7"},
+		{" 7 ", "reco:user:7"},
+		{"+7", "reco:user:7"},
+		{"guest-1", "reco:user:guest-1"},
+	}
+	for _, tt := range tests {
+		if got := userKey(tt.userID); got != tt.want {
+			t.Errorf("userKey(%q) = %q, want %q", tt.userID, got, tt.want)
+		}
+	}
+}
@@ -1,275 +1,349 @@
 package recommendation
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
 
-	"github.com/gofiber/fiber/v2"
+	"guru-game/models"
 )
 
+// REST client defaults, overridable with RECO_* environment variables
+const (
+	defaultRecoTimeout          = 5 * time.Second
+	defaultRecoBulkTimeout      = 2 * time.Minute
+	defaultRecoMaxRetries       = 2
+	defaultRecoBreakerThreshold = 5
+	defaultRecoBreakerCooldown  = 30 * time.Second
+	recoBaseRetryDelay          = 200 * time.Millisecond
+	recoMaxRetryDelay           = 2 * time.Second
+)
+
+// RESTRecommendationClient talks to the Python ML service. Every call has a timeout;
+// idempotent calls are retried with jittered backoff when the service is unavailable, and
+// a circuit breaker fails calls fast while the service keeps failing. Failures are returned
+// as *UpstreamError.
 type RESTRecommendationClient struct {
-	baseURL string
-	app     *fiber.App
+	baseURL     string
+	httpClient  *http.Client
+	timeout     time.Duration
+	bulkTimeout time.Duration
+	maxRetries  int
+	breaker     *circuitBreaker
+
+	pollMu      sync.Mutex
+	pollPending map[int]*models.GamePollStats // games with a push in flight, and their next summary
 }
 
 func NewRESTRecommendationClient(baseURL string) *RESTRecommendationClient {
 	return &RESTRecommendationClient{
-		baseURL: baseURL,
-		app:     fiber.New(),
+		baseURL:     baseURL,
+		httpClient:  &http.Client{},
+		timeout:     envDuration("RECO_TIMEOUT", defaultRecoTimeout),
+		bulkTimeout: envDuration("RECO_BULK_TIMEOUT", defaultRecoBulkTimeout),
+		maxRetries:  envInt("RECO_MAX_RETRIES", defaultRecoMaxRetries),
+		breaker: newCircuitBreaker(
+			envInt("RECO_BREAKER_THRESHOLD", defaultRecoBreakerThreshold),
+			envDuration("RECO_BREAKER_COOLDOWN", defaultRecoBreakerCooldown),
+		),
 	}
 }
 
-func (c *RESTRecommendationClient) SendUserAction(action UserAction) error {
-	url := fmt.Sprintf("%s/api/actions", c.baseURL)
-	jsonData, err := json.Marshal(action)
-	if err != nil {
-		return fmt.Errorf("failed to marshal user action: %v", err)
-	}
-
-	agent := fiber.AcquireAgent()
-	defer fiber.ReleaseAgent(agent)
-
-	req := agent.Request()
-	req.SetRequestURI(url)
-	req.Header.SetMethod(fiber.MethodPost)
-	req.Header.SetContentType("application/json")
-	req.SetBody(jsonData)
-
-	if err := agent.Parse(); err != nil {
-		return fmt.Errorf("failed to parse request: %v", err)
-	}
+// call describes one request to the ML service
+type call struct {
+	op         string // error message prefix, e.g. "failed to get recommendations"
+	method     string
+	path       string
+	body       interface{}
+	idempotent bool
+	timeout    time.Duration
+}
 
-	code, body, errs := agent.Bytes()
-	if len(errs) > 0 {
-		return fmt.Errorf("failed to send user action: %v", errs[0])
-	}
+// do sends the request, retrying idempotent calls, and decodes a 200 response into out
+// when out is not nil
+func (c *RESTRecommendationClient) do(ctx context.Context, r call, out interface{}) error {
+	var payload []byte
+	if r.body != nil {
+		var err error
+		if payload, err = json.Marshal(r.body); err != nil {
+			return fmt.Errorf("%s: failed to marshal request: %w", r.op, err)
+		}
+	}
+	if r.timeout == 0 {
+		r.timeout = c.timeout
+	}
+
+	attempts := 1
+	if r.idempotent {
+		attempts += c.maxRetries
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			timer := time.NewTimer(backoff(recoBaseRetryDelay, attempt-1, recoMaxRetryDelay))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return fmt.Errorf("%s: %w", r.op, ctx.Err())
+			case <-timer.C:
+			}
+		}
+
+		var body []byte
+		body, err = c.attempt(ctx, r, payload)
+		if err == nil {
+			if out == nil {
+				return nil
+			}
+			if err := json.Unmarshal(body, out); err != nil {
+				return fmt.Errorf("%s: failed to decode response: %w", r.op, err)
+			}
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return fmt.Errorf("%s: %w", r.op, ctx.Err())
+		}
+		var upstreamErr *UpstreamError
+		if errors.Is(err, ErrCircuitOpen) || !errors.As(err, &upstreamErr) || !upstreamErr.Unavailable() {
+			return err
+		}
+	}
+	return err
+}
 
-	if code != fiber.StatusOK {
-		return fmt.Errorf("failed to send user action: status %d, body: %s", code, string(body))
+// attempt makes a single request through the circuit breaker and returns the body of a
+// 200 response
+func (c *RESTRecommendationClient) attempt(ctx context.Context, r call, payload []byte) ([]byte, error) {
+	if !c.breaker.allow() {
+		return nil, &UpstreamError{Op: r.op, Err: ErrCircuitOpen}
 	}
 
-	return nil
+	body, err := c.send(ctx, r, payload)
+	if err != nil {
+		var upstreamErr *UpstreamError
+		// A caller that went away says nothing about the service's health
+		failed := ctx.Err() == nil && errors.As(err, &upstreamErr) && upstreamErr.Unavailable()
+		c.breaker.record(failed)
+		return nil, err
+	}
+	c.breaker.record(false)
+	return body, nil
 }
 
-func (c *RESTRecommendationClient) GetRecommendations(userID string, limit int) ([]Boardgame, error) {
-	url := fmt.Sprintf("%s/recommendations", c.baseURL)
-	reqBody := map[string]interface{}{
-		"user_id":            userID,
-		"limit":              limit,
-		"include_categories": true,
+func (c *RESTRecommendationClient) send(ctx context.Context, r call, payload []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
 	}
-	jsonData, err := json.Marshal(reqBody)
+	req, err := http.NewRequestWithContext(ctx, r.method, c.baseURL+r.path, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %v", err)
+		return nil, fmt.Errorf("%s: failed to build request: %w", r.op, err)
 	}
-
-	log.Printf("📡 URL: %s", url)
-
-	agent := fiber.AcquireAgent()
-	defer fiber.ReleaseAgent(agent)
-
-	req := agent.Request()
-	req.SetRequestURI(url)
-	req.Header.SetMethod(fiber.MethodPost)
-	req.Header.SetContentType("application/json")
-	req.SetBody(jsonData)
-
-	if err := agent.Parse(); err != nil {
-		return nil, fmt.Errorf("failed to parse request: %v", err)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
-
-	code, body, errs := agent.Bytes()
-	if len(errs) > 0 {
-		return nil, fmt.Errorf("failed to get recommendations: %v", errs[0])
+	if id := RequestIDFromContext(ctx); id != "" {
+		req.Header.Set(HeaderRequestID, id)
 	}
 
-	// Log response data in a more readable format
-	log.Printf("📥 ===== API Response from Python ML Service =====")
-	log.Printf("📡 Status Code: %d", code)
-
-	if code != fiber.StatusOK {
-		return nil, fmt.Errorf("failed to get recommendations: status %d, body: %s", code, string(body))
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &UpstreamError{Op: r.op, Err: err}
 	}
+	defer resp.Body.Close()
 
-	var response struct {
-		Boardgames []Boardgame `json:"boardgames"`
-		Categories []string    `json:"categories"`
-	}
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %v", err)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &UpstreamError{Op: r.op, Err: fmt.Errorf("failed to read response: %w", err)}
 	}
-
-	if len(response.Categories) > 0 {
-		log.Printf("📋 Categories: %v", response.Categories)
+	if resp.StatusCode != http.StatusOK {
+		if len(body) > maxErrorBody {
+			body = body[:maxErrorBody]
+		}
+		return nil, &UpstreamError{Op: r.op, StatusCode: resp.StatusCode, Body: string(body)}
 	}
+	return body, nil
+}
 
-	return response.Boardgames, nil
+func (c *RESTRecommendationClient) SendUserAction(ctx context.Context, action UserAction) error {
+	return c.do(ctx, call{
+		op:     "failed to send user action",
+		method: http.MethodPost,
+		path:   "/api/actions",
+		body:   action,
+	}, nil)
 }
 
-func (c *RESTRecommendationClient) SendAllBoardgames(boardgames []Boardgame) error {
-	url := fmt.Sprintf("%s/api/boardgames", c.baseURL)
-	reqBody := map[string][]Boardgame{
-		"boardgames": boardgames,
+func (c *RESTRecommendationClient) GetRecommendations(ctx context.Context, userID string, limit int) ([]Boardgame, error) {
+	var response struct {
+		Boardgames []Boardgame `json:"boardgames"`
+		Categories []string    `json:"categories"`
 	}
-	jsonData, err := json.Marshal(reqBody)
+	err := c.do(ctx, call{
+		op:     "failed to get recommendations",
+		method: http.MethodPost,
+		path:   "/recommendations",
+		body: map[string]interface{}{
+			"user_id":            userID,
+			"limit":              limit,
+			"include_categories": true,
+		},
+		// Asking for recommendations changes nothing, so it is safe to repeat
+		idempotent: true,
+	}, &response)
 	if err != nil {
-		return fmt.Errorf("failed to marshal boardgames: %v", err)
-	}
-
-	agent := fiber.AcquireAgent()
-	defer fiber.ReleaseAgent(agent)
-
-	req := agent.Request()
-	req.SetRequestURI(url)
-	req.Header.SetMethod(fiber.MethodPost)
-	req.Header.SetContentType("application/json")
-	req.SetBody(jsonData)
-
-	if err := agent.Parse(); err != nil {
-		return fmt.Errorf("failed to parse request: %v", err)
-	}
-
-	code, body, errs := agent.Bytes()
-	if len(errs) > 0 {
-		return fmt.Errorf("failed to send boardgames: %v", errs[0])
-	}
-
-	if code != fiber.StatusOK {
-		return fmt.Errorf("failed to send boardgames: status %d, body: %s", code, string(body))
+		return nil, err
 	}
-
-	return nil
+	return response.Boardgames, nil
 }
 
-func (c *RESTRecommendationClient) GetAllBoardgames() ([]Boardgame, error) {
-	url := fmt.Sprintf("%s/api/boardgames", c.baseURL)
-
-	agent := fiber.AcquireAgent()
-	defer fiber.ReleaseAgent(agent)
-
-	req := agent.Request()
-	req.SetRequestURI(url)
-	req.Header.SetMethod(fiber.MethodGet)
-
-	if err := agent.Parse(); err != nil {
-		return nil, fmt.Errorf("failed to parse request: %v", err)
-	}
-
-	code, body, errs := agent.Bytes()
-	if len(errs) > 0 {
-		return nil, fmt.Errorf("failed to get boardgames: %v", errs[0])
-	}
-
-	if code != fiber.StatusOK {
-		return nil, fmt.Errorf("failed to get boardgames: status %d, body: %s", code, string(body))
-	}
+func (c *RESTRecommendationClient) SendAllBoardgames(ctx context.Context, boardgames []Boardgame) error {
+	return c.do(ctx, call{
+		op:      "failed to send boardgames",
+		method:  http.MethodPost,
+		path:    "/api/boardgames",
+		body:    map[string][]Boardgame{"boardgames": boardgames},
+		timeout: c.bulkTimeout,
+	}, nil)
+}
 
+func (c *RESTRecommendationClient) GetAllBoardgames(ctx context.Context) ([]Boardgame, error) {
 	var response struct {
 		Boardgames []Boardgame `json:"boardgames"`
 	}
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %v", err)
+	err := c.do(ctx, call{
+		op:         "failed to get boardgames",
+		method:     http.MethodGet,
+		path:       "/api/boardgames",
+		idempotent: true,
+		timeout:    c.bulkTimeout,
+	}, &response)
+	if err != nil {
+		return nil, err
 	}
-
 	return response.Boardgames, nil
 }
 
-func (c *RESTRecommendationClient) GetPopularBoardgames(limit int) ([]Boardgame, error) {
-	url := fmt.Sprintf("%s/api/boardgames/popular?limit=%d", c.baseURL, limit)
-
-	agent := fiber.AcquireAgent()
-	defer fiber.ReleaseAgent(agent)
-
-	req := agent.Request()
-	req.SetRequestURI(url)
-	req.Header.SetMethod(fiber.MethodGet)
-
-	if err := agent.Parse(); err != nil {
-		return nil, fmt.Errorf("failed to parse request: %v", err)
-	}
-
-	code, body, errs := agent.Bytes()
-	if len(errs) > 0 {
-		return nil, fmt.Errorf("failed to get popular boardgames: %v", errs[0])
-	}
-
-	if code != fiber.StatusOK {
-		return nil, fmt.Errorf("failed to get popular boardgames: status %d, body: %s", code, string(body))
-	}
-
+func (c *RESTRecommendationClient) GetPopularBoardgames(ctx context.Context, limit int) ([]Boardgame, error) {
 	var response struct {
 		Boardgames []Boardgame `json:"boardgames"`
 	}
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %v", err)
+	err := c.do(ctx, call{
+		op:         "failed to get popular boardgames",
+		method:     http.MethodGet,
+		path:       fmt.Sprintf("/api/boardgames/popular?limit=%d", limit),
+		idempotent: true,
+	}, &response)
+	if err != nil {
+		return nil, err
 	}
-
 	return response.Boardgames, nil
 }
 
-func (c *RESTRecommendationClient) GetUserActions(userID string) ([]UserAction, error) {
-	url := fmt.Sprintf("%s/api/actions/user/%s", c.baseURL, userID)
-
-	agent := fiber.AcquireAgent()
-	defer fiber.ReleaseAgent(agent)
-
-	req := agent.Request()
-	req.SetRequestURI(url)
-	req.Header.SetMethod(fiber.MethodGet)
-
-	if err := agent.Parse(); err != nil {
-		return nil, fmt.Errorf("failed to parse request: %v", err)
-	}
-
-	code, body, errs := agent.Bytes()
-	if len(errs) > 0 {
-		return nil, fmt.Errorf("failed to get user actions: %v", errs[0])
+func (c *RESTRecommendationClient) GetUserActions(ctx context.Context, userID string) ([]UserAction, error) {
+	var result struct {
+		Actions []UserAction `json:"actions"`
 	}
-
-	if code != fiber.StatusOK {
-		return nil, fmt.Errorf("failed to get user actions: status %d, body: %s", code, string(body))
+	err := c.do(ctx, call{
+		op:         "failed to get user actions",
+		method:     http.MethodGet,
+		path:       "/api/actions/user/" + url.PathEscape(userID),
+		idempotent: true,
+	}, &result)
+	if err != nil {
+		return nil, err
 	}
+	return result.Actions, nil
+}
 
+func (c *RESTRecommendationClient) GetBoardgameActions(ctx context.Context, boardgameID string) ([]UserAction, error) {
 	var result struct {
 		Actions []UserAction `json:"actions"`
 	}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %v", err)
+	err := c.do(ctx, call{
+		op:         "failed to get boardgame actions",
+		method:     http.MethodGet,
+		path:       "/api/actions/boardgame/" + url.PathEscape(boardgameID),
+		idempotent: true,
+	}, &result)
+	if err != nil {
+		return nil, err
 	}
-
 	return result.Actions, nil
 }
 
-func (c *RESTRecommendationClient) GetBoardgameActions(boardgameID string) ([]UserAction, error) {
-	url := fmt.Sprintf("%s/api/actions/boardgame/%s", c.baseURL, boardgameID)
-
-	agent := fiber.AcquireAgent()
-	defer fiber.ReleaseAgent(agent)
+// PollStatsChanged pushes a game's new poll summary to the search index in the background,
+// so the weight and best-at search filters see votes before the next catalogue sync without
+// the vote waiting on the ML service. While a game's push is in flight only its latest
+// summary is kept and sent next, so an older summary never overwrites a newer one. Failures
+// are only logged; the next sync sends the summary again.
+func (c *RESTRecommendationClient) PollStatsChanged(ctx context.Context, stats models.GamePollStats) {
+	c.pollMu.Lock()
+	defer c.pollMu.Unlock()
 
-	req := agent.Request()
-	req.SetRequestURI(url)
-	req.Header.SetMethod(fiber.MethodGet)
-
-	if err := agent.Parse(); err != nil {
-		return nil, fmt.Errorf("failed to parse request: %v", err)
+	if c.pollPending == nil {
+		c.pollPending = make(map[int]*models.GamePollStats)
 	}
-
-	code, body, errs := agent.Bytes()
-	if len(errs) > 0 {
-		return nil, fmt.Errorf("failed to get boardgame actions: %v", errs[0])
+	if _, running := c.pollPending[stats.BoardgameID]; running {
+		c.pollPending[stats.BoardgameID] = &stats
+		return
 	}
+	c.pollPending[stats.BoardgameID] = nil
+	go c.pushPollStats(context.WithoutCancel(ctx), stats)
+}
 
-	if code != fiber.StatusOK {
-		return nil, fmt.Errorf("failed to get boardgame actions: status %d, body: %s", code, string(body))
+// pushPollStats sends stats, then any summary of the same game queued meanwhile
+func (c *RESTRecommendationClient) pushPollStats(ctx context.Context, stats models.GamePollStats) {
+	for {
+		c.sendPollStats(ctx, stats)
+
+		c.pollMu.Lock()
+		next := c.pollPending[stats.BoardgameID]
+		if next == nil {
+			delete(c.pollPending, stats.BoardgameID)
+			c.pollMu.Unlock()
+			return
+		}
+		c.pollPending[stats.BoardgameID] = nil
+		c.pollMu.Unlock()
+		stats = *next
 	}
+}
 
-	var result struct {
-		Actions []UserAction `json:"actions"`
-	}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %v", err)
+func (c *RESTRecommendationClient) sendPollStats(ctx context.Context, stats models.GamePollStats) {
+	best, recommended := stats.BestPlayerCounts, stats.RecommendedPlayerCounts
+	if best == nil {
+		best = []int{}
+	}
+	if recommended == nil {
+		recommended = []int{}
+	}
+	err := c.do(ctx, call{
+		op:     "failed to update poll stats",
+		method: http.MethodPut,
+		path:   fmt.Sprintf("/api/boardgames/%d/polls", stats.BoardgameID),
+		body: map[string]interface{}{
+			"weight":                    stats.WeightAvg,
+			"best_player_counts":        best,
+			"recommended_player_counts": recommended,
+		},
+		// The whole summary is replaced, so repeating the call is harmless
+		idempotent: true,
+	}, nil)
+	if err != nil {
+		log.Printf("Error pushing poll stats of boardgame %d to the search index: %v", stats.BoardgameID, err)
 	}
-
-	return result.Actions, nil
 }
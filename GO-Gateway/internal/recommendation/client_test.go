@@ -0,0 +1,139 @@
+package recommendation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"guru-game/models"
+)
+
+func TestDo(t *testing.T) {
+	tests := []struct {
+		name            string
+		statuses        []int // answer to each request; the last one repeats
+		idempotent      bool
+		cancelOnAnswer  bool // cancel the caller's context as the first answer is written
+		wantHits        int32
+		wantErr         bool
+		wantCanceled    bool
+		wantUnavailable bool
+	}{
+		{name: "success", statuses: []int{http.StatusOK}, idempotent: true, wantHits: 1},
+		{name: "retries until the service recovers", statuses: []int{http.StatusServiceUnavailable, http.StatusOK}, idempotent: true, wantHits: 2},
+		{name: "gives up after the retries", statuses: []int{http.StatusBadGateway}, idempotent: true, wantHits: 3, wantErr: true, wantUnavailable: true},
+		{name: "non-idempotent call is not retried", statuses: []int{http.StatusServiceUnavailable}, wantHits: 1, wantErr: true, wantUnavailable: true},
+		{name: "client error is not retried", statuses: []int{http.StatusBadRequest}, idempotent: true, wantHits: 1, wantErr: true},
+		{name: "cancelled during backoff", statuses: []int{http.StatusServiceUnavailable}, idempotent: true, cancelOnAnswer: true, wantHits: 1, wantErr: true, wantCanceled: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var hits atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(hits.Add(1))
+				status := tt.statuses[min(n, len(tt.statuses))-1]
+				w.WriteHeader(status)
+				if status == http.StatusOK {
+					w.Write([]byte(`{}`))
+				}
+				if tt.cancelOnAnswer {
+					cancel()
+				}
+			}))
+			defer srv.Close()
+
+			c := &RESTRecommendationClient{
+				baseURL:    srv.URL,
+				httpClient: srv.Client(),
+				timeout:    time.Second,
+				maxRetries: 2,
+				breaker:    newCircuitBreaker(100, time.Minute),
+			}
+			err := c.do(ctx, call{op: "test", method: http.MethodGet, path: "/", idempotent: tt.idempotent}, &struct{}{})
+
+			if got := hits.Load(); got != tt.wantHits {
+				t.Errorf("requests = %d, want %d", got, tt.wantHits)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("do() = %v, want error %v", err, tt.wantErr)
+			}
+			if got := errors.Is(err, context.Canceled); got != tt.wantCanceled {
+				t.Errorf("errors.Is(%v, context.Canceled) = %v, want %v", err, got, tt.wantCanceled)
+			}
+			if got := errors.Is(err, ErrUpstreamUnavailable); got != tt.wantUnavailable {
+				t.Errorf("errors.Is(%v, ErrUpstreamUnavailable) = %v, want %v", err, got, tt.wantUnavailable)
+			}
+		})
+	}
+}
+
+func TestPollStatsChanged(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var weights []float64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Weight float64 `json:"weight"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		weights = append(weights, body.Weight)
+		mu.Unlock()
+		started <- struct{}{}
+		<-release
+	}))
+	defer srv.Close()
+
+	c := &RESTRecommendationClient{
+		baseURL:    srv.URL,
+		httpClient: srv.Client(),
+		timeout:    5 * time.Second,
+		breaker:    newCircuitBreaker(100, time.Minute),
+	}
+	push := func(weight float64) {
+		c.PollStatsChanged(context.Background(), models.GamePollStats{BoardgameID: 7, WeightAvg: &weight})
+	}
+
+	begin := time.Now()
+	push(1)
+	<-started
+	// Queued behind the push in flight; only the latest is sent
+	push(2)
+	push(3)
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Fatalf("PollStatsChanged waited %v for the ML service", elapsed)
+	}
+	close(release)
+	<-started
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		c.pollMu.Lock()
+		_, running := c.pollPending[7]
+		c.pollMu.Unlock()
+		if !running {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("push still running")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []float64{1, 3}; !reflect.DeepEqual(weights, want) {
+		t.Errorf("pushed weights = %v, want %v", weights, want)
+	}
+}
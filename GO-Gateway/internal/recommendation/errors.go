@@ -0,0 +1,47 @@
+package recommendation
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrUpstreamUnavailable matches errors meaning the ML service could not be reached, timed
+// out, answered with a server error or is being skipped by the circuit breaker. Handlers
+// answer these with 503 rather than 500.
+var ErrUpstreamUnavailable = errors.New("recommendation service unavailable")
+
+// ErrCircuitOpen is returned without calling the ML service while the circuit breaker is open
+var ErrCircuitOpen = fmt.Errorf("circuit open: %w", ErrUpstreamUnavailable)
+
+// maxErrorBody caps how much of an error response body is kept in an UpstreamError
+const maxErrorBody = 512
+
+// UpstreamError is a failed call to the ML service. StatusCode is 0 when no response was
+// received.
+type UpstreamError struct {
+	Op         string
+	StatusCode int
+	Body       string
+	Err        error
+}
+
+func (e *UpstreamError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s: status %d, body: %s", e.Op, e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf("%s: %v", e.Op, e.Err)
+}
+
+func (e *UpstreamError) Unwrap() error { return e.Err }
+
+// Is makes errors.Is(err, ErrUpstreamUnavailable) true for unavailable-type failures
+func (e *UpstreamError) Is(target error) bool {
+	return target == ErrUpstreamUnavailable && e.Unavailable()
+}
+
+// Unavailable reports whether the failure is the service's fault rather than the request's,
+// so the call may be retried and counts against the circuit breaker
+func (e *UpstreamError) Unavailable() bool {
+	return e.StatusCode == 0 || e.StatusCode >= http.StatusInternalServerError || e.StatusCode == http.StatusTooManyRequests
+}
@@ -0,0 +1,99 @@
+package recommendation
+
+import (
+	"context"
+	"log"
+)
+
+// Sources of recommendation results, as reported by GetRecommendationsFrom
+const (
+	SourceML    = "ml"
+	SourceLocal = "local"
+)
+
+// FailoverRecommendationClient reads from a primary client and falls back to a secondary
+// one when the primary fails. Writes only go to the primary: dropping them on the floor
+// would leave the ML service out of date once it is back.
+type FailoverRecommendationClient struct {
+	primary  RecommendationClient
+	fallback RecommendationClient
+}
+
+// NewFailoverRecommendationClient creates a client that fails over from primary to fallback
+func NewFailoverRecommendationClient(primary, fallback RecommendationClient) *FailoverRecommendationClient {
+	return &FailoverRecommendationClient{
+		primary:  primary,
+		fallback: fallback,
+	}
+}
+
+func (c *FailoverRecommendationClient) SendUserAction(ctx context.Context, action UserAction) error {
+	return c.primary.SendUserAction(ctx, action)
+}
+
+func (c *FailoverRecommendationClient) SendAllBoardgames(ctx context.Context, boardgames []Boardgame) error {
+	return c.primary.SendAllBoardgames(ctx, boardgames)
+}
+
+func (c *FailoverRecommendationClient) GetRecommendations(ctx context.Context, userID string, limit int) ([]Boardgame, error) {
+	recommendations, _, err := c.GetRecommendationsFrom(ctx, userID, limit)
+	return recommendations, err
+}
+
+// GetRecommendationsFrom is GetRecommendations that also reports which client answered:
+// SourceML for the primary and SourceLocal for the fallback
+func (c *FailoverRecommendationClient) GetRecommendationsFrom(ctx context.Context, userID string, limit int) ([]Boardgame, string, error) {
+	return failoverFrom(ctx, c, "GetRecommendations", func(client RecommendationClient) ([]Boardgame, error) {
+		return client.GetRecommendations(ctx, userID, limit)
+	})
+}
+
+func (c *FailoverRecommendationClient) GetAllBoardgames(ctx context.Context) ([]Boardgame, error) {
+	return failover(ctx, c, "GetAllBoardgames", func(client RecommendationClient) ([]Boardgame, error) {
+		return client.GetAllBoardgames(ctx)
+	})
+}
+
+func (c *FailoverRecommendationClient) GetPopularBoardgames(ctx context.Context, limit int) ([]Boardgame, error) {
+	return failover(ctx, c, "GetPopularBoardgames", func(client RecommendationClient) ([]Boardgame, error) {
+		return client.GetPopularBoardgames(ctx, limit)
+	})
+}
+
+func (c *FailoverRecommendationClient) GetUserActions(ctx context.Context, userID string) ([]UserAction, error) {
+	return failover(ctx, c, "GetUserActions", func(client RecommendationClient) ([]UserAction, error) {
+		return client.GetUserActions(ctx, userID)
+	})
+}
+
+func (c *FailoverRecommendationClient) GetBoardgameActions(ctx context.Context, boardgameID string) ([]UserAction, error) {
+	return failover(ctx, c, "GetBoardgameActions", func(client RecommendationClient) ([]UserAction, error) {
+		return client.GetBoardgameActions(ctx, boardgameID)
+	})
+}
+
+// failover runs call against the primary client and, if it fails, against the fallback.
+// The primary's error is returned when the fallback fails too, or when ctx was cancelled.
+func failover[T any](ctx context.Context, c *FailoverRecommendationClient, name string, call func(RecommendationClient) (T, error)) (T, error) {
+	result, _, err := failoverFrom(ctx, c, name, call)
+	return result, err
+}
+
+// failoverFrom is failover that also reports which client answered
+func failoverFrom[T any](ctx context.Context, c *FailoverRecommendationClient, name string, call func(RecommendationClient) (T, error)) (T, string, error) {
+	result, err := call(c.primary)
+	if err == nil {
+		return result, SourceML, nil
+	}
+	if ctx.Err() != nil {
+		return result, SourceML, err
+	}
+	log.Printf("⚠️ Recommendation service %s failed, using fallback: %v", name, err)
+
+	fallbackResult, fallbackErr := call(c.fallback)
+	if fallbackErr != nil {
+		log.Printf("❌ Fallback %s failed: %v", name, fallbackErr)
+		return result, SourceML, err
+	}
+	return fallbackResult, SourceLocal, nil
+}
@@ -0,0 +1,69 @@
+package recommendation
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// stubClient answers GetRecommendations with a fixed result and records the limit it was
+// asked for; other calls are not used
+type stubClient struct {
+	RecommendationClient
+	boardgames []Boardgame
+	err        error
+	calls      int
+	limit      int
+}
+
+func (s *stubClient) GetRecommendations(ctx context.Context, userID string, limit int) ([]Boardgame, error) {
+	s.calls++
+	s.limit = limit
+	return s.boardgames, s.err
+}
+
+func TestFailoverGetRecommendationsFrom(t *testing.T) {
+	mlGames := []Boardgame{{ID: 1}}
+	localGames := []Boardgame{{ID: 2}}
+	primaryErr := errors.New("ml down")
+
+	tests := []struct {
+		name          string
+		primary       *stubClient
+		fallback      *stubClient
+		cancelled     bool
+		wantID        int
+		wantSource    string
+		wantErr       error
+		wantFallbacks int
+	}{
+		{"primary answers", &stubClient{boardgames: mlGames}, &stubClient{boardgames: localGames}, false, 1, SourceML, nil, 0},
+		{"fallback answers", &stubClient{err: primaryErr}, &stubClient{boardgames: localGames}, false, 2, SourceLocal, nil, 1},
+		{"both fail", &stubClient{err: primaryErr}, &stubClient{err: errors.New("db down")}, false, 0, SourceML, primaryErr, 1},
+		{"cancelled skips fallback", &stubClient{err: primaryErr}, &stubClient{boardgames: localGames}, true, 0, SourceML, primaryErr, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelled {
+				cancel()
+			}
+
+			client := NewFailoverRecommendationClient(tt.primary, tt.fallback)
+			got, source, err := client.GetRecommendationsFrom(ctx, "7", 5)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if source != tt.wantSource {
+				t.Errorf("source = %q, want %q", source, tt.wantSource)
+			}
+			if tt.wantErr == nil && (len(got) != 1 || got[0].ID != tt.wantID) {
+				t.Errorf("boardgames = %+v, want game %d", got, tt.wantID)
+			}
+			if tt.fallback.calls != tt.wantFallbacks {
+				t.Errorf("fallback calls = %d, want %d", tt.fallback.calls, tt.wantFallbacks)
+			}
+		})
+	}
+}
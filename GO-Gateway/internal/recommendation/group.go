@@ -0,0 +1,452 @@
+package recommendation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"guru-game/internal/auth/jwt"
+	"guru-game/internal/db/repository/user_states"
+	"guru-game/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Group aggregation strategies
+const (
+	StrategyLeastMisery = "least_misery"
+	StrategyAverage     = "average"
+)
+
+// Limits on group requests
+const (
+	MaxGroupSize       = 12
+	DefaultGroupLimit  = 10
+	MaxGroupLimit      = 50
+	groupMLCandidates  = 50
+	groupRatingScale   = 10.0
+	groupLikedScore    = 0.85
+	groupFavoriteScore = 0.95
+)
+
+// GroupRequest asks for games a group of users would enjoy together. The caller must be one
+// of the users, and every other user must be a friend of the caller or have confirmed a game
+// night the caller also attends. PlayerCount defaults to the number of users and may be larger
+// for guests; TimeBudget is in minutes, 0 for no limit.
+type GroupRequest struct {
+	UserIDs     []int64 `json:"user_ids"`
+	PlayerCount int     `json:"player_count"`
+	TimeBudget  int     `json:"time_budget"`
+	Strategy    string  `json:"strategy"`
+	Limit       int     `json:"limit"`
+}
+
+// GroupRecommendation is a recommended game with the group score and the caller's own score,
+// both between 0 and 1. Other members' scores are not returned, since they would reveal
+// their ratings, likes and favorites.
+type GroupRecommendation struct {
+	Boardgame
+	GroupScore float64 `json:"group_score"`
+	MyScore    float64 `json:"my_score"`
+}
+
+// GroupResult is the response of the group endpoint. Source is "ml" when every member's
+// ranking came from the ML service and "local" when any was computed in the gateway, by the
+// fallback recommender or, when that fails too, from category affinity alone.
+type GroupResult struct {
+	Strategy   string                `json:"strategy"`
+	Source     string                `json:"source"`
+	Boardgames []GroupRecommendation `json:"boardgames"`
+}
+
+// groupValidationError reports an invalid group request
+type groupValidationError struct {
+	err error
+}
+
+func (e *groupValidationError) Error() string { return e.err.Error() }
+
+// errNotGroupPeer is returned when a requested member is neither a friend nor a fellow attendee
+var errNotGroupPeer = errors.New("group members must be your friends or attendees of a game night you attend")
+
+// sourcedClient is implemented by clients that can answer from more than one backend
+type sourcedClient interface {
+	GetRecommendationsFrom(ctx context.Context, userID string, limit int) ([]Boardgame, string, error)
+}
+
+// Friends lists a user's friends, the users they follow who follow them back
+type Friends interface {
+	Friends(ctx context.Context, userID int64) ([]models.Follow, error)
+}
+
+// CoAttendees looks up the users who confirmed a game night the user also confirmed
+type CoAttendees interface {
+	CoAttendeeIDs(ctx context.Context, userID int64) (map[int64]bool, error)
+}
+
+// memberProfile is what the group scorer knows about one member
+type memberProfile struct {
+	userID     int64
+	states     map[int]user_states.UserState
+	categories map[string]float64 // category -> preference weight
+	mlRank     map[int]float64    // boardgame ID -> ML rank score, 1 for the top pick
+}
+
+// HandleGetGroupRecommendations recommends games for a group of players that includes the caller
+func (h *Handler) HandleGetGroupRecommendations(c *fiber.Ctx) error {
+	callerID, ok := jwt.UserIDFromCtx(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	var req GroupRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	ctx, cancel := requestContext(c)
+	defer cancel()
+
+	result, err := h.recommendForGroup(ctx, callerID, req)
+	if err != nil {
+		var validationErr *groupValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": validationErr.Error(),
+			})
+		case errors.Is(err, errNotGroupPeer):
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return upstreamError(c, err, "failed to get group recommendations")
+	}
+
+	return c.JSON(result)
+}
+
+// recommendForGroup scores every game that fits the group for each member and combines the
+// member scores with the requested strategy. A member's score is their own rating, like or
+// favorite when they have one, otherwise their ML ranking blended with category affinity.
+// When no ranking is available at all, members are scored from category affinity alone.
+func (h *Handler) recommendForGroup(ctx context.Context, callerID int64, req GroupRequest) (*GroupResult, error) {
+	if err := normalizeGroupRequest(&req, callerID); err != nil {
+		return nil, err
+	}
+	if err := h.checkGroupPeers(ctx, callerID, req.UserIDs); err != nil {
+		return nil, err
+	}
+
+	catalogue, err := h.bgService.GetAllBoardgames()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load boardgames: %w", err)
+	}
+	byID := make(map[int]models.BoardGame, len(catalogue))
+	for _, bg := range catalogue {
+		byID[bg.ID] = bg
+	}
+
+	members := make([]*memberProfile, 0, len(req.UserIDs))
+	for _, userID := range req.UserIDs {
+		states, err := h.userStateRepo.GetAllByUserID(ctx, int(userID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load states of user %d: %w", userID, err)
+		}
+		members = append(members, newMemberProfile(userID, states, byID))
+	}
+
+	source := SourceML
+	for _, m := range members {
+		recs, from, err := h.recommendationsFrom(ctx, strconv.FormatInt(m.userID, 10), groupMLCandidates)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			log.Printf("⚠️ No rankings available for group recommendations, using category scoring: %v", err)
+			source = SourceLocal
+			for _, m := range members {
+				m.mlRank = nil
+			}
+			break
+		}
+		if from == SourceLocal {
+			source = SourceLocal
+		}
+		m.mlRank = make(map[int]float64, len(recs))
+		for i, bg := range recs {
+			m.mlRank[bg.ID] = 1 - float64(i)/float64(len(recs))
+		}
+	}
+
+	var scored []GroupRecommendation
+	for _, bg := range catalogue {
+		if !fitsGroup(bg, req.PlayerCount, req.TimeBudget) {
+			continue
+		}
+		rec := GroupRecommendation{Boardgame: toRecoBoardgame(bg)}
+		scores := make([]float64, 0, len(members))
+		for _, m := range members {
+			s := m.score(bg)
+			if m.userID == callerID {
+				rec.MyScore = round2(s)
+			}
+			scores = append(scores, s)
+		}
+		rec.GroupScore = round2(aggregate(req.Strategy, scores))
+		scored = append(scored, rec)
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		if scored[i].GroupScore != scored[j].GroupScore {
+			return scored[i].GroupScore > scored[j].GroupScore
+		}
+		return scored[i].PopularityScore > scored[j].PopularityScore
+	})
+	if len(scored) > req.Limit {
+		scored = scored[:req.Limit]
+	}
+	if scored == nil {
+		scored = []GroupRecommendation{}
+	}
+
+	return &GroupResult{Strategy: req.Strategy, Source: source, Boardgames: scored}, nil
+}
+
+// recommendationsFrom ranks games for one member and reports which backend answered
+func (h *Handler) recommendationsFrom(ctx context.Context, userID string, limit int) ([]Boardgame, string, error) {
+	if c, ok := h.client.(sourcedClient); ok {
+		return c.GetRecommendationsFrom(ctx, userID, limit)
+	}
+	recommendations, err := h.client.GetRecommendations(ctx, userID, limit)
+	return recommendations, SourceML, err
+}
+
+// checkGroupPeers returns errNotGroupPeer unless every member other than the caller is a
+// friend of the caller or a fellow attendee of one of the caller's game nights
+func (h *Handler) checkGroupPeers(ctx context.Context, callerID int64, userIDs []int64) error {
+	friends, err := h.friends.Friends(ctx, callerID)
+	if err != nil {
+		return fmt.Errorf("failed to load friends of user %d: %w", callerID, err)
+	}
+	allowed := map[int64]bool{callerID: true}
+	for _, f := range friends {
+		allowed[f.UserID] = true
+	}
+
+	var coAttendees map[int64]bool
+	for _, id := range userIDs {
+		if allowed[id] {
+			continue
+		}
+		if coAttendees == nil {
+			if coAttendees, err = h.coAttendees.CoAttendeeIDs(ctx, callerID); err != nil {
+				return fmt.Errorf("failed to load co-attendees of user %d: %w", callerID, err)
+			}
+		}
+		if !coAttendees[id] {
+			return errNotGroupPeer
+		}
+	}
+	return nil
+}
+
+// normalizeGroupRequest validates req, drops duplicate users and fills in the defaults.
+// callerID must be one of the users.
+func normalizeGroupRequest(req *GroupRequest, callerID int64) error {
+	seen := make(map[int64]bool, len(req.UserIDs))
+	var ids []int64
+	for _, id := range req.UserIDs {
+		if id <= 0 {
+			return &groupValidationError{errors.New("user_ids must be positive")}
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) < 2 || len(ids) > MaxGroupSize {
+		return &groupValidationError{fmt.Errorf("between 2 and %d distinct user_ids are required", MaxGroupSize)}
+	}
+	if !seen[callerID] {
+		return &groupValidationError{errors.New("user_ids must include you")}
+	}
+	req.UserIDs = ids
+
+	if req.PlayerCount == 0 {
+		req.PlayerCount = len(ids)
+	}
+	if req.PlayerCount < len(ids) {
+		return &groupValidationError{errors.New("player_count cannot be less than the number of users")}
+	}
+	if req.TimeBudget < 0 {
+		return &groupValidationError{errors.New("time_budget cannot be negative")}
+	}
+
+	switch req.Strategy {
+	case "":
+		req.Strategy = StrategyLeastMisery
+	case StrategyLeastMisery, StrategyAverage:
+	default:
+		return &groupValidationError{fmt.Errorf("strategy must be %s or %s", StrategyLeastMisery, StrategyAverage)}
+	}
+
+	if req.Limit <= 0 || req.Limit > MaxGroupLimit {
+		req.Limit = DefaultGroupLimit
+	}
+	return nil
+}
+
+// fitsGroup reports whether a game supports playerCount players and, with a time budget,
+// can be finished within it
+func fitsGroup(bg models.BoardGame, playerCount, timeBudget int) bool {
+	if bg.MinPlayers > playerCount || (bg.MaxPlayers > 0 && bg.MaxPlayers < playerCount) {
+		return false
+	}
+	if timeBudget > 0 {
+		length := bg.PlayTimeMax
+		if length == 0 {
+			length = bg.PlayTimeMin
+		}
+		if length > timeBudget {
+			return false
+		}
+	}
+	return true
+}
+
+func newMemberProfile(userID int64, states []user_states.UserState, catalogue map[int]models.BoardGame) *memberProfile {
+	m := &memberProfile{
+		userID:     userID,
+		states:     make(map[int]user_states.UserState, len(states)),
+		categories: make(map[string]float64),
+	}
+	for _, st := range states {
+		m.states[st.BoardgameID] = st
+		weight := stateWeight(st)
+		if weight <= 0 {
+			continue
+		}
+		bg, ok := catalogue[st.BoardgameID]
+		if !ok {
+			continue
+		}
+		for _, cat := range splitCategories(bg.Categories) {
+			m.categories[cat] += weight
+		}
+	}
+	return m
+}
+
+// stateWeight is how much a member's state says they like a game: favorites and likes
+// count fully, ratings count above the midpoint of the scale
+func stateWeight(st user_states.UserState) float64 {
+	switch {
+	case st.Favorited:
+		return 2
+	case st.Liked:
+		return 1
+	case st.Rating > groupRatingScale/2:
+		return (st.Rating - groupRatingScale/2) / (groupRatingScale / 2)
+	}
+	return 0
+}
+
+// score estimates how much the member would enjoy bg, between 0 and 1
+func (m *memberProfile) score(bg models.BoardGame) float64 {
+	if st, ok := m.states[bg.ID]; ok {
+		var s float64
+		if st.Rating > 0 {
+			s = st.Rating / groupRatingScale
+		}
+		if st.Liked {
+			s = math.Max(s, groupLikedScore)
+		}
+		if st.Favorited {
+			s = math.Max(s, groupFavoriteScore)
+		}
+		if st.Rating > 0 || st.Liked || st.Favorited {
+			return s
+		}
+	}
+
+	affinity := m.categoryAffinity(bg)
+	quality := math.Min(bg.RatingAvg/groupRatingScale, 1)
+	if rank, ok := m.mlRank[bg.ID]; ok {
+		return 0.4 + 0.45*rank + 0.15*affinity
+	}
+	return 0.1 + 0.5*affinity + 0.2*quality
+}
+
+// categoryAffinity is the share of the member's category preference covered by bg's categories
+func (m *memberProfile) categoryAffinity(bg models.BoardGame) float64 {
+	var total, matched float64
+	for _, w := range m.categories {
+		total += w
+	}
+	if total == 0 {
+		return 0
+	}
+	for _, cat := range splitCategories(bg.Categories) {
+		matched += m.categories[cat]
+	}
+	return math.Min(matched/total*2, 1)
+}
+
+func aggregate(strategy string, scores []float64) float64 {
+	if len(scores) == 0 {
+		return 0
+	}
+	if strategy == StrategyLeastMisery {
+		lowest := scores[0]
+		for _, s := range scores[1:] {
+			lowest = math.Min(lowest, s)
+		}
+		return lowest
+	}
+	var sum float64
+	for _, s := range scores {
+		sum += s
+	}
+	return sum / float64(len(scores))
+}
+
+func splitCategories(categories string) []string {
+	var out []string
+	for _, cat := range strings.Split(categories, ",") {
+		if cat = strings.ToLower(strings.TrimSpace(cat)); cat != "" {
+			out = append(out, cat)
+		}
+	}
+	return out
+}
+
+func toRecoBoardgame(bg models.BoardGame) Boardgame {
+	return Boardgame{
+		ID:              bg.ID,
+		Title:           bg.Title,
+		Description:     bg.Description,
+		MinPlayers:      bg.MinPlayers,
+		MaxPlayers:      bg.MaxPlayers,
+		PlayTimeMin:     bg.PlayTimeMin,
+		PlayTimeMax:     bg.PlayTimeMax,
+		Categories:      bg.Categories,
+		RatingAvg:       bg.RatingAvg,
+		RatingCount:     bg.RatingCount,
+		PopularityScore: bg.PopularityScore,
+		ImageURL:        bg.ImageURL,
+	}
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
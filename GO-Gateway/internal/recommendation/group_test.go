@@ -0,0 +1,95 @@
+package recommendation
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeGroupRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      GroupRequest
+		callerID int64
+		want     GroupRequest
+		wantErr  string
+	}{
+		{
+			name:     "defaults",
+			req:      GroupRequest{UserIDs: []int64{1, 2, 3}},
+			callerID: 1,
+			want:     GroupRequest{UserIDs: []int64{1, 2, 3}, PlayerCount: 3, Strategy: StrategyLeastMisery, Limit: DefaultGroupLimit},
+		},
+		{
+			name:     "duplicates dropped in order",
+			req:      GroupRequest{UserIDs: []int64{2, 1, 2, 1}, PlayerCount: 4, TimeBudget: 90, Strategy: StrategyAverage, Limit: 5},
+			callerID: 2,
+			want:     GroupRequest{UserIDs: []int64{2, 1}, PlayerCount: 4, TimeBudget: 90, Strategy: StrategyAverage, Limit: 5},
+		},
+		{
+			name:     "limit above maximum falls back to default",
+			req:      GroupRequest{UserIDs: []int64{1, 2}, Limit: MaxGroupLimit + 1},
+			callerID: 1,
+			want:     GroupRequest{UserIDs: []int64{1, 2}, PlayerCount: 2, Strategy: StrategyLeastMisery, Limit: DefaultGroupLimit},
+		},
+		{name: "caller missing", req: GroupRequest{UserIDs: []int64{2, 3}}, callerID: 1, wantErr: "must include you"},
+		{name: "non-positive id", req: GroupRequest{UserIDs: []int64{1, 0}}, callerID: 1, wantErr: "positive"},
+		{name: "only one distinct user", req: GroupRequest{UserIDs: []int64{1, 1}}, callerID: 1, wantErr: "distinct user_ids"},
+		{name: "too many users", req: GroupRequest{UserIDs: sequence(MaxGroupSize + 1)}, callerID: 1, wantErr: "distinct user_ids"},
+		{name: "player count below group size", req: GroupRequest{UserIDs: []int64{1, 2, 3}, PlayerCount: 2}, callerID: 1, wantErr: "player_count"},
+		{name: "negative time budget", req: GroupRequest{UserIDs: []int64{1, 2}, TimeBudget: -1}, callerID: 1, wantErr: "time_budget"},
+		{name: "unknown strategy", req: GroupRequest{UserIDs: []int64{1, 2}, Strategy: "majority"}, callerID: 1, wantErr: "strategy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			err := normalizeGroupRequest(&req, tt.callerID)
+			if tt.wantErr != "" {
+				var validationErr *groupValidationError
+				if !errors.As(err, &validationErr) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("normalizeGroupRequest() = %v, want validation error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("normalizeGroupRequest() = %v", err)
+			}
+			if !reflect.DeepEqual(req, tt.want) {
+				t.Errorf("normalized = %+v, want %+v", req, tt.want)
+			}
+		})
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		scores   []float64
+		want     float64
+	}{
+		{"least misery takes the minimum", StrategyLeastMisery, []float64{0.9, 0.2, 0.6}, 0.2},
+		{"least misery single score", StrategyLeastMisery, []float64{0.7}, 0.7},
+		{"average", StrategyAverage, []float64{0.9, 0.2, 0.6}, 0.5666666666666667},
+		{"average of equal scores", StrategyAverage, []float64{0.4, 0.4}, 0.4},
+		{"no scores", StrategyAverage, nil, 0},
+		{"no scores least misery", StrategyLeastMisery, []float64{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := aggregate(tt.strategy, tt.scores); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("aggregate(%q, %v) = %v, want %v", tt.strategy, tt.scores, got, tt.want)
+			}
+		})
+	}
+}
+
+func sequence(n int) []int64 {
+	ids := make([]int64, n)
+	for i := range ids {
+		ids[i] = int64(i + 1)
+	}
+	return ids
+}
@@ -1,25 +1,39 @@
 package recommendation
 
 import (
+	"context"
+	"errors"
 	"log"
 	"strconv"
 	"strings"
 
 	"guru-game/internal/boardgame/service_board"
 	"guru-game/internal/db/repository/user_states"
+	"guru-game/models"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-// RecommendationClient defines the interface for recommendation service clients
+// RecommendationClient defines the interface for recommendation service clients. Calls stop
+// when ctx is cancelled; a request ID stored with WithRequestID is passed on to the service.
 type RecommendationClient interface {
-	SendUserAction(action UserAction) error
-	GetRecommendations(userID string, limit int) ([]Boardgame, error)
-	SendAllBoardgames(boardgames []Boardgame) error
-	GetAllBoardgames() ([]Boardgame, error)
-	GetPopularBoardgames(limit int) ([]Boardgame, error)
-	GetUserActions(userID string) ([]UserAction, error)
-	GetBoardgameActions(boardgameID string) ([]UserAction, error)
+	SendUserAction(ctx context.Context, action UserAction) error
+	GetRecommendations(ctx context.Context, userID string, limit int) ([]Boardgame, error)
+	SendAllBoardgames(ctx context.Context, boardgames []Boardgame) error
+	GetAllBoardgames(ctx context.Context) ([]Boardgame, error)
+	GetPopularBoardgames(ctx context.Context, limit int) ([]Boardgame, error)
+	GetUserActions(ctx context.Context, userID string) ([]UserAction, error)
+	GetBoardgameActions(ctx context.Context, boardgameID string) ([]UserAction, error)
+}
+
+// OwnedGames looks up the games a user already owns, which are left out of recommendations
+type OwnedGames interface {
+	OwnedGameIDs(ctx context.Context, userID int64) (map[int]bool, error)
+}
+
+// GamePolls provides the community weight and player count poll results sent with the catalogue
+type GamePolls interface {
+	Stats(ctx context.Context, ids []int) (map[int]models.GamePollStats, error)
 }
 
 // Handler handles recommendation-related HTTP requests
@@ -27,19 +41,108 @@ type Handler struct {
 	client        RecommendationClient
 	bgService     *service_board.BoardgameService
 	userStateRepo user_states.UserStateRepository
+	owned         OwnedGames
+	polls         GamePolls
+	friends       Friends
+	coAttendees   CoAttendees
 }
 
 // NewHandler creates a new recommendation handler
-func NewHandler(client RecommendationClient, bgService *service_board.BoardgameService, userStateRepo user_states.UserStateRepository) *Handler {
+func NewHandler(client RecommendationClient, bgService *service_board.BoardgameService, userStateRepo user_states.UserStateRepository, owned OwnedGames, polls GamePolls, friends Friends, coAttendees CoAttendees) *Handler {
 	return &Handler{
 		client:        client,
 		bgService:     bgService,
 		userStateRepo: userStateRepo,
+		owned:         owned,
+		polls:         polls,
+		friends:       friends,
+		coAttendees:   coAttendees,
+	}
+}
+
+// upstreamError answers a failed recommendation service call with 503 when the service is
+// unavailable and 500 otherwise
+func upstreamError(c *fiber.Ctx, err error, message string) error {
+	if errors.Is(err, context.Canceled) {
+		// The server is shutting down; nobody is left to read an answer
+		return nil
+	}
+	log.Printf("❌ %s: %v", message, err)
+	if errors.Is(err, ErrUpstreamUnavailable) {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": message,
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
+
+// MaxRecommendationLimit caps how many games one recommendation request returns
+const MaxRecommendationLimit = 100
+
+// limitParam reads ?limit=, defaulting to def and capped at MaxRecommendationLimit. When it
+// returns false a 400 has already been written.
+func limitParam(c *fiber.Ctx, def int) (int, bool) {
+	limit, err := strconv.Atoi(c.Query("limit", strconv.Itoa(def)))
+	if err != nil || limit < 1 {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid limit parameter",
+		})
+		return 0, false
+	}
+	if limit > MaxRecommendationLimit {
+		limit = MaxRecommendationLimit
+	}
+	return limit, true
+}
+
+// maxOwnedPadding caps how many extra recommendations are requested to make up for owned games
+const maxOwnedPadding = 100
+
+// recommendWithoutOwned asks the ML service for limit recommendations, leaving out games
+// the user owns unless ?include_owned=true. Extra results are requested to fill the gap.
+func (h *Handler) recommendWithoutOwned(ctx context.Context, c *fiber.Ctx, userID string, limit int) ([]Boardgame, error) {
+	owned := map[int]bool{}
+	if id, err := strconv.ParseInt(userID, 10, 64); err == nil && !c.QueryBool("include_owned") {
+		owned, err = h.owned.OwnedGameIDs(ctx, id)
+		if err != nil {
+			// Recommending an owned game is better than recommending nothing
+			log.Printf("⚠️ Could not load owned games for user %s: %v", userID, err)
+			owned = map[int]bool{}
+		}
+	}
+
+	padding := len(owned)
+	if padding > maxOwnedPadding {
+		padding = maxOwnedPadding
+	}
+	recommendations, err := h.client.GetRecommendations(ctx, userID, limit+padding)
+	if err != nil {
+		return nil, err
 	}
+
+	filtered := make([]Boardgame, 0, limit)
+	for _, bg := range recommendations {
+		if owned[bg.ID] {
+			continue
+		}
+		filtered = append(filtered, bg)
+		if len(filtered) == limit {
+			break
+		}
+	}
+	return filtered, nil
 }
 
 // HandleSendAllBoardgames handles sending all boardgames to the recommendation service
 func (h *Handler) HandleSendAllBoardgames(c *fiber.Ctx) error {
+	ctx, cancel := requestContext(c)
+	defer cancel()
+	// A sync that has started is finished even if the admin stops waiting for it; the bulk
+	// timeout still bounds it
+	ctx = context.WithoutCancel(ctx)
+
 	// Query ข้อมูลจาก DB
 	boardgames, err := h.bgService.GetAllBoardgames()
 	if err != nil {
@@ -48,9 +151,17 @@ func (h *Handler) HandleSendAllBoardgames(c *fiber.Ctx) error {
 		})
 	}
 
+	// Poll results are optional input; the catalogue is still sent without them
+	pollStats, err := h.polls.Stats(ctx, nil)
+	if err != nil {
+		log.Printf("⚠️ Could not load poll results: %v", err)
+		pollStats = map[int]models.GamePollStats{}
+	}
+
 	// แปลงข้อมูลเป็น format ที่ Python service ต้องการ
 	var recoBoardgames []Boardgame
 	for _, bg := range boardgames {
+		stats := pollStats[bg.ID]
 		recoBoardgames = append(recoBoardgames, Boardgame{
 			ID:              bg.ID,
 			Title:           bg.Title,
@@ -64,14 +175,16 @@ func (h *Handler) HandleSendAllBoardgames(c *fiber.Ctx) error {
 			RatingCount:     bg.RatingCount,
 			PopularityScore: bg.PopularityScore,
 			ImageURL:        bg.ImageURL,
+
+			Weight:                  stats.WeightAvg,
+			BestPlayerCounts:        stats.BestPlayerCounts,
+			RecommendedPlayerCounts: stats.RecommendedPlayerCounts,
 		})
 	}
 
 	// ส่งข้อมูลไปยัง Python service
-	if err := h.client.SendAllBoardgames(recoBoardgames); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to send boardgames to recommendation service",
-		})
+	if err := h.client.SendAllBoardgames(ctx, recoBoardgames); err != nil {
+		return upstreamError(c, err, "failed to send boardgames to recommendation service")
 	}
 
 	return c.JSON(fiber.Map{
@@ -89,19 +202,17 @@ func (h *Handler) HandleGetRecommendations(c *fiber.Ctx) error {
 		})
 	}
 
-	limitStr := c.Query("limit", "10")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid limit parameter",
-		})
+	limit, ok := limitParam(c, 10)
+	if !ok {
+		return nil
 	}
 
-	recommendations, err := h.client.GetRecommendations(userID, limit)
+	ctx, cancel := requestContext(c)
+	defer cancel()
+
+	recommendations, err := h.recommendWithoutOwned(ctx, c, userID, limit)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to get recommendations",
-		})
+		return upstreamError(c, err, "failed to get recommendations")
 	}
 
 	return c.JSON(fiber.Map{
@@ -111,19 +222,17 @@ func (h *Handler) HandleGetRecommendations(c *fiber.Ctx) error {
 
 // HandleGetPopularBoardgames handles getting popular boardgames
 func (h *Handler) HandleGetPopularBoardgames(c *fiber.Ctx) error {
-	limitStr := c.Query("limit", "5")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid limit parameter",
-		})
+	limit, ok := limitParam(c, 5)
+	if !ok {
+		return nil
 	}
 
-	boardgames, err := h.client.GetPopularBoardgames(limit)
+	ctx, cancel := requestContext(c)
+	defer cancel()
+
+	boardgames, err := h.client.GetPopularBoardgames(ctx, limit)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to get popular boardgames",
-		})
+		return upstreamError(c, err, "failed to get popular boardgames")
 	}
 
 	return c.JSON(fiber.Map{
@@ -140,10 +249,11 @@ func (h *Handler) HandleAddUserAction(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.client.SendUserAction(action); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to add user action",
-		})
+	ctx, cancel := requestContext(c)
+	defer cancel()
+
+	if err := h.client.SendUserAction(ctx, action); err != nil {
+		return upstreamError(c, err, "failed to add user action")
 	}
 
 	return c.JSON(fiber.Map{
@@ -160,11 +270,12 @@ func (h *Handler) HandleGetUserActions(c *fiber.Ctx) error {
 		})
 	}
 
-	actions, err := h.client.GetUserActions(userID)
+	ctx, cancel := requestContext(c)
+	defer cancel()
+
+	actions, err := h.client.GetUserActions(ctx, userID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to get user actions",
-		})
+		return upstreamError(c, err, "failed to get user actions")
 	}
 
 	// Check if the route is for favorites and filter actions
@@ -194,11 +305,12 @@ func (h *Handler) HandleGetBoardgameActions(c *fiber.Ctx) error {
 		})
 	}
 
-	actions, err := h.client.GetBoardgameActions(boardgameID)
+	ctx, cancel := requestContext(c)
+	defer cancel()
+
+	actions, err := h.client.GetBoardgameActions(ctx, boardgameID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to get boardgame actions",
-		})
+		return upstreamError(c, err, "failed to get boardgame actions")
 	}
 
 	return c.JSON(fiber.Map{
@@ -208,11 +320,12 @@ func (h *Handler) HandleGetBoardgameActions(c *fiber.Ctx) error {
 
 // HandleGetAllBoardgamesFromES handles getting all boardgames from Elasticsearch via the recommendation service
 func (h *Handler) HandleGetAllBoardgamesFromES(c *fiber.Ctx) error {
-	boardgames, err := h.client.GetAllBoardgames()
+	ctx, cancel := requestContext(c)
+	defer cancel()
+
+	boardgames, err := h.client.GetAllBoardgames(ctx)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to get all boardgames from recommendation service",
-		})
+		return upstreamError(c, err, "failed to get all boardgames from recommendation service")
 	}
 
 	return c.JSON(fiber.Map{
@@ -352,23 +465,20 @@ func (h *Handler) HandleGetBehaviorBasedRecommendations(c *fiber.Ctx) error {
 	log.Printf("Total Categories: %d", len(userCategories))
 
 	// Get limit parameter
-	limitStr := c.Query("limit", "10")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid limit parameter",
-		})
+	limit, ok := limitParam(c, 10)
+	if !ok {
+		return nil
 	}
 
 	log.Printf("🎯 Requesting %d recommendations from ML service", limit)
 
 	// Get recommendations from ML service
-	recommendations, err := h.client.GetRecommendations(userID, limit)
+	ctx, cancel := requestContext(c)
+	defer cancel()
+
+	recommendations, err := h.recommendWithoutOwned(ctx, c, userID, limit)
 	if err != nil {
-		log.Printf("❌ Failed to get recommendations: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to get recommendations",
-		})
+		return upstreamError(c, err, "failed to get recommendations")
 	}
 
 	log.Printf("✅ Successfully received %d recommendations", len(recommendations))
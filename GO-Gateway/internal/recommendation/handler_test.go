@@ -0,0 +1,141 @@
+package recommendation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestLimitParam(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantLimit  int
+	}{
+		{"default", "", fiber.StatusOK, 10},
+		{"given", "?limit=25", fiber.StatusOK, 25},
+		{"one", "?limit=1", fiber.StatusOK, 1},
+		{"capped", "?limit=1000000", fiber.StatusOK, MaxRecommendationLimit},
+		{"zero", "?limit=0", fiber.StatusBadRequest, 0},
+		{"negative", "?limit=-1", fiber.StatusBadRequest, 0},
+		{"not a number", "?limit=ten", fiber.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				limit, ok := limitParam(c, 10)
+				if !ok {
+					return nil
+				}
+				return c.SendString(strconv.Itoa(limit))
+			})
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/"+tt.query, nil))
+			if err != nil {
+				t.Fatalf("app.Test() = %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("GET /%s status = %d, want %d", tt.query, resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != fiber.StatusOK {
+				return
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != strconv.Itoa(tt.wantLimit) {
+				t.Errorf("limit = %s, want %d", body, tt.wantLimit)
+			}
+		})
+	}
+}
+
+// stubOwned answers OwnedGameIDs with fixed games
+type stubOwned struct {
+	owned map[int]bool
+	err   error
+	calls int
+}
+
+func (s *stubOwned) OwnedGameIDs(ctx context.Context, userID int64) (map[int]bool, error) {
+	s.calls++
+	return s.owned, s.err
+}
+
+func TestRecommendWithoutOwned(t *testing.T) {
+	games := func(ids ...int) []Boardgame {
+		boardgames := make([]Boardgame, len(ids))
+		for i, id := range ids {
+			boardgames[i].ID = id
+		}
+		return boardgames
+	}
+	manyOwned := make(map[int]bool)
+	for id := 1000; id < 1000+maxOwnedPadding+50; id++ {
+		manyOwned[id] = true
+	}
+
+	tests := []struct {
+		name        string
+		userID      string
+		query       string
+		owned       *stubOwned
+		served      []Boardgame
+		limit       int
+		wantAsked   int
+		wantIDs     []int
+		wantLookups int
+	}{
+		{"owned games are left out", "7", "", &stubOwned{owned: map[int]bool{2: true, 4: true}}, games(1, 2, 3, 4, 5), 3, 5, []int{1, 3, 5}, 1},
+		{"cut at the limit", "7", "", &stubOwned{owned: map[int]bool{2: true}}, games(1, 2, 3, 4, 5), 2, 3, []int{1, 3}, 1},
+		{"fewer than the limit left", "7", "", &stubOwned{owned: map[int]bool{1: true, 2: true}}, games(1, 2, 3), 3, 5, []int{3}, 1},
+		{"padding is capped", "7", "", &stubOwned{owned: manyOwned}, games(1, 2), 2, 2 + maxOwnedPadding, []int{1, 2}, 1},
+		{"include_owned skips the lookup", "7", "?include_owned=true", &stubOwned{owned: map[int]bool{2: true}}, games(1, 2, 3), 3, 3, []int{1, 2, 3}, 0},
+		{"non-numeric user skips the lookup", "guest", "", &stubOwned{owned: map[int]bool{2: true}}, games(1, 2, 3), 3, 3, []int{1, 2, 3}, 0},
+		{"lookup failure keeps every game", "7", "", &stubOwned{err: errors.New("db down")}, games(1, 2, 3), 3, 3, []int{1, 2, 3}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &stubClient{boardgames: tt.served}
+			h := &Handler{client: client, owned: tt.owned}
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				recommendations, err := h.recommendWithoutOwned(context.Background(), c, tt.userID, tt.limit)
+				if err != nil {
+					return err
+				}
+				return c.JSON(recommendations)
+			})
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/"+tt.query, nil))
+			if err != nil {
+				t.Fatalf("app.Test() = %v", err)
+			}
+			var got []Boardgame
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+
+			ids := []int{}
+			for _, bg := range got {
+				ids = append(ids, bg.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("recommended = %v, want %v", ids, tt.wantIDs)
+			}
+			if client.limit != tt.wantAsked {
+				t.Errorf("asked the ML service for %d, want %d", client.limit, tt.wantAsked)
+			}
+			if tt.owned.calls != tt.wantLookups {
+				t.Errorf("owned lookups = %d, want %d", tt.owned.calls, tt.wantLookups)
+			}
+		})
+	}
+}
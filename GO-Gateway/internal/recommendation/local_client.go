@@ -0,0 +1,201 @@
+package recommendation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"guru-game/internal/boardgame/service_board"
+	"guru-game/internal/db/repository/user_states"
+	"guru-game/models"
+)
+
+// ErrNotSupported is returned by clients for operations they cannot serve
+var ErrNotSupported = errors.New("operation not supported by this recommendation client")
+
+// Weights of the local recommender's score components
+const (
+	localCollaborativeWeight = 0.6
+	localContentWeight       = 0.3
+	localQualityWeight       = 0.1
+	localCandidates          = 200
+)
+
+// LocalRecommendationClient recommends games without the ML service, from item-item
+// collaborative filtering over user_states blended with category similarity to the games
+// the user likes. Users without any state get the most popular games.
+type LocalRecommendationClient struct {
+	bgService     *service_board.BoardgameService
+	userStateRepo user_states.UserStateRepository
+}
+
+// NewLocalRecommendationClient creates a new LocalRecommendationClient
+func NewLocalRecommendationClient(bgService *service_board.BoardgameService, userStateRepo user_states.UserStateRepository) *LocalRecommendationClient {
+	return &LocalRecommendationClient{
+		bgService:     bgService,
+		userStateRepo: userStateRepo,
+	}
+}
+
+// GetRecommendations returns up to limit games the user has no state for, best first
+func (c *LocalRecommendationClient) GetRecommendations(ctx context.Context, userID string, limit int) ([]Boardgame, error) {
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID %q: %w", userID, err)
+	}
+	if limit < 0 {
+		limit = 0
+	}
+	catalogue, err := c.bgService.GetAllBoardgames()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load boardgames: %w", err)
+	}
+	states, err := c.userStateRepo.GetAllByUserID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(states) == 0 {
+		return popular(catalogue, limit), nil
+	}
+
+	similar, err := c.userStateRepo.SimilarGames(ctx, id, localCandidates)
+	if err != nil {
+		return nil, err
+	}
+	var maxSimilarity float64
+	for _, s := range similar {
+		maxSimilarity = math.Max(maxSimilarity, s)
+	}
+
+	byID := make(map[int]models.BoardGame, len(catalogue))
+	for _, bg := range catalogue {
+		byID[bg.ID] = bg
+	}
+	profile := newMemberProfile(int64(id), states, byID)
+
+	type candidate struct {
+		bg    models.BoardGame
+		score float64
+	}
+	var candidates []candidate
+	for _, bg := range catalogue {
+		if _, known := profile.states[bg.ID]; known {
+			continue
+		}
+		var collaborative float64
+		if maxSimilarity > 0 {
+			collaborative = similar[bg.ID] / maxSimilarity
+		}
+		score := localCollaborativeWeight*collaborative +
+			localContentWeight*profile.categoryAffinity(bg) +
+			localQualityWeight*math.Min(bg.RatingAvg/groupRatingScale, 1)
+		candidates = append(candidates, candidate{bg: bg, score: score})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].bg.PopularityScore > candidates[j].bg.PopularityScore
+	})
+
+	recommendations := make([]Boardgame, 0, min(limit, len(candidates)))
+	for _, cand := range candidates {
+		if len(recommendations) == limit {
+			break
+		}
+		recommendations = append(recommendations, toRecoBoardgame(cand.bg))
+	}
+	return recommendations, nil
+}
+
+// GetPopularBoardgames returns the limit games with the highest popularity score
+func (c *LocalRecommendationClient) GetPopularBoardgames(ctx context.Context, limit int) ([]Boardgame, error) {
+	catalogue, err := c.bgService.GetAllBoardgames()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load boardgames: %w", err)
+	}
+	return popular(catalogue, limit), nil
+}
+
+// GetAllBoardgames returns the catalogue from the database
+func (c *LocalRecommendationClient) GetAllBoardgames(ctx context.Context) ([]Boardgame, error) {
+	catalogue, err := c.bgService.GetAllBoardgames()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load boardgames: %w", err)
+	}
+	boardgames := make([]Boardgame, 0, len(catalogue))
+	for _, bg := range catalogue {
+		boardgames = append(boardgames, toRecoBoardgame(bg))
+	}
+	return boardgames, nil
+}
+
+// GetUserActions derives the user's like, favorite and rating actions from user_states
+func (c *LocalRecommendationClient) GetUserActions(ctx context.Context, userID string) ([]UserAction, error) {
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID %q: %w", userID, err)
+	}
+	states, err := c.userStateRepo.GetAllByUserID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := []UserAction{}
+	for _, st := range states {
+		action := UserAction{
+			UserID:      userID,
+			BoardgameID: strconv.Itoa(st.BoardgameID),
+			ActionTime:  st.UpdatedAt,
+		}
+		if st.Liked {
+			action.ActionType, action.ActionValue = "like", 1
+			actions = append(actions, action)
+		}
+		if st.Favorited {
+			action.ActionType, action.ActionValue = "favorite", 1
+			actions = append(actions, action)
+		}
+		if st.Rating > 0 {
+			action.ActionType, action.ActionValue = "rating", st.Rating
+			actions = append(actions, action)
+		}
+	}
+	return actions, nil
+}
+
+// SendUserAction is not supported; the local recommender reads user_states directly
+func (c *LocalRecommendationClient) SendUserAction(ctx context.Context, action UserAction) error {
+	return ErrNotSupported
+}
+
+// SendAllBoardgames is not supported; the local recommender reads the catalogue directly
+func (c *LocalRecommendationClient) SendAllBoardgames(ctx context.Context, boardgames []Boardgame) error {
+	return ErrNotSupported
+}
+
+// GetBoardgameActions is not supported by the local recommender
+func (c *LocalRecommendationClient) GetBoardgameActions(ctx context.Context, boardgameID string) ([]UserAction, error) {
+	return nil, ErrNotSupported
+}
+
+func popular(catalogue []models.BoardGame, limit int) []Boardgame {
+	sorted := make([]models.BoardGame, len(catalogue))
+	copy(sorted, catalogue)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].PopularityScore > sorted[j].PopularityScore
+	})
+	if limit >= 0 && len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+
+	boardgames := make([]Boardgame, 0, len(sorted))
+	for _, bg := range sorted {
+		boardgames = append(boardgames, toRecoBoardgame(bg))
+	}
+	return boardgames
+}
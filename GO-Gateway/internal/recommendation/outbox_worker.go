@@ -0,0 +1,239 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"guru-game/internal/db/repository/outbox"
+)
+
+// Outbox worker defaults, overridable with OUTBOX_* environment variables
+const (
+	defaultOutboxPollInterval = 5 * time.Second
+	defaultOutboxBatchSize    = 100
+	defaultOutboxConcurrency  = 4
+	defaultOutboxMaxAttempts  = 10
+	defaultOutboxLease        = 10 * time.Minute
+	outboxBaseRetryDelay      = 2 * time.Second
+	outboxMaxRetryDelay       = 30 * time.Minute
+	outboxMaxIdleDelay        = 5 * time.Minute
+	outboxRetention           = 7 * 24 * time.Hour
+)
+
+// OutboxWorker delivers recommendation_outbox messages through RecommendationClient.SendUserAction.
+// Each user's messages are sent one at a time in order; different users are sent in parallel,
+// bounded by OUTBOX_CONCURRENCY. Failed messages are retried with exponential backoff and
+// dead-lettered after OUTBOX_MAX_ATTEMPTS. Claimed messages are leased for OUTBOX_LEASE, which
+// must outlast the delivery of a batch, and no transaction is held open meanwhile. While the recommender keeps failing the worker
+// polls less often, so a backlog builds up in the table rather than in memory.
+type OutboxWorker struct {
+	repo         outbox.OutboxRepository
+	client       RecommendationClient
+	pollInterval time.Duration
+	batchSize    int
+	concurrency  int
+	maxAttempts  int
+	lease        time.Duration
+	wake         chan struct{}
+}
+
+// NewOutboxWorker creates a new OutboxWorker
+func NewOutboxWorker(repo outbox.OutboxRepository, client RecommendationClient) *OutboxWorker {
+	return &OutboxWorker{
+		repo:         repo,
+		client:       client,
+		pollInterval: envDuration("OUTBOX_POLL_INTERVAL", defaultOutboxPollInterval),
+		batchSize:    envInt("OUTBOX_BATCH_SIZE", defaultOutboxBatchSize),
+		concurrency:  envInt("OUTBOX_CONCURRENCY", defaultOutboxConcurrency),
+		maxAttempts:  envInt("OUTBOX_MAX_ATTEMPTS", defaultOutboxMaxAttempts),
+		lease:        envDuration("OUTBOX_LEASE", defaultOutboxLease),
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// Notify wakes the worker after new messages were committed. It never blocks.
+func (w *OutboxWorker) Notify() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start runs the delivery loop until ctx is cancelled
+func (w *OutboxWorker) Start(ctx context.Context) {
+	go func() {
+		failedRounds := 0
+		lastPurge := time.Time{}
+		for {
+			claimed, failed, err := w.deliverOnce(ctx)
+			if err != nil {
+				log.Printf("❌ Outbox delivery failed: %v", err)
+			}
+
+			switch {
+			case err != nil || (claimed > 0 && failed == claimed):
+				failedRounds++
+			default:
+				failedRounds = 0
+			}
+
+			if time.Since(lastPurge) > time.Hour {
+				if n, err := w.repo.PurgeDelivered(ctx, outboxRetention); err == nil && n > 0 {
+					log.Printf("🧹 Purged %d delivered outbox messages", n)
+				}
+				lastPurge = time.Now()
+			}
+
+			// A full batch that went through means more is probably waiting
+			if claimed == w.batchSize && failedRounds == 0 {
+				if ctx.Err() != nil {
+					return
+				}
+				continue
+			}
+
+			delay := w.pollInterval
+			if failedRounds > 0 {
+				delay = backoff(w.pollInterval, failedRounds, outboxMaxIdleDelay)
+			}
+			timer := time.NewTimer(delay)
+		wait:
+			for {
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-w.wake:
+					// New writes don't cut a backoff short
+					if failedRounds == 0 {
+						timer.Stop()
+						break wait
+					}
+				case <-timer.C:
+					break wait
+				}
+			}
+		}
+	}()
+}
+
+// deliverOnce delivers one batch and reports how many messages were claimed and how many failed
+func (w *OutboxWorker) deliverOnce(ctx context.Context) (claimed, failed int, err error) {
+	claimed, err = w.repo.DeliverBatch(ctx, w.batchSize, w.lease, func(msgs []outbox.Message) []outbox.Result {
+		results := w.deliver(ctx, msgs)
+		for _, r := range results {
+			if r.Err != nil {
+				failed++
+			}
+		}
+		return results
+	})
+	return claimed, failed, err
+}
+
+// deliver sends msgs grouped by user. Within a user, the first failure stops the rest of
+// that user's messages; they stay pending behind it and keep their order.
+func (w *OutboxWorker) deliver(ctx context.Context, msgs []outbox.Message) []outbox.Result {
+	var users []int64
+	byUser := make(map[int64][]outbox.Message)
+	for _, m := range msgs {
+		if _, ok := byUser[m.UserID]; !ok {
+			users = append(users, m.UserID)
+		}
+		byUser[m.UserID] = append(byUser[m.UserID], m)
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results []outbox.Result
+		sem     = make(chan struct{}, w.concurrency)
+	)
+	for _, userID := range users {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(queue []outbox.Message) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			for _, m := range queue {
+				res := w.send(ctx, m)
+				mu.Lock()
+				results = append(results, res)
+				mu.Unlock()
+				if res.Err != nil {
+					return
+				}
+			}
+		}(byUser[userID])
+	}
+	wg.Wait()
+
+	return results
+}
+
+func (w *OutboxWorker) send(ctx context.Context, m outbox.Message) outbox.Result {
+	err := w.client.SendUserAction(ctx, UserAction{
+		UserID:      strconv.FormatInt(m.UserID, 10),
+		BoardgameID: strconv.Itoa(m.BoardgameID),
+		ActionType:  m.ActionType,
+		ActionValue: m.ActionValue,
+		ActionTime:  m.ActionTime,
+	})
+	if err == nil {
+		return outbox.Result{ID: m.ID}
+	}
+
+	attempt := m.Attempts + 1
+	if attempt >= w.maxAttempts {
+		log.Printf("⚠️ Giving up on outbox message %d (%s for user %d) after %d attempts: %v", m.ID, m.ActionType, m.UserID, attempt, err)
+		return outbox.Result{ID: m.ID, Err: err, Dead: true}
+	}
+	return outbox.Result{
+		ID:      m.ID,
+		Err:     fmt.Errorf("attempt %d: %w", attempt, err),
+		RetryAt: time.Now().Add(backoff(outboxBaseRetryDelay, attempt, outboxMaxRetryDelay)),
+	}
+}
+
+// backoff returns base doubled for each attempt after the first, capped at max, with ±20% jitter
+func backoff(base time.Duration, attempt int, max time.Duration) time.Duration {
+	d := base
+	for i := 1; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	jitter := time.Duration(rand.Int63n(int64(d)/5 + 1))
+	if rand.Intn(2) == 0 {
+		return d - jitter
+	}
+	return d + jitter
+}
+
+func envInt(key string, fallback int) int {
+	if v := os.Getenv(key); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+		log.Printf("⚠️ Invalid %s %q, using %d", key, v, fallback)
+	}
+	return fallback
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+		log.Printf("⚠️ Invalid %s %q, using %v", key, v, fallback)
+	}
+	return fallback
+}
@@ -0,0 +1,31 @@
+package recommendation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    time.Duration
+		attempt int
+		max     time.Duration
+		want    time.Duration
+	}{
+		{"first attempt is the base", time.Second, 1, time.Minute, time.Second},
+		{"doubles per attempt", time.Second, 3, time.Minute, 4 * time.Second},
+		{"capped at max", time.Second, 20, time.Minute, time.Minute},
+		{"zero attempt is the base", time.Second, 0, time.Minute, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lo, hi := tt.want-tt.want/5, tt.want+tt.want/5
+			for i := 0; i < 100; i++ {
+				if got := backoff(tt.base, tt.attempt, tt.max); got < lo || got > hi {
+					t.Fatalf("backoff(%v, %d, %v) = %v, want within ±20%% of %v", tt.base, tt.attempt, tt.max, got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package recommendation
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+)
+
+// HeaderRequestID carries the gateway's request ID to the ML service
+const HeaderRequestID = fiber.HeaderXRequestID
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying id, which is sent with every call made to
+// the ML service under that context
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID stored by WithRequestID, or ""
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// requestContext returns the context for work done on behalf of c. It carries the request
+// ID and is cancelled when the server shuts down; each call to the ML service is bounded by
+// its own timeout. The returned cancel must be called when the handler is done.
+func requestContext(c *fiber.Ctx) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(c.UserContext())
+
+	id, _ := c.Locals(requestid.ConfigDefault.ContextKey).(string)
+	if id == "" {
+		id = c.Get(HeaderRequestID)
+	}
+	if id != "" {
+		ctx = WithRequestID(ctx, id)
+	}
+
+	// The handler goroutine owns c, so only the shutdown channel is handed to the watcher
+	shutdown := c.Context().Done()
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-shutdown:
+			cancel()
+		}
+	}()
+
+	return ctx, cancel
+}
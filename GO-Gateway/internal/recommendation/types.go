@@ -23,6 +23,11 @@ type Boardgame struct {
 	RatingCount     int     `json:"rating_count"`
 	PopularityScore float64 `json:"popularity_score"`
 	ImageURL        string  `json:"image_url"`
+
+	// Community poll results, sent when the game has votes
+	Weight                  *float64 `json:"weight,omitempty"`
+	BestPlayerCounts        []int    `json:"best_player_counts,omitempty"`
+	RecommendedPlayerCounts []int    `json:"recommended_player_counts,omitempty"`
 }
 
 // FavoritedBoardgame represents the combined data of UserState and Boardgame for favorited items
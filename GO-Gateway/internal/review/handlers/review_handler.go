@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"guru-game/internal/auth/jwt"
+	"guru-game/internal/db/repository/reviews"
+	"guru-game/internal/review"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ReviewHandlers holds the dependencies for review handlers
+type ReviewHandlers struct {
+	Service *review.Service
+}
+
+// NewReviewHandlers creates a new ReviewHandlers instance
+func NewReviewHandlers(service *review.Service) *ReviewHandlers {
+	return &ReviewHandlers{Service: service}
+}
+
+type reviewInput struct {
+	Body string `json:"body"`
+}
+
+type reasonInput struct {
+	Reason string `json:"reason"`
+}
+
+// HandleListReviews lists the visible reviews of a game:
+// ?sort=helpful|newest|oldest|rating_high|rating_low&limit=&offset=
+func (h *ReviewHandlers) HandleListReviews(c *fiber.Ctx) error {
+	gameID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid board game ID"})
+	}
+
+	list, err := h.Service.List(c.Context(), reviews.ReviewQuery{
+		BoardgameID: gameID,
+		Sort:        c.Query("sort"),
+		Limit:       c.QueryInt("limit", review.DefaultPageSize),
+		Offset:      c.QueryInt("offset"),
+	})
+	if err != nil {
+		return reviewError(c, err)
+	}
+	return c.JSON(fiber.Map{"reviews": list})
+}
+
+// HandleGetMyReview returns the caller's review of a game, including a hidden one
+func (h *ReviewHandlers) HandleGetMyReview(c *fiber.Ctx) error {
+	userID, gameID, ok := jwt.UserAndIDParam[int](c, "id", "board game")
+	if !ok {
+		return nil
+	}
+
+	rv, err := h.Service.Mine(c.Context(), userID, gameID)
+	if err != nil {
+		return reviewError(c, err)
+	}
+	return c.JSON(rv)
+}
+
+// HandleWriteReview creates or edits the caller's review of a game they rated
+func (h *ReviewHandlers) HandleWriteReview(c *fiber.Ctx) error {
+	userID, gameID, ok := jwt.UserAndIDParam[int](c, "id", "board game")
+	if !ok {
+		return nil
+	}
+
+	var in reviewInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	rv, err := h.Service.Write(c.Context(), userID, gameID, in.Body)
+	if err != nil {
+		return reviewError(c, err)
+	}
+	return c.JSON(rv)
+}
+
+// HandleDeleteReview deletes the caller's review of a game
+func (h *ReviewHandlers) HandleDeleteReview(c *fiber.Ctx) error {
+	userID, gameID, ok := jwt.UserAndIDParam[int](c, "id", "board game")
+	if !ok {
+		return nil
+	}
+
+	if err := h.Service.Delete(c.Context(), userID, gameID); err != nil {
+		return reviewError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleReviewHistory returns the earlier versions of a review
+func (h *ReviewHandlers) HandleReviewHistory(c *fiber.Ctx) error {
+	reviewID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid review ID"})
+	}
+
+	revisions, err := h.Service.History(c.Context(), reviewID)
+	if err != nil {
+		return reviewError(c, err)
+	}
+	return c.JSON(fiber.Map{"revisions": revisions})
+}
+
+// HandleVoteHelpful marks a review as helpful for the caller
+func (h *ReviewHandlers) HandleVoteHelpful(c *fiber.Ctx) error {
+	return h.vote(c, true)
+}
+
+// HandleUnvoteHelpful takes back the caller's helpful vote
+func (h *ReviewHandlers) HandleUnvoteHelpful(c *fiber.Ctx) error {
+	return h.vote(c, false)
+}
+
+func (h *ReviewHandlers) vote(c *fiber.Ctx, helpful bool) error {
+	userID, reviewID, ok := jwt.UserAndIDParam[int64](c, "id", "review")
+	if !ok {
+		return nil
+	}
+
+	count, err := h.Service.Vote(c.Context(), userID, reviewID, helpful)
+	if err != nil {
+		return reviewError(c, err)
+	}
+	return c.JSON(fiber.Map{"review_id": reviewID, "helpful_count": count})
+}
+
+// HandleReportReview flags a review for moderators
+func (h *ReviewHandlers) HandleReportReview(c *fiber.Ctx) error {
+	userID, reviewID, ok := jwt.UserAndIDParam[int64](c, "id", "review")
+	if !ok {
+		return nil
+	}
+
+	var in reasonInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	if err := h.Service.Report(c.Context(), userID, reviewID, in.Reason); err != nil {
+		return reviewError(c, err)
+	}
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "Review reported"})
+}
+
+// HandleModerationQueue lists reported reviews for moderators: ?limit=&offset=
+func (h *ReviewHandlers) HandleModerationQueue(c *fiber.Ctx) error {
+	items, err := h.Service.Queue(c.Context(), c.QueryInt("limit", review.DefaultPageSize), c.QueryInt("offset"))
+	if err != nil {
+		return reviewError(c, err)
+	}
+	return c.JSON(fiber.Map{"queue": items})
+}
+
+// HandleHideReview hides a review and resolves its reports
+func (h *ReviewHandlers) HandleHideReview(c *fiber.Ctx) error {
+	moderatorID, reviewID, ok := jwt.UserAndIDParam[int64](c, "id", "review")
+	if !ok {
+		return nil
+	}
+
+	var in reasonInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	rv, err := h.Service.Hide(c.Context(), moderatorID, reviewID, in.Reason)
+	if err != nil {
+		return reviewError(c, err)
+	}
+	return c.JSON(rv)
+}
+
+// HandleRestoreReview makes a hidden review visible again and resolves its reports
+func (h *ReviewHandlers) HandleRestoreReview(c *fiber.Ctx) error {
+	moderatorID, reviewID, ok := jwt.UserAndIDParam[int64](c, "id", "review")
+	if !ok {
+		return nil
+	}
+
+	rv, err := h.Service.Restore(c.Context(), moderatorID, reviewID)
+	if err != nil {
+		return reviewError(c, err)
+	}
+	return c.JSON(rv)
+}
+
+func reviewError(c *fiber.Ctx, err error) error {
+	var validationErr *review.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": validationErr.Error()})
+	case errors.Is(err, reviews.ErrNotRated):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, reviews.ErrReviewNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Review not found"})
+	case errors.Is(err, reviews.ErrAlreadyReported):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, review.ErrOwnReview):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	default:
+		log.Println("Review operation failed ->", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Review operation failed"})
+	}
+}
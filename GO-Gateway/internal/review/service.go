@@ -0,0 +1,159 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"guru-game/internal/db/repository/reviews"
+	"guru-game/models"
+)
+
+var (
+	// ErrOwnReview is returned when a user votes on or reports their own review
+	ErrOwnReview = errors.New("you cannot vote on or report your own review")
+)
+
+// Limits on review input
+const (
+	MinBodyLength   = 10
+	MaxBodyLength   = 10000
+	MaxReasonLength = 500
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// ValidationError reports invalid review input
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string { return e.Err.Error() }
+
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// Service manages reviews, helpful votes, reports and moderation
+type Service struct {
+	repo reviews.ReviewRepository
+}
+
+// NewService creates a new review Service
+func NewService(repo reviews.ReviewRepository) *Service {
+	return &Service{repo: repo}
+}
+
+// Write creates or edits userID's review of a game they have rated
+func (s *Service) Write(ctx context.Context, userID int64, boardgameID int, body string) (*models.Review, error) {
+	body = strings.TrimSpace(body)
+	if n := utf8.RuneCountInString(body); n < MinBodyLength || n > MaxBodyLength {
+		return nil, &ValidationError{Err: fmt.Errorf("review must be between %d and %d characters", MinBodyLength, MaxBodyLength)}
+	}
+	return s.repo.Upsert(ctx, userID, boardgameID, body)
+}
+
+// Delete removes userID's review of a game
+func (s *Service) Delete(ctx context.Context, userID int64, boardgameID int) error {
+	return s.repo.Delete(ctx, userID, boardgameID)
+}
+
+// Mine returns userID's review of a game, including a hidden one
+func (s *Service) Mine(ctx context.Context, userID int64, boardgameID int) (*models.Review, error) {
+	return s.repo.GetByUser(ctx, userID, boardgameID)
+}
+
+// List returns a page of visible reviews of a game
+func (s *Service) List(ctx context.Context, q reviews.ReviewQuery) ([]models.Review, error) {
+	if q.Sort == "" {
+		q.Sort = reviews.SortHelpful
+	}
+	if !reviews.ValidSort(q.Sort) {
+		return nil, &ValidationError{Err: fmt.Errorf("sort must be one of %s, %s, %s, %s, %s",
+			reviews.SortHelpful, reviews.SortNewest, reviews.SortOldest, reviews.SortRatingHigh, reviews.SortRatingLow)}
+	}
+	normalizePage(&q.Limit, &q.Offset)
+	return s.repo.ListForGame(ctx, q)
+}
+
+// History returns the earlier versions of a visible review
+func (s *Service) History(ctx context.Context, reviewID int64) ([]models.ReviewRevision, error) {
+	if _, err := s.visible(ctx, reviewID); err != nil {
+		return nil, err
+	}
+	return s.repo.History(ctx, reviewID)
+}
+
+// Vote marks a review as helpful for userID, or takes the vote back, and returns the new count
+func (s *Service) Vote(ctx context.Context, userID, reviewID int64, helpful bool) (int, error) {
+	rv, err := s.visible(ctx, reviewID)
+	if err != nil {
+		return 0, err
+	}
+	if rv.UserID == userID {
+		return 0, ErrOwnReview
+	}
+	return s.repo.SetVote(ctx, reviewID, userID, helpful)
+}
+
+// Report flags a review for moderators
+func (s *Service) Report(ctx context.Context, userID, reviewID int64, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || utf8.RuneCountInString(reason) > MaxReasonLength {
+		return &ValidationError{Err: fmt.Errorf("reason is required and must be at most %d characters", MaxReasonLength)}
+	}
+	rv, err := s.visible(ctx, reviewID)
+	if err != nil {
+		return err
+	}
+	if rv.UserID == userID {
+		return ErrOwnReview
+	}
+	return s.repo.Report(ctx, reviewID, userID, reason)
+}
+
+// Queue returns reported reviews waiting for a moderator
+func (s *Service) Queue(ctx context.Context, limit, offset int) ([]models.ModerationItem, error) {
+	normalizePage(&limit, &offset)
+	return s.repo.ModerationQueue(ctx, limit, offset)
+}
+
+// Hide hides a review from everyone but its author and resolves its reports
+func (s *Service) Hide(ctx context.Context, moderatorID, reviewID int64, reason string) (*models.Review, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || utf8.RuneCountInString(reason) > MaxReasonLength {
+		return nil, &ValidationError{Err: fmt.Errorf("reason is required and must be at most %d characters", MaxReasonLength)}
+	}
+	return s.repo.Moderate(ctx, reviewID, moderatorID, models.ReviewHidden, reason)
+}
+
+// Restore makes a review visible again and resolves its reports
+func (s *Service) Restore(ctx context.Context, moderatorID, reviewID int64) (*models.Review, error) {
+	return s.repo.Moderate(ctx, reviewID, moderatorID, models.ReviewVisible, "")
+}
+
+// Summary returns the review summary shown on the game detail endpoint
+func (s *Service) Summary(ctx context.Context, boardgameID int) (*models.ReviewSummary, error) {
+	return s.repo.Summary(ctx, boardgameID)
+}
+
+// visible returns a review unless it is hidden or its author no longer rates the game
+func (s *Service) visible(ctx context.Context, reviewID int64) (*models.Review, error) {
+	rv, err := s.repo.GetByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if rv.Status != models.ReviewVisible || rv.Rating <= 0 {
+		return nil, reviews.ErrReviewNotFound
+	}
+	return rv, nil
+}
+
+func normalizePage(limit, offset *int) {
+	if *limit <= 0 || *limit > MaxPageSize {
+		*limit = DefaultPageSize
+	}
+	if *offset < 0 {
+		*offset = 0
+	}
+}
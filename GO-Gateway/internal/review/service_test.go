@@ -0,0 +1,191 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"guru-game/internal/db/repository/reviews"
+	"guru-game/models"
+)
+
+// fakeRepo holds reviews by ID and records which writes reached it; other
+// ReviewRepository methods are not used
+type fakeRepo struct {
+	reviews.ReviewRepository
+	reviews map[int64]*models.Review
+	query   *reviews.ReviewQuery
+	writes  []string
+}
+
+func (r *fakeRepo) GetByID(ctx context.Context, id int64) (*models.Review, error) {
+	rv, ok := r.reviews[id]
+	if !ok {
+		return nil, reviews.ErrReviewNotFound
+	}
+	copied := *rv
+	return &copied, nil
+}
+
+func (r *fakeRepo) History(ctx context.Context, reviewID int64) ([]models.ReviewRevision, error) {
+	return []models.ReviewRevision{}, nil
+}
+
+func (r *fakeRepo) SetVote(ctx context.Context, reviewID, userID int64, helpful bool) (int, error) {
+	r.writes = append(r.writes, "vote")
+	return 1, nil
+}
+
+func (r *fakeRepo) Report(ctx context.Context, reviewID, userID int64, reason string) error {
+	r.writes = append(r.writes, "report")
+	return nil
+}
+
+func (r *fakeRepo) ListForGame(ctx context.Context, q reviews.ReviewQuery) ([]models.Review, error) {
+	r.query = &q
+	return []models.Review{}, nil
+}
+
+func TestVisible(t *testing.T) {
+	tests := []struct {
+		name    string
+		review  models.Review
+		wantErr error
+	}{
+		{"visible review", models.Review{Status: models.ReviewVisible, Rating: 8}, nil},
+		{"hidden review", models.Review{Status: models.ReviewHidden, Rating: 8}, reviews.ErrReviewNotFound},
+		{"rating removed", models.Review{Status: models.ReviewVisible, Rating: 0}, reviews.ErrReviewNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rv := tt.review
+			rv.ID, rv.UserID = 10, 1
+			repo := &fakeRepo{reviews: map[int64]*models.Review{10: &rv}}
+			s := NewService(repo)
+
+			if _, err := s.History(context.Background(), 10); !errors.Is(err, tt.wantErr) {
+				t.Errorf("History() = %v, want %v", err, tt.wantErr)
+			}
+			if _, err := s.Vote(context.Background(), 2, 10, true); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Vote() = %v, want %v", err, tt.wantErr)
+			}
+			if err := s.Report(context.Background(), 2, 10, "spam"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Report() = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && len(repo.writes) != 0 {
+				t.Errorf("repository writes = %v, want none", repo.writes)
+			}
+		})
+	}
+}
+
+func TestOwnReview(t *testing.T) {
+	const author, reader = 1, 2
+
+	tests := []struct {
+		name      string
+		userID    int64
+		vote      bool // vote on the review, otherwise report it
+		wantErr   error
+		wantWrite string
+	}{
+		{"reader votes", reader, true, nil, "vote"},
+		{"reader reports", reader, false, nil, "report"},
+		{"author votes", author, true, ErrOwnReview, ""},
+		{"author reports", author, false, ErrOwnReview, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepo{reviews: map[int64]*models.Review{
+				10: {ID: 10, UserID: author, Status: models.ReviewVisible, Rating: 8},
+			}}
+			s := NewService(repo)
+			var err error
+			if tt.vote {
+				_, err = s.Vote(context.Background(), tt.userID, 10, true)
+			} else {
+				err = s.Report(context.Background(), tt.userID, 10, "spam")
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			var wantWrites []string
+			if tt.wantWrite != "" {
+				wantWrites = []string{tt.wantWrite}
+			}
+			if strings.Join(repo.writes, ",") != strings.Join(wantWrites, ",") {
+				t.Errorf("repository writes = %v, want %v", repo.writes, wantWrites)
+			}
+		})
+	}
+}
+
+func TestVisibleMissingReview(t *testing.T) {
+	repo := &fakeRepo{}
+	if _, err := NewService(repo).Vote(context.Background(), 2, 99, true); !errors.Is(err, reviews.ErrReviewNotFound) {
+		t.Fatalf("Vote() = %v, want ErrReviewNotFound", err)
+	}
+}
+
+func TestListSort(t *testing.T) {
+	tests := []struct {
+		sort     string
+		wantSort string
+		wantErr  bool
+	}{
+		{"", reviews.SortHelpful, false},
+		{reviews.SortHelpful, reviews.SortHelpful, false},
+		{reviews.SortNewest, reviews.SortNewest, false},
+		{reviews.SortOldest, reviews.SortOldest, false},
+		{reviews.SortRatingHigh, reviews.SortRatingHigh, false},
+		{reviews.SortRatingLow, reviews.SortRatingLow, false},
+		{"top", "", true},
+		{"s.rating DESC", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			repo := &fakeRepo{}
+			_, err := NewService(repo).List(context.Background(), reviews.ReviewQuery{BoardgameID: 7, Sort: tt.sort})
+			if tt.wantErr {
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) || !strings.Contains(err.Error(), "sort must be one of") {
+					t.Fatalf("List() = %v, want sort validation error", err)
+				}
+				if repo.query != nil {
+					t.Error("invalid sort reached the repository")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("List() = %v", err)
+			}
+			if repo.query.Sort != tt.wantSort {
+				t.Errorf("sort = %q, want %q", repo.query.Sort, tt.wantSort)
+			}
+		})
+	}
+}
+
+func TestNormalizePage(t *testing.T) {
+	tests := []struct {
+		name                  string
+		limit, offset         int
+		wantLimit, wantOffset int
+	}{
+		{"defaults", 0, 0, DefaultPageSize, 0},
+		{"kept", 20, 40, 20, 40},
+		{"maximum kept", MaxPageSize, 0, MaxPageSize, 0},
+		{"over the maximum", MaxPageSize + 1, 0, DefaultPageSize, 0},
+		{"negative", -5, -10, DefaultPageSize, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, offset := tt.limit, tt.offset
+			normalizePage(&limit, &offset)
+			if limit != tt.wantLimit || offset != tt.wantOffset {
+				t.Errorf("page = %d/%d, want %d/%d", limit, offset, tt.wantLimit, tt.wantOffset)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"guru-game/internal/auth/jwt"
+	"guru-game/internal/db/repository/social"
+	socialservice "guru-game/internal/social"
+	"guru-game/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SocialHandlers holds the dependencies for follow graph and feed handlers
+type SocialHandlers struct {
+	Service *socialservice.Service
+}
+
+// NewSocialHandlers creates a new SocialHandlers instance
+func NewSocialHandlers(service *socialservice.Service) *SocialHandlers {
+	return &SocialHandlers{Service: service}
+}
+
+// HandleFollow follows the :user_id user, or sends a request if their account is private
+func (h *SocialHandlers) HandleFollow(c *fiber.Ctx) error {
+	userID, targetID, ok := jwt.UserAndIDParam[int64](c, "user_id", "user")
+	if !ok {
+		return nil
+	}
+
+	status, err := h.Service.Follow(c.Context(), userID, targetID)
+	if err != nil {
+		return socialError(c, err)
+	}
+	return c.JSON(fiber.Map{"user_id": targetID, "status": status})
+}
+
+// HandleUnfollow stops following the :user_id user, or withdraws the request
+func (h *SocialHandlers) HandleUnfollow(c *fiber.Ctx) error {
+	userID, targetID, ok := jwt.UserAndIDParam[int64](c, "user_id", "user")
+	if !ok {
+		return nil
+	}
+
+	if err := h.Service.Unfollow(c.Context(), userID, targetID); err != nil {
+		return socialError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleRemoveFollower stops the :user_id user following the caller
+func (h *SocialHandlers) HandleRemoveFollower(c *fiber.Ctx) error {
+	userID, followerID, ok := jwt.UserAndIDParam[int64](c, "user_id", "user")
+	if !ok {
+		return nil
+	}
+
+	if err := h.Service.RemoveFollower(c.Context(), userID, followerID); err != nil {
+		return socialError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleAcceptRequest accepts the follow request from the :user_id user
+func (h *SocialHandlers) HandleAcceptRequest(c *fiber.Ctx) error {
+	userID, followerID, ok := jwt.UserAndIDParam[int64](c, "user_id", "user")
+	if !ok {
+		return nil
+	}
+
+	if err := h.Service.Accept(c.Context(), userID, followerID); err != nil {
+		return socialError(c, err)
+	}
+	return c.JSON(fiber.Map{"user_id": followerID, "status": models.FollowAccepted})
+}
+
+// HandleDeclineRequest declines the follow request from the :user_id user
+func (h *SocialHandlers) HandleDeclineRequest(c *fiber.Ctx) error {
+	userID, followerID, ok := jwt.UserAndIDParam[int64](c, "user_id", "user")
+	if !ok {
+		return nil
+	}
+
+	if err := h.Service.Decline(c.Context(), userID, followerID); err != nil {
+		return socialError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleListFollowers lists the caller's followers
+func (h *SocialHandlers) HandleListFollowers(c *fiber.Ctx) error {
+	return h.list(c, "followers", h.Service.Followers)
+}
+
+// HandleListRequests lists the follow requests waiting for the caller
+func (h *SocialHandlers) HandleListRequests(c *fiber.Ctx) error {
+	return h.list(c, "requests", h.Service.Requests)
+}
+
+// HandleListFollowing lists the users the caller follows or asked to follow
+func (h *SocialHandlers) HandleListFollowing(c *fiber.Ctx) error {
+	return h.list(c, "following", h.Service.Following)
+}
+
+// HandleListFriends lists the caller's mutual follows
+func (h *SocialHandlers) HandleListFriends(c *fiber.Ctx) error {
+	return h.list(c, "friends", h.Service.Friends)
+}
+
+func (h *SocialHandlers) list(c *fiber.Ctx, key string, fetch func(ctx context.Context, userID int64) ([]models.Follow, error)) error {
+	userID, ok := jwt.UserIDFromCtx(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	follows, err := fetch(c.Context(), userID)
+	if err != nil {
+		return socialError(c, err)
+	}
+	return c.JSON(fiber.Map{key: follows})
+}
+
+// HandleGetPrivacy returns the caller's privacy settings
+func (h *SocialHandlers) HandleGetPrivacy(c *fiber.Ctx) error {
+	userID, ok := jwt.UserIDFromCtx(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	settings, err := h.Service.Privacy(c.Context(), userID)
+	if err != nil {
+		return socialError(c, err)
+	}
+	return c.JSON(settings)
+}
+
+// HandleUpdatePrivacy replaces the caller's privacy settings
+func (h *SocialHandlers) HandleUpdatePrivacy(c *fiber.Ctx) error {
+	userID, ok := jwt.UserIDFromCtx(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var settings models.PrivacySettings
+	if err := c.BodyParser(&settings); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	saved, err := h.Service.SetPrivacy(c.Context(), userID, &settings)
+	if err != nil {
+		return socialError(c, err)
+	}
+	return c.JSON(saved)
+}
+
+// HandleFeed returns the caller's activity feed: ?cursor=&limit=
+func (h *SocialHandlers) HandleFeed(c *fiber.Ctx) error {
+	userID, ok := jwt.UserIDFromCtx(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	page, err := h.Service.Feed(c.Context(), userID, c.Query("cursor"), c.QueryInt("limit", socialservice.DefaultFeedSize))
+	if err != nil {
+		return socialError(c, err)
+	}
+	return c.JSON(page)
+}
+
+func socialError(c *fiber.Ctx, err error) error {
+	var validationErr *socialservice.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": validationErr.Error()})
+	case errors.Is(err, socialservice.ErrSelfFollow):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, social.ErrUserNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+	case errors.Is(err, social.ErrFollowNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Follow not found"})
+	default:
+		log.Println("Social operation failed ->", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Social operation failed"})
+	}
+}
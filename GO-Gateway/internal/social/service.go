@@ -0,0 +1,172 @@
+package social
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"guru-game/internal/db/repository/social"
+	"guru-game/models"
+)
+
+// ErrSelfFollow is returned when a user tries to follow themselves
+var ErrSelfFollow = errors.New("you cannot follow yourself")
+
+// Page sizes for Feed
+const (
+	DefaultFeedSize = 20
+	MaxFeedSize     = 100
+)
+
+// ValidationError reports invalid social input
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string { return e.Err.Error() }
+
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// FeedPage is one page of the activity feed. NextCursor is empty on the last page.
+type FeedPage struct {
+	Items      []models.FeedItem `json:"items"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// Service manages the follow graph, privacy settings and the activity feed
+type Service struct {
+	repo social.SocialRepository
+}
+
+// NewService creates a new social Service
+func NewService(repo social.SocialRepository) *Service {
+	return &Service{repo: repo}
+}
+
+// Follow makes userID follow targetID, or sends a follow request to a private account,
+// and returns the resulting status
+func (s *Service) Follow(ctx context.Context, userID, targetID int64) (string, error) {
+	if userID == targetID {
+		return "", ErrSelfFollow
+	}
+	return s.repo.Follow(ctx, userID, targetID)
+}
+
+// Unfollow stops userID following targetID, or withdraws the request
+func (s *Service) Unfollow(ctx context.Context, userID, targetID int64) error {
+	return s.repo.Unfollow(ctx, userID, targetID)
+}
+
+// RemoveFollower stops followerID following userID
+func (s *Service) RemoveFollower(ctx context.Context, userID, followerID int64) error {
+	return s.repo.Unfollow(ctx, followerID, userID)
+}
+
+// Accept accepts a pending follow request sent to userID
+func (s *Service) Accept(ctx context.Context, userID, followerID int64) error {
+	return s.repo.Respond(ctx, userID, followerID, true)
+}
+
+// Decline declines a pending follow request sent to userID
+func (s *Service) Decline(ctx context.Context, userID, followerID int64) error {
+	return s.repo.Respond(ctx, userID, followerID, false)
+}
+
+// Followers returns the accepted followers of userID
+func (s *Service) Followers(ctx context.Context, userID int64) ([]models.Follow, error) {
+	return s.repo.ListFollowers(ctx, userID, models.FollowAccepted)
+}
+
+// Requests returns the pending follow requests sent to userID
+func (s *Service) Requests(ctx context.Context, userID int64) ([]models.Follow, error) {
+	return s.repo.ListFollowers(ctx, userID, models.FollowPending)
+}
+
+// Following returns the users userID follows, including pending requests
+func (s *Service) Following(ctx context.Context, userID int64) ([]models.Follow, error) {
+	return s.repo.ListFollowing(ctx, userID)
+}
+
+// Friends returns the users who follow userID and are followed back
+func (s *Service) Friends(ctx context.Context, userID int64) ([]models.Follow, error) {
+	return s.repo.ListFriends(ctx, userID)
+}
+
+// Privacy returns userID's privacy settings
+func (s *Service) Privacy(ctx context.Context, userID int64) (*models.PrivacySettings, error) {
+	return s.repo.GetPrivacy(ctx, userID)
+}
+
+// SetPrivacy saves userID's privacy settings
+func (s *Service) SetPrivacy(ctx context.Context, userID int64, settings *models.PrivacySettings) (*models.PrivacySettings, error) {
+	switch settings.FeedVisibility {
+	case "":
+		settings.FeedVisibility = models.FeedFollowers
+	case models.FeedFollowers, models.FeedFriends, models.FeedNobody:
+	default:
+		return nil, &ValidationError{Err: fmt.Errorf("feed_visibility must be one of %s, %s, %s",
+			models.FeedFollowers, models.FeedFriends, models.FeedNobody)}
+	}
+	if err := s.repo.SetPrivacy(ctx, userID, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// Feed returns a page of the activity of the users userID follows, newest first.
+// cursor is the NextCursor of the previous page, or empty for the first page.
+func (s *Service) Feed(ctx context.Context, userID int64, cursor string, limit int) (*FeedPage, error) {
+	if limit <= 0 || limit > MaxFeedSize {
+		limit = DefaultFeedSize
+	}
+	q := social.FeedQuery{ViewerID: userID, Limit: limit}
+	if cursor != "" {
+		before, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		q.Before = before
+	}
+
+	items, err := s.repo.Feed(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	page := &FeedPage{Items: items}
+	if len(items) == limit {
+		last := items[len(items)-1]
+		page.NextCursor = encodeCursor(social.FeedCursor{OccurredAt: last.OccurredAt, Type: last.Type, ID: last.SourceID})
+	}
+	return page, nil
+}
+
+// Cursors are opaque to clients: base64 of "<RFC 3339 time>|<type>|<id>"
+func encodeCursor(c social.FeedCursor) string {
+	raw := c.OccurredAt.UTC().Format(time.RFC3339Nano) + "|" + c.Type + "|" + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (*social.FeedCursor, error) {
+	invalid := &ValidationError{Err: errors.New("invalid cursor")}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 3 {
+		return nil, invalid
+	}
+	at, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, invalid
+	}
+	id, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, invalid
+	}
+	return &social.FeedCursor{OccurredAt: at, Type: parts[1], ID: id}, nil
+}
@@ -0,0 +1,56 @@
+package social
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"guru-game/internal/db/repository/social"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor social.FeedCursor
+	}{
+		{"rating", social.FeedCursor{OccurredAt: time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC), Type: "rating", ID: 42}},
+		{"sub-second time", social.FeedCursor{OccurredAt: time.Date(2026, 3, 4, 5, 6, 7, 123456789, time.UTC), Type: "play", ID: 1}},
+		{"non-UTC time", social.FeedCursor{OccurredAt: time.Date(2026, 3, 4, 12, 0, 0, 0, time.FixedZone("ICT", 7*3600)), Type: "review", ID: 9}},
+		{"large id", social.FeedCursor{OccurredAt: time.Unix(0, 0).UTC(), Type: "favorite", ID: 1<<62 + 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeCursor(encodeCursor(tt.cursor))
+			if err != nil {
+				t.Fatalf("decodeCursor() error = %v", err)
+			}
+			if !got.OccurredAt.Equal(tt.cursor.OccurredAt) || got.Type != tt.cursor.Type || got.ID != tt.cursor.ID {
+				t.Errorf("round trip = %+v, want %+v", *got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "***"},
+		{"too few parts", encode("2026-03-04T05:06:07Z|play")},
+		{"too many parts", encode("2026-03-04T05:06:07Z|play|1|2")},
+		{"bad time", encode("yesterday|play|1")},
+		{"bad id", encode("2026-03-04T05:06:07Z|play|one")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeCursor(tt.cursor)
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Errorf("decodeCursor(%q) = %v, want *ValidationError", tt.cursor, err)
+			}
+		})
+	}
+}
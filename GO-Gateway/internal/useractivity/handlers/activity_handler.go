@@ -1,11 +1,13 @@
 package handlers
 
 import (
-	"context"
-	"guru-game/internal/db/connection"
-	"guru-game/internal/recommendation"
+	"errors"
+	"fmt"
+	"guru-game/internal/auth/jwt"
+	"guru-game/internal/userstate"
 	"guru-game/models"
 	"log"
+	"strconv"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -13,24 +15,29 @@ import (
 
 // Handler for user activity
 type UserActivityHandler struct {
-	recommendationClient recommendation.RecommendationClient
+	states *userstate.Service
 }
 
 // NewUserActivityHandler creates a new handler instance
-func NewUserActivityHandler(client recommendation.RecommendationClient) *UserActivityHandler {
+func NewUserActivityHandler(states *userstate.Service) *UserActivityHandler {
 	return &UserActivityHandler{
-		recommendationClient: client,
+		states: states,
 	}
 }
 
-// HandleUserActivity receives and processes user activity logs
+// HandleUserActivity receives and processes user activity logs.
+// Like/favorite/rate activities update user_states through the same service as
+// /api/game/updateState, and every activity is appended to the activity log; a resent
+// eventID is answered with status "duplicate". Likes, ratings, views and plays reach the
+// recommendation service via the outbox worker.
 func (h *UserActivityHandler) HandleUserActivity(c *fiber.Ctx) error {
 	timestamp := time.Now().Format("2006-01-02 15:04:05")
 	log.Printf("\n\n[%s] ===== USER ACTIVITY LOG RECEIVED =====\n", timestamp)
 
-	// Log raw request body
-	rawBody := c.Body()
-	log.Printf("[%s] Raw Request Body: %s", timestamp, string(rawBody))
+	tokenUserID, ok := jwt.UserIDFromCtx(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
 
 	var activityLog models.ActivityLog
 
@@ -42,63 +49,108 @@ func (h *UserActivityHandler) HandleUserActivity(c *fiber.Ctx) error {
 		})
 	}
 
-	// Log the parsed data in a structured format - logging removed to simplify output for now
-
-	// --- Add logic to process activity and update user_states table ---
-	ctx := context.Background()
-
-	switch activityLog.Type {
-	case "LIKE_GAME":
-		log.Printf("[%s] Processing LIKE_GAME for UserID: %d, GameID: %d, IsLiked: %v", timestamp, activityLog.UserID, activityLog.Data.GameID, activityLog.Data.IsLiked)
-		// UPSERT into user_states table
-		query := `INSERT INTO user_states (user_id, boardgame_id, liked, updated_at)
-		          VALUES ($1, $2, $3, NOW())
-		          ON CONFLICT (user_id, boardgame_id) DO UPDATE SET
-		          liked = EXCLUDED.liked, updated_at = NOW()`
-		_, err := connection.DB.Exec(ctx, query, activityLog.UserID, activityLog.Data.GameID, activityLog.Data.IsLiked)
-		if err != nil {
-			log.Printf("[%s] Error updating user_states for LIKE_GAME: %v", timestamp, err)
-			// Decide how to handle error - return error to frontend?
-		}
-
-	case "FAVORITE_GAME":
-		log.Printf("[%s] Processing FAVORITE_GAME for UserID: %d, GameID: %d, IsFavorite: %v", timestamp, activityLog.UserID, activityLog.Data.GameID, activityLog.Data.IsFavorite)
-		// UPSERT into user_states table
-		query := `INSERT INTO user_states (user_id, boardgame_id, favorited, updated_at)
-		          VALUES ($1, $2, $3, NOW())
-		          ON CONFLICT (user_id, boardgame_id) DO UPDATE SET
-		          favorited = EXCLUDED.favorited, updated_at = NOW()`
-		_, err := connection.DB.Exec(ctx, query, activityLog.UserID, activityLog.Data.GameID, activityLog.Data.IsFavorite)
-		if err != nil {
-			log.Printf("[%s] Error updating user_states for FAVORITE_GAME: %v", timestamp, err)
-			// Decide how to handle error
-		}
-
-	case "RATE_GAME":
-		log.Printf("[%s] Processing RATE_GAME for UserID: %d, GameID: %d, RatingValue: %v", timestamp, activityLog.UserID, activityLog.Data.GameID, activityLog.Data.RatingValue)
-		// UPSERT into user_states table
-		query := `INSERT INTO user_states (user_id, boardgame_id, rating, updated_at)
-		          VALUES ($1, $2, $3, NOW())
-		          ON CONFLICT (user_id, boardgame_id) DO UPDATE SET
-		          rating = EXCLUDED.rating, updated_at = NOW()`
-		_, err := connection.DB.Exec(ctx, query, activityLog.UserID, activityLog.Data.GameID, activityLog.Data.RatingValue)
-		if err != nil {
-			log.Printf("[%s] Error updating user_states for RATE_GAME: %v", timestamp, err)
-			// Decide how to handle error
-		}
-
-	// TODO: Add cases for other activity types like VIEW_GAME, PLAY_GAME, etc.
+	log.Printf("[%s] Processing %s for UserID: %d, GameID: %d", timestamp, activityLog.Type, tokenUserID, activityLog.Data.GameID)
 
-	default:
-		log.Printf("[%s] Unhandled activity type: %s", timestamp, activityLog.Type)
-		// Optionally handle other types or return a different response
+	result, err := h.states.RecordActivity(c.Context(), tokenUserID, activityLog)
+	if err != nil {
+		log.Printf("[%s] Error processing %s activity: %v", timestamp, activityLog.Type, err)
+		return activityError(c, err)
+	}
+
+	response := fiber.Map{
+		"message": "Activity log received and processed successfully",
+		"status":  result.Status,
 	}
+	if result.State != nil {
+		response["state"] = result.State
+	}
+	return c.Status(fiber.StatusOK).JSON(response)
+}
 
-	// Note: Sending to Recommendation Service logic is temporarily commented out
-	// if activityLog.Type in types to be sent to recommendation service...
-	// ... send to h.recommendationClient ...
+// ActivityBatchRequest is the body of POST /user/activities/batch
+type ActivityBatchRequest struct {
+	Activities []models.ActivityLog `json:"activities"`
+}
+
+// HandleUserActivityBatch records several activities in one request and returns a result
+// per activity (accepted, duplicate or rejected) in request order. Invalid items don't fail the batch.
+func (h *UserActivityHandler) HandleUserActivityBatch(c *fiber.Ctx) error {
+	tokenUserID, ok := jwt.UserIDFromCtx(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req ActivityBatchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Failed to parse request body"})
+	}
+	if len(req.Activities) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "activities must not be empty"})
+	}
+	if len(req.Activities) > userstate.MaxActivityBatch {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"error": fmt.Sprintf("at most %d activities can be sent at once", userstate.MaxActivityBatch),
+		})
+	}
+
+	results, err := h.states.RecordActivities(c.Context(), tokenUserID, req.Activities)
+	if err != nil {
+		log.Printf("Error processing activity batch of %d for user %d: %v", len(req.Activities), tokenUserID, err)
+		return activityError(c, err)
+	}
+
+	counts := map[string]int{userstate.StatusAccepted: 0, userstate.StatusDuplicate: 0, userstate.StatusRejected: 0}
+	for _, r := range results {
+		counts[r.Status]++
+	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Activity log received and processed successfully",
+		"results": results,
+		"counts":  counts,
 	})
-}
\ No newline at end of file
+}
+
+// HandleRecentlyViewed returns the caller's recently viewed games: ?limit=
+func (h *UserActivityHandler) HandleRecentlyViewed(c *fiber.Ctx) error {
+	userID, ok := jwt.UserIDFromCtx(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	games, err := h.states.RecentlyViewed(c.Context(), userID, c.QueryInt("limit", userstate.DefaultRecentlyViewed))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to fetch recently viewed games"})
+	}
+
+	return c.JSON(fiber.Map{"games": games})
+}
+
+// HandleGameViewStats returns view counts and dwell time totals for a board game
+func (h *UserActivityHandler) HandleGameViewStats(c *fiber.Ctx) error {
+	boardgameID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid boardgame ID"})
+	}
+
+	stats, err := h.states.ViewStats(c.Context(), boardgameID)
+	if err != nil {
+		return activityError(c, err)
+	}
+
+	return c.JSON(stats)
+}
+
+// activityError maps userstate.Service errors to HTTP responses
+func activityError(c *fiber.Ctx, err error) error {
+	var validationErr *userstate.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": validationErr.Error()})
+	case errors.Is(err, userstate.ErrBoardgameNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "board game not found"})
+	case errors.Is(err, userstate.ErrForeignUser):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "you can only log your own activity"})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to process activity"})
+	}
+}
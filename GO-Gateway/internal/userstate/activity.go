@@ -0,0 +1,317 @@
+package userstate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"guru-game/internal/db/repository/activities"
+	"guru-game/internal/db/repository/outbox"
+	"guru-game/internal/db/repository/user_states"
+	"guru-game/models"
+)
+
+// Activity types accepted by RecordActivities
+const (
+	ActivityLike     = "LIKE_GAME"
+	ActivityFavorite = "FAVORITE_GAME"
+	ActivityRate     = "RATE_GAME"
+	ActivityView     = "VIEW_GAME"
+	ActivityPlay     = "PLAY_GAME"
+	ActivitySearch   = "SEARCH_GAMES"
+)
+
+// Per-item outcomes of RecordActivities
+const (
+	StatusAccepted  = "accepted"
+	StatusDuplicate = "duplicate"
+	StatusRejected  = "rejected"
+)
+
+// Limits on incoming activities
+const (
+	MaxActivityBatch = 500
+	maxEventIDLength = 128
+	maxClockSkew     = 5 * time.Minute
+	maxActivityAge   = 30 * 24 * time.Hour
+	maxDwell         = 6 * time.Hour
+)
+
+// ActivityResult is the outcome of one activity in a batch
+type ActivityResult struct {
+	Index   int                    `json:"index"`
+	EventID string                 `json:"event_id,omitempty"`
+	Status  string                 `json:"status"`
+	Error   string                 `json:"error,omitempty"`
+	State   *user_states.UserState `json:"state,omitempty"`
+	Err     error                  `json:"-"`
+}
+
+func (r *ActivityResult) reject(err error) {
+	r.Status = StatusRejected
+	r.Err = err
+	var validationErr *ValidationError
+	switch {
+	case errors.As(err, &validationErr), errors.Is(err, ErrBoardgameNotFound), errors.Is(err, ErrForeignUser):
+		r.Error = err.Error()
+	default:
+		r.Error = "failed to process activity"
+	}
+}
+
+// preparedActivity is an activity that passed validation
+type preparedActivity struct {
+	index      int
+	activity   models.ActivityLog
+	kind       string
+	occurredAt time.Time
+}
+
+// RecordActivity records a single activity for userID; see RecordActivities.
+// A rejected activity is returned as the error.
+func (s *Service) RecordActivity(ctx context.Context, userID int64, a models.ActivityLog) (*ActivityResult, error) {
+	results, err := s.RecordActivities(ctx, userID, []models.ActivityLog{a})
+	if err != nil {
+		return nil, err
+	}
+	if results[0].Err != nil {
+		return nil, results[0].Err
+	}
+	return &results[0], nil
+}
+
+// RecordActivities records a batch of activities from the frontend for userID and returns
+// one result per item, in order. Like, favorite and rate activities change user_states and
+// are applied oldest first, each logged in the same transaction as its change; views are
+// added to the per-session view totals with their dwell time; the other accepted activities
+// are appended to the activity log together. Activities whose event ID was already seen are
+// reported as duplicates and skipped, so a batch can be resent safely after an error.
+// The error is only set when the batch could not be stored at all.
+func (s *Service) RecordActivities(ctx context.Context, userID int64, batch []models.ActivityLog) ([]ActivityResult, error) {
+	if len(batch) > MaxActivityBatch {
+		return nil, &ValidationError{Err: fmt.Errorf("at most %d activities can be sent at once", MaxActivityBatch)}
+	}
+
+	now := time.Now()
+	results := make([]ActivityResult, len(batch))
+	var items []preparedActivity
+	seen := make(map[string]bool)
+	for i, a := range batch {
+		results[i] = ActivityResult{Index: i, EventID: a.EventID}
+		if a.UserID == 0 {
+			a.UserID = userID
+		}
+		p, err := prepareActivity(i, a, userID, now)
+		if err != nil {
+			results[i].reject(err)
+			continue
+		}
+		if a.EventID != "" {
+			if seen[a.EventID] {
+				results[i].Status = StatusDuplicate
+				continue
+			}
+			seen[a.EventID] = true
+		}
+		items = append(items, p)
+	}
+
+	// State changes are applied in the order they happened, not the order they arrived
+	sort.SliceStable(items, func(i, j int) bool { return items[i].occurredAt.Before(items[j].occurredAt) })
+
+	var logged []activities.BatchItem
+	var loggedIndex []int
+	changedGames := make(map[int]bool)
+	checkedGames := make(map[int]error)
+	for _, p := range items {
+		a := p.activity
+		res := &results[p.index]
+		var eventID *string
+		if a.EventID != "" {
+			eventID = &a.EventID
+		}
+
+		switch p.kind {
+		case ActivityLike, ActivityFavorite, ActivityRate:
+			change := activityChange(p.kind, a.Data)
+			change.Log = &user_states.LoggedActivity{
+				Type:       p.kind,
+				SessionID:  a.SessionID,
+				EventID:    eventID,
+				Data:       a.Data,
+				OccurredAt: p.occurredAt,
+			}
+			state, err := s.applyChange(ctx, int(userID), a.Data.GameID, change)
+			switch {
+			case errors.Is(err, user_states.ErrDuplicateEvent):
+				res.Status = StatusDuplicate
+			case err != nil:
+				res.reject(err)
+			default:
+				res.State = state
+				res.Status = StatusAccepted
+				changedGames[a.Data.GameID] = true
+			}
+			continue
+
+		case ActivityView, ActivityPlay:
+			err, ok := checkedGames[a.Data.GameID]
+			if !ok {
+				err = s.ensureBoardgame(a.Data.GameID)
+				checkedGames[a.Data.GameID] = err
+			}
+			if err != nil {
+				res.reject(err)
+				continue
+			}
+		}
+
+		item := activities.BatchItem{Activity: activities.Activity{
+			UserID:     userID,
+			Type:       p.kind,
+			SessionID:  a.SessionID,
+			EventID:    eventID,
+			Data:       a.Data,
+			OccurredAt: p.occurredAt,
+		}}
+		if p.kind == ActivityView || p.kind == ActivityPlay {
+			gameID := a.Data.GameID
+			item.Activity.BoardgameID = &gameID
+			action := outbox.ActionPlay
+			if p.kind == ActivityView {
+				action = outbox.ActionView
+				item.View = &activities.View{
+					UserID:      userID,
+					BoardgameID: gameID,
+					SessionID:   a.SessionID,
+					DwellMs:     a.Data.DwellMs,
+					ViewedAt:    p.occurredAt,
+				}
+			}
+			item.Forward = &outbox.Message{
+				UserID:      userID,
+				BoardgameID: gameID,
+				ActionType:  action,
+				ActionValue: 1,
+				ActionTime:  p.occurredAt,
+			}
+		}
+		logged = append(logged, item)
+		loggedIndex = append(loggedIndex, p.index)
+	}
+
+	duplicates, err := s.activities.InsertBatch(ctx, logged)
+	if err != nil {
+		return nil, err
+	}
+	forwarded := false
+	for i, item := range logged {
+		res := &results[loggedIndex[i]]
+		if id := item.Activity.EventID; id != nil && duplicates[*id] {
+			res.Status = StatusDuplicate
+			continue
+		}
+		res.Status = StatusAccepted
+		forwarded = forwarded || item.Forward != nil
+	}
+	if forwarded {
+		s.outbox.Notify()
+	}
+	for gameID := range changedGames {
+		s.recompute(ctx, gameID)
+	}
+
+	return results, nil
+}
+
+// prepareActivity validates everything about a that doesn't need the database
+func prepareActivity(index int, a models.ActivityLog, userID int64, now time.Time) (preparedActivity, error) {
+	if a.UserID != userID {
+		return preparedActivity{}, ErrForeignUser
+	}
+	if len(a.EventID) > maxEventIDLength {
+		return preparedActivity{}, &ValidationError{Err: fmt.Errorf("eventID must be at most %d characters", maxEventIDLength)}
+	}
+
+	occurredAt, err := parseActivityTime(a.Timestamp, now)
+	if err != nil {
+		return preparedActivity{}, err
+	}
+
+	kind := strings.ToUpper(strings.TrimSpace(a.Type))
+	switch kind {
+	case ActivityLike, ActivityFavorite, ActivityRate, ActivityView, ActivityPlay:
+		if a.Data.GameID <= 0 {
+			return preparedActivity{}, &ValidationError{Err: errors.New("gameID is required")}
+		}
+		if d := a.Data.DwellMs; d < 0 || d > maxDwell.Milliseconds() {
+			return preparedActivity{}, &ValidationError{Err: fmt.Errorf("dwellMs must be between 0 and %d", maxDwell.Milliseconds())}
+		}
+	case ActivitySearch, "SEARCH":
+		kind = ActivitySearch
+		if strings.TrimSpace(a.Data.SearchQuery) == "" {
+			return preparedActivity{}, &ValidationError{Err: errors.New("searchQuery is required for search activities")}
+		}
+	default:
+		return preparedActivity{}, &ValidationError{Err: fmt.Errorf("unsupported activity type %q", a.Type)}
+	}
+
+	return preparedActivity{index: index, activity: a, kind: kind, occurredAt: occurredAt}, nil
+}
+
+// parseActivityTime parses an RFC 3339 timestamp; empty means now. Timestamps from the
+// future (beyond clock skew) or older than maxActivityAge are rejected.
+func parseActivityTime(ts string, now time.Time) (time.Time, error) {
+	if strings.TrimSpace(ts) == "" {
+		return now, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return time.Time{}, &ValidationError{Err: fmt.Errorf("timestamp %q is not an RFC 3339 time", ts)}
+	}
+	if t.After(now.Add(maxClockSkew)) {
+		return time.Time{}, &ValidationError{Err: fmt.Errorf("timestamp %q is in the future", ts)}
+	}
+	if t.Before(now.Add(-maxActivityAge)) {
+		return time.Time{}, &ValidationError{Err: fmt.Errorf("timestamp %q is too old", ts)}
+	}
+	return t, nil
+}
+
+func activityChange(kind string, d models.ActivityData) user_states.StateChange {
+	change := user_states.StateChange{Source: user_states.SourceActivity}
+	switch kind {
+	case ActivityLike:
+		change.Liked = &d.IsLiked
+	case ActivityFavorite:
+		change.Favorited = &d.IsFavorite
+	case ActivityRate:
+		change.Rating = &d.RatingValue
+	}
+	return change
+}
+
+// Page sizes for RecentlyViewed
+const (
+	DefaultRecentlyViewed = 20
+	MaxRecentlyViewed     = 100
+)
+
+// RecentlyViewed returns the games userID viewed most recently
+func (s *Service) RecentlyViewed(ctx context.Context, userID int64, limit int) ([]activities.RecentlyViewedGame, error) {
+	if limit <= 0 || limit > MaxRecentlyViewed {
+		limit = DefaultRecentlyViewed
+	}
+	return s.activities.RecentlyViewed(ctx, userID, limit)
+}
+
+// ViewStats returns the view totals of a game
+func (s *Service) ViewStats(ctx context.Context, boardgameID int) (*activities.ViewStats, error) {
+	if err := s.ensureBoardgame(boardgameID); err != nil {
+		return nil, err
+	}
+	return s.activities.GetViewStats(ctx, boardgameID)
+}
@@ -0,0 +1,89 @@
+package userstate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"guru-game/models"
+)
+
+func TestParseActivityTime(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		ts      string
+		want    time.Time
+		wantErr bool
+	}{
+		{"empty is now", "", now, false},
+		{"blank is now", "  ", now, false},
+		{"rfc3339", "2026-03-01T11:30:00Z", now.Add(-30 * time.Minute), false},
+		{"fractional seconds and offset", "2026-03-01T18:30:00.5+07:00", now.Add(-30*time.Minute + 500*time.Millisecond), false},
+		{"within clock skew", "2026-03-01T12:04:00Z", now.Add(4 * time.Minute), false},
+		{"future", "2026-03-01T12:06:00Z", time.Time{}, true},
+		{"too old", "2026-01-01T00:00:00Z", time.Time{}, true},
+		{"not rfc3339", "01/03/2026", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseActivityTime(tt.ts, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseActivityTime(%q) error = %v, wantErr %v", tt.ts, err, tt.wantErr)
+			}
+			var validationErr *ValidationError
+			if err != nil && !errors.As(err, &validationErr) {
+				t.Errorf("parseActivityTime(%q) error %v is not a ValidationError", tt.ts, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseActivityTime(%q) = %v, want %v", tt.ts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrepareActivity(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		activity models.ActivityLog
+		wantKind string
+		wantErr  error // a specific error, checked with errors.Is
+		invalid  bool  // any ValidationError
+	}{
+		{"like", models.ActivityLog{Type: "LIKE_GAME", UserID: 1, Data: models.ActivityData{GameID: 5}}, ActivityLike, nil, false},
+		{"type is case insensitive", models.ActivityLog{Type: " view_game ", UserID: 1, Data: models.ActivityData{GameID: 5, DwellMs: 1000}}, ActivityView, nil, false},
+		{"search alias", models.ActivityLog{Type: "SEARCH", UserID: 1, Data: models.ActivityData{SearchQuery: "catan"}}, ActivitySearch, nil, false},
+		{"other user", models.ActivityLog{Type: "LIKE_GAME", UserID: 2, Data: models.ActivityData{GameID: 5}}, "", ErrForeignUser, false},
+		{"missing game", models.ActivityLog{Type: "RATE_GAME", UserID: 1}, "", nil, true},
+		{"negative dwell", models.ActivityLog{Type: "VIEW_GAME", UserID: 1, Data: models.ActivityData{GameID: 5, DwellMs: -1}}, "", nil, true},
+		{"dwell too long", models.ActivityLog{Type: "VIEW_GAME", UserID: 1, Data: models.ActivityData{GameID: 5, DwellMs: maxDwell.Milliseconds() + 1}}, "", nil, true},
+		{"empty search", models.ActivityLog{Type: "SEARCH_GAMES", UserID: 1, Data: models.ActivityData{SearchQuery: " "}}, "", nil, true},
+		{"unknown type", models.ActivityLog{Type: "SHARE_GAME", UserID: 1, Data: models.ActivityData{GameID: 5}}, "", nil, true},
+		{"event ID too long", models.ActivityLog{Type: "LIKE_GAME", UserID: 1, EventID: strings.Repeat("x", maxEventIDLength+1), Data: models.ActivityData{GameID: 5}}, "", nil, true},
+		{"bad timestamp", models.ActivityLog{Type: "LIKE_GAME", UserID: 1, Timestamp: "yesterday", Data: models.ActivityData{GameID: 5}}, "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := prepareActivity(3, tt.activity, 1, now)
+			var validationErr *ValidationError
+			switch {
+			case tt.invalid:
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("prepareActivity error = %v, want a ValidationError", err)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("prepareActivity error = %v, want %v", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatalf("prepareActivity error = %v", err)
+			default:
+				if p.kind != tt.wantKind || p.index != 3 || !p.occurredAt.Equal(now) {
+					t.Errorf("prepareActivity = %+v, want kind %s at index 3 occurring now", p, tt.wantKind)
+				}
+			}
+		})
+	}
+}
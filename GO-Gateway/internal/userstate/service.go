@@ -0,0 +1,132 @@
+package userstate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"guru-game/internal/aggregation"
+	"guru-game/internal/db/repository/activities"
+	"guru-game/internal/db/repository/boardgame"
+	"guru-game/internal/db/repository/user_states"
+)
+
+// Allowed range for ratings; 0 clears the rating
+const (
+	MinRating = 0
+	MaxRating = 10
+)
+
+var (
+	// ErrBoardgameNotFound is returned when a change targets a board game that does not exist
+	ErrBoardgameNotFound = errors.New("board game not found")
+	// ErrForeignUser is returned when an activity names a user other than the caller
+	ErrForeignUser = errors.New("activity belongs to another user")
+)
+
+// ValidationError wraps invalid input so handlers can answer 400
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string { return e.Err.Error() }
+
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// OutboxNotifier is woken after changes that queued recommendation_outbox messages
+type OutboxNotifier interface {
+	Notify()
+}
+
+// StateListener is told after a user's state changed, e.g. to drop cached recommendations
+type StateListener interface {
+	UserStateChanged(ctx context.Context, userID int)
+}
+
+// Service is the single entry point for changes to user_states. Both the game state API
+// and the activity log go through it, so validation, history, aggregates and forwarding
+// to the recommender stay consistent.
+type Service struct {
+	states     user_states.UserStateRepository
+	boardgames boardgame.BoardGameRepository
+	activities activities.ActivityRepository
+	aggregator *aggregation.Service
+	outbox     OutboxNotifier
+	listener   StateListener
+}
+
+// NewService creates a new user state Service
+func NewService(states user_states.UserStateRepository, boardgames boardgame.BoardGameRepository, activityRepo activities.ActivityRepository, aggregator *aggregation.Service, outboxNotifier OutboxNotifier, listener StateListener) *Service {
+	return &Service{
+		states:     states,
+		boardgames: boardgames,
+		activities: activityRepo,
+		aggregator: aggregator,
+		outbox:     outboxNotifier,
+		listener:   listener,
+	}
+}
+
+// Get returns a user's state for a board game (Version 0 if none is stored)
+func (s *Service) Get(ctx context.Context, userID, boardgameID int) (*user_states.UserState, error) {
+	return s.states.Get(ctx, userID, boardgameID)
+}
+
+// Events returns user state history matching q
+func (s *Service) Events(ctx context.Context, q user_states.EventQuery) ([]user_states.UserStateEvent, error) {
+	return s.states.GetEvents(ctx, q)
+}
+
+// ApplyChange validates and applies a partial state change, then refreshes the game's aggregates.
+// A stale change.ExpectedVersion returns *user_states.VersionConflictError.
+func (s *Service) ApplyChange(ctx context.Context, userID, boardgameID int, change user_states.StateChange) (*user_states.UserState, error) {
+	state, err := s.applyChange(ctx, userID, boardgameID, change)
+	if err != nil {
+		return nil, err
+	}
+	s.recompute(ctx, boardgameID)
+	return state, nil
+}
+
+// applyChange is ApplyChange without the aggregate refresh, so batches can refresh each game once
+func (s *Service) applyChange(ctx context.Context, userID, boardgameID int, change user_states.StateChange) (*user_states.UserState, error) {
+	if change.Liked == nil && change.Favorited == nil && change.Rating == nil {
+		return nil, &ValidationError{Err: errors.New("at least one of liked, favorited or rating is required")}
+	}
+	if r := change.Rating; r != nil && (*r < MinRating || *r > MaxRating) {
+		return nil, &ValidationError{Err: fmt.Errorf("rating must be between %d and %d", MinRating, MaxRating)}
+	}
+	if err := s.ensureBoardgame(boardgameID); err != nil {
+		return nil, err
+	}
+
+	state, err := s.states.ApplyChange(ctx, userID, boardgameID, change)
+	if err != nil {
+		return nil, err
+	}
+	s.outbox.Notify()
+	s.listener.UserStateChanged(ctx, userID)
+	return state, nil
+}
+
+// recompute refreshes a game's aggregates. The state is already saved, so a failure
+// is only logged; the periodic job will catch up.
+func (s *Service) recompute(ctx context.Context, boardgameID int) {
+	if err := s.aggregator.RecomputeGame(ctx, boardgameID); err != nil {
+		log.Printf("Error recomputing aggregates for game %d: %v", boardgameID, err)
+	}
+}
+
+func (s *Service) ensureBoardgame(boardgameID int) error {
+	if boardgameID <= 0 {
+		return &ValidationError{Err: errors.New("game ID is required")}
+	}
+	if _, err := s.boardgames.GetByID(boardgameID); err != nil {
+		if errors.Is(err, boardgame.ErrNotFound) {
+			return ErrBoardgameNotFound
+		}
+		return fmt.Errorf("failed to load board game %d: %w", boardgameID, err)
+	}
+	return nil
+}
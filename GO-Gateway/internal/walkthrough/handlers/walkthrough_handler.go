@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+
+	"guru-game/internal/auth/jwt"
+	"guru-game/internal/db/repository/game_rules"
+	"guru-game/internal/db/repository/walkthroughs"
+	"guru-game/internal/walkthrough"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// WalkthroughHandlers holds the dependencies for walkthrough handlers
+type WalkthroughHandlers struct {
+	Service *walkthrough.Service
+}
+
+// NewWalkthroughHandlers creates a new WalkthroughHandlers instance
+func NewWalkthroughHandlers(service *walkthrough.Service) *WalkthroughHandlers {
+	return &WalkthroughHandlers{Service: service}
+}
+
+// HandleStart starts a walkthrough: {"rule_id": 1}
+func (h *WalkthroughHandlers) HandleStart(c *fiber.Ctx) error {
+	userID, ok := jwt.UserIDFromCtx(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var body struct {
+		RuleID int `json:"rule_id"`
+	}
+	if err := c.BodyParser(&body); err != nil || body.RuleID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "rule_id is required"})
+	}
+
+	session, err := h.Service.Start(c.Context(), body.RuleID, userID)
+	if err != nil {
+		return walkthroughError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(session)
+}
+
+// HandleJoin joins an existing walkthrough: {"join_code": "ABC123"}
+func (h *WalkthroughHandlers) HandleJoin(c *fiber.Ctx) error {
+	userID, ok := jwt.UserIDFromCtx(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var body struct {
+		JoinCode string `json:"join_code"`
+	}
+	if err := c.BodyParser(&body); err != nil || body.JoinCode == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "join_code is required"})
+	}
+
+	session, err := h.Service.Join(c.Context(), body.JoinCode, userID)
+	if err != nil {
+		return walkthroughError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(session)
+}
+
+// HandleGet returns the walkthrough and its current step
+func (h *WalkthroughHandlers) HandleGet(c *fiber.Ctx) error {
+	return h.withSession(c, h.Service.Get)
+}
+
+// HandleNext advances to the next step
+func (h *WalkthroughHandlers) HandleNext(c *fiber.Ctx) error {
+	return h.withSession(c, h.Service.Next)
+}
+
+// HandlePrevious goes back one step
+func (h *WalkthroughHandlers) HandlePrevious(c *fiber.Ctx) error {
+	return h.withSession(c, h.Service.Previous)
+}
+
+// HandleComplete marks the walkthrough as finished
+func (h *WalkthroughHandlers) HandleComplete(c *fiber.Ctx) error {
+	return h.withSession(c, h.Service.Complete)
+}
+
+// HandleJump moves to a specific step: {"step": 4}
+func (h *WalkthroughHandlers) HandleJump(c *fiber.Ctx) error {
+	var body struct {
+		Step int `json:"step"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "step is required"})
+	}
+	return h.withSession(c, func(ctx context.Context, id, userID int64) (*walkthrough.Session, error) {
+		return h.Service.Jump(ctx, id, userID, body.Step)
+	})
+}
+
+// HandleStepStats reports which steps of each rule version people re-read most
+func (h *WalkthroughHandlers) HandleStepStats(c *fiber.Ctx) error {
+	ruleID, err := strconv.Atoi(c.Params("rule_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid rule ID"})
+	}
+
+	stats, err := h.Service.StepStats(c.Context(), ruleID)
+	if err != nil {
+		return walkthroughError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"rule_id": ruleID, "steps": stats})
+}
+
+func (h *WalkthroughHandlers) withSession(c *fiber.Ctx, fn func(ctx context.Context, id, userID int64) (*walkthrough.Session, error)) error {
+	userID, ok := jwt.UserIDFromCtx(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid walkthrough ID"})
+	}
+
+	session, err := fn(c.Context(), id, userID)
+	if err != nil {
+		return walkthroughError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(session)
+}
+
+func walkthroughError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, walkthroughs.ErrWalkthroughNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Walkthrough not found"})
+	case errors.Is(err, game_rules.ErrRuleNotFound), errors.Is(err, game_rules.ErrVersionNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Game rule not found"})
+	case errors.Is(err, walkthrough.ErrNotParticipant):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, walkthrough.ErrStepOutOfRange), errors.Is(err, walkthrough.ErrCompleted):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	default:
+		log.Println("Walkthrough operation failed ->", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Walkthrough operation failed"})
+	}
+}
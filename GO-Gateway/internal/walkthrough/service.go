@@ -0,0 +1,197 @@
+package walkthrough
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+
+	"guru-game/internal/db/repository/game_rules"
+	"guru-game/internal/db/repository/walkthroughs"
+	"guru-game/models"
+)
+
+var (
+	// ErrNotParticipant is returned when a user acts on a walkthrough they have not joined
+	ErrNotParticipant = errors.New("you are not part of this walkthrough")
+	// ErrStepOutOfRange is returned when navigating before the first or past the last step
+	ErrStepOutOfRange = errors.New("step out of range")
+	// ErrCompleted is returned when navigating a walkthrough that has already been completed
+	ErrCompleted = errors.New("walkthrough already completed")
+)
+
+// joinCodeAlphabet avoids characters that are easy to confuse when read aloud at the table
+const joinCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// Session is a walkthrough together with the rule step currently shown
+type Session struct {
+	models.Walkthrough
+	Title string           `json:"title"`
+	Step  *models.RuleStep `json:"step"`
+}
+
+// Service coordinates walkthrough sessions over stored rule versions
+type Service struct {
+	repo  walkthroughs.WalkthroughRepository
+	rules game_rules.GameRuleRepository
+}
+
+// NewService creates a new walkthrough Service
+func NewService(repo walkthroughs.WalkthroughRepository, rules game_rules.GameRuleRepository) *Service {
+	return &Service{repo: repo, rules: rules}
+}
+
+// Start begins a walkthrough of the current version of a rule set
+func (s *Service) Start(ctx context.Context, ruleID int, userID int64) (*Session, error) {
+	rule, err := s.rules.GetByID(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if len(rule.Steps) == 0 {
+		return nil, fmt.Errorf("%w: rule set has no steps", ErrStepOutOfRange)
+	}
+
+	code, err := newJoinCode()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &models.Walkthrough{
+		RuleID:      rule.ID,
+		RuleVersion: rule.Version,
+		OwnerID:     userID,
+		JoinCode:    code,
+		TotalSteps:  len(rule.Steps),
+	}
+	if err := s.repo.Create(ctx, w); err != nil {
+		return nil, err
+	}
+
+	return s.session(ctx, w)
+}
+
+// Join adds a user to the walkthrough with the given join code
+func (s *Service) Join(ctx context.Context, code string, userID int64) (*Session, error) {
+	w, err := s.repo.GetByJoinCode(ctx, strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.AddParticipant(ctx, w.ID, userID); err != nil {
+		return nil, err
+	}
+	return s.session(ctx, w)
+}
+
+// Get returns a walkthrough and its current step for a participant
+func (s *Service) Get(ctx context.Context, id, userID int64) (*Session, error) {
+	w, err := s.authorize(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.session(ctx, w)
+}
+
+// Next advances to the following step
+func (s *Service) Next(ctx context.Context, id, userID int64) (*Session, error) {
+	return s.move(ctx, id, userID, walkthroughs.ActionNext, func(w *models.Walkthrough) int { return w.CurrentStep + 1 })
+}
+
+// Previous goes back one step
+func (s *Service) Previous(ctx context.Context, id, userID int64) (*Session, error) {
+	return s.move(ctx, id, userID, walkthroughs.ActionPrevious, func(w *models.Walkthrough) int { return w.CurrentStep - 1 })
+}
+
+// Jump moves directly to a step
+func (s *Service) Jump(ctx context.Context, id, userID int64, step int) (*Session, error) {
+	return s.move(ctx, id, userID, walkthroughs.ActionJump, func(*models.Walkthrough) int { return step })
+}
+
+// Complete records that the group finished the walkthrough
+func (s *Service) Complete(ctx context.Context, id, userID int64) (*Session, error) {
+	if _, err := s.authorize(ctx, id, userID); err != nil {
+		return nil, err
+	}
+	w, err := s.repo.Complete(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.session(ctx, w)
+}
+
+// StepStats reports which steps of each version of a rule set are re-read most often
+func (s *Service) StepStats(ctx context.Context, ruleID int) ([]models.WalkthroughStepStat, error) {
+	if _, err := s.rules.GetByID(ctx, ruleID); err != nil {
+		return nil, err
+	}
+	stats, err := s.repo.GetStepStats(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if stats == nil {
+		stats = []models.WalkthroughStepStat{}
+	}
+	return stats, nil
+}
+
+func (s *Service) move(ctx context.Context, id, userID int64, action string, target func(*models.Walkthrough) int) (*Session, error) {
+	w, err := s.authorize(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if w.CompletedAt != nil {
+		return nil, ErrCompleted
+	}
+
+	step := target(w)
+	if step < 1 || step > w.TotalSteps {
+		return nil, fmt.Errorf("%w: step must be between 1 and %d", ErrStepOutOfRange, w.TotalSteps)
+	}
+
+	w, err = s.repo.MoveToStep(ctx, id, userID, step, action)
+	if err != nil {
+		return nil, err
+	}
+	return s.session(ctx, w)
+}
+
+func (s *Service) authorize(ctx context.Context, id, userID int64) (*models.Walkthrough, error) {
+	w, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.repo.IsParticipant(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotParticipant
+	}
+	return w, nil
+}
+
+// session attaches the rule step for the walkthrough's current position, using the
+// rule version the walkthrough started with so later edits do not shift the steps
+func (s *Service) session(ctx context.Context, w *models.Walkthrough) (*Session, error) {
+	version, err := s.rules.GetVersion(ctx, w.RuleID, w.RuleVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &Session{Walkthrough: *w, Title: version.Title}
+	if i := w.CurrentStep - 1; i >= 0 && i < len(version.Steps) {
+		session.Step = &version.Steps[i]
+	}
+	return session, nil
+}
+
+func newJoinCode() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate join code: %w", err)
+	}
+	for i := range b {
+		b[i] = joinCodeAlphabet[int(b[i])%len(joinCodeAlphabet)]
+	}
+	return string(b), nil
+}
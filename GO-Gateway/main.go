@@ -1,39 +1,71 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 
+	"guru-game/internal/aggregation"
 	"guru-game/internal/auth/service_auth"
 	"guru-game/internal/boardgame/service_board"
 
+	"guru-game/internal/collection"
 	"guru-game/internal/db/connection"
+	"guru-game/internal/db/repository/activities"
+	"guru-game/internal/db/repository/aggregates"
 	"guru-game/internal/db/repository/boardgame"
+	"guru-game/internal/db/repository/collections"
 	"guru-game/internal/db/repository/game_rules"
+	"guru-game/internal/db/repository/gamenights"
+	"guru-game/internal/db/repository/outbox"
+	"guru-game/internal/db/repository/plays"
+	"guru-game/internal/db/repository/polls"
+	"guru-game/internal/db/repository/reviews"
+	"guru-game/internal/db/repository/social"
 	"guru-game/internal/db/repository/user"
 	"guru-game/internal/db/repository/user_states"
+	"guru-game/internal/db/repository/walkthroughs"
+	"guru-game/internal/gamenight"
+	playservice "guru-game/internal/plays"
+	"guru-game/internal/poll"
+	"guru-game/internal/recommendation"
+	"guru-game/internal/review"
+	socialservice "guru-game/internal/social"
+	"guru-game/internal/userstate"
+	"guru-game/internal/walkthrough"
 	"guru-game/routes"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 
 	// Ensure the gamesearch handlers package is imported
 	gamesearchhandlers "guru-game/internal/gamesearch/handlers"
 )
 
 func main() {
+	// Subcommands: `go run . import ...`
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		os.Exit(runImportCommand(os.Args[2:]))
+	}
+
 	log.Println("🚀 Starting server...")
 
 	app := fiber.New()
 
 	// ตั้งค่า CORS middleware เพื่ออนุญาต frontend จาก localhost:3000
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: "http://localhost:3000",
-		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
+		AllowOrigins:  "http://localhost:3000",
+		AllowMethods:  "GET,POST,PUT,PATCH,DELETE,OPTIONS",
+		AllowHeaders:  "Origin, Content-Type, Accept, Authorization, If-Match, X-Request-ID",
+		ExposeHeaders: "ETag, X-Request-ID",
 	}))
 	log.Println("✅ CORS middleware configured")
 
+	// Tag every request with an X-Request-ID (kept when the client sends one); it is
+	// forwarded to the ML service so both sides' logs can be matched up
+	app.Use(requestid.New())
+
 	// เชื่อมต่อ DB
 	connection.ConnectDB()
 	service_auth.Init(&user.PostgresUserRepository{})
@@ -43,24 +75,71 @@ func main() {
 	// Initialize boardGameRepo correctly as an empty struct
 	boardGameRepo := &boardgame.PostgresBoardgameRepository{}
 	gameRuleRepo := game_rules.NewPostgresGameRuleRepository(connection.DB)
+	walkthroughRepo := walkthroughs.NewPostgresWalkthroughRepository(connection.DB)
+	aggregateRepo := aggregates.NewPostgresAggregateRepository(connection.DB)
+	activityRepo := activities.NewPostgresActivityRepository(connection.DB)
+	outboxRepo := outbox.NewPostgresOutboxRepository(connection.DB)
+	playRepo := plays.NewPostgresPlayRepository(connection.DB)
+	collectionRepo := collections.NewPostgresCollectionRepository(connection.DB)
+	reviewRepo := reviews.NewPostgresReviewRepository(connection.DB)
+	pollRepo := polls.NewPostgresPollRepository(connection.DB)
+	socialRepo := social.NewPostgresSocialRepository(connection.DB)
+	gameNightRepo := gamenights.NewPostgresGameNightRepository(connection.DB)
 	log.Println("✅ Repositories initialized")
 
+	pythonServiceURL := os.Getenv("PYTHON_SERVICE_URL")
+	if pythonServiceURL == "" {
+		pythonServiceURL = "http://localhost:50051" // default URL
+	}
+
 	// Initialize services
 	// Provide the boardGameRepo to the boardgame service
 	service_board.Init(boardGameRepo)
 	gameRuleService := service_board.NewGameRuleService(gameRuleRepo)
+	walkthroughService := walkthrough.NewService(walkthroughRepo, gameRuleRepo)
+	aggregationService := aggregation.NewService(aggregateRepo)
+	// Shared by the outbox worker and the API so delivered actions refresh what users see
+	recoCache := recommendation.NewCacheFromEnv()
+	// One REST client, so every caller shares its circuit breaker
+	log.Printf("🌐 Python service URL: %s", pythonServiceURL)
+	recoREST := recommendation.NewRESTRecommendationClient(pythonServiceURL)
+	recoClient := recommendation.NewCachingRecommendationClient(recoREST, recoCache)
+	outboxWorker := recommendation.NewOutboxWorker(outboxRepo, recoClient)
+	userStateService := userstate.NewService(userStateRepo, boardGameRepo, activityRepo, aggregationService, outboxWorker, recoCache)
+	playService := playservice.NewService(playRepo)
+	collectionService := collection.NewService(collectionRepo)
+	reviewService := review.NewService(reviewRepo)
+	pollService := poll.NewService(pollRepo, boardGameRepo, recoREST)
+	socialService := socialservice.NewService(socialRepo)
+	gameNightService := gamenight.NewService(gameNightRepo)
 	log.Println("✅ Services initialized")
 
+	// Keep ratings and time-decayed popularity fresh in the background
+	aggregationService.StartPeriodicRecompute(context.Background())
+	// Forward user actions queued in recommendation_outbox to the ML service
+	outboxWorker.Start(context.Background())
+
 	// Initialize Game Search Handlers
-	pythonServiceURL := os.Getenv("PYTHON_SERVICE_URL")
-	if pythonServiceURL == "" {
-		pythonServiceURL = "http://localhost:50051" // default URL
-	}
 	gameSearchHandlers := gamesearchhandlers.NewGameSearchHandlers(pythonServiceURL)
 
 	log.Println("🔧 Setting up routes...")
 	// Pass the concrete boardGameRepo which satisfies the interface
-	routes.SetupRoutes(app, userStateRepo, boardGameRepo, gameRuleService, gameSearchHandlers)
+	routes.SetupRoutes(app, routes.Dependencies{
+		UserStateRepo:        userStateRepo,
+		BoardGameRepo:        boardGameRepo,
+		GameRuleService:      gameRuleService,
+		WalkthroughService:   walkthroughService,
+		AggregationService:   aggregationService,
+		GameSearchHandlers:   gameSearchHandlers,
+		UserStateService:     userStateService,
+		PlayService:          playService,
+		CollectionService:    collectionService,
+		ReviewService:        reviewService,
+		PollService:          pollService,
+		SocialService:        socialService,
+		GameNightService:     gameNightService,
+		RecommendationClient: recoClient,
+	})
 	log.Println("✅ Routes configured")
 
 	port := os.Getenv("GO_PORT")
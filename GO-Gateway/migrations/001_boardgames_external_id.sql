@@ -0,0 +1,6 @@
+-- External identifiers for games imported from CSV or BoardGameGeek exports.
+-- The import upserts on this column, so it must be unique (NULLs are allowed
+-- for games that were created by hand).
+ALTER TABLE boardgames ADD COLUMN IF NOT EXISTS external_id TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS boardgames_external_id_key ON boardgames (external_id);
@@ -0,0 +1,17 @@
+-- game_rules.steps now follows the RuleStep schema (models/GameRule.go):
+--   [{"step": 1, "title": "...", "description": "...", "image_url": "...", "tips": ["..."]}]
+-- Older rows stored a plain array of strings; convert those to step objects.
+ALTER TABLE game_rules ALTER COLUMN steps TYPE JSONB USING steps::jsonb;
+
+UPDATE game_rules
+SET steps = (
+	SELECT jsonb_agg(jsonb_build_object('step', t.ord, 'title', '', 'description', t.elem #>> '{}') ORDER BY t.ord)
+	FROM jsonb_array_elements(game_rules.steps) WITH ORDINALITY AS t(elem, ord)
+)
+WHERE jsonb_typeof(steps) = 'array'
+  AND jsonb_typeof(steps -> 0) = 'string';
+
+ALTER TABLE game_rules
+	ADD CONSTRAINT game_rules_boardgame_id_fkey
+	FOREIGN KEY (boardgame_id) REFERENCES boardgames (id) ON DELETE CASCADE
+	NOT VALID;
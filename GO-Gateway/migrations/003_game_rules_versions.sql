@@ -0,0 +1,26 @@
+-- Localized, versioned game rules.
+-- game_rules holds the current version of each rule set; every create/update
+-- also appends a snapshot to game_rule_versions together with its author.
+ALTER TABLE game_rules
+	ADD COLUMN IF NOT EXISTS language TEXT NOT NULL DEFAULT 'th',
+	ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1,
+	ADD COLUMN IF NOT EXISTS updated_by BIGINT REFERENCES users (id) ON DELETE SET NULL,
+	ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS game_rules_boardgame_language_idx ON game_rules (boardgame_id, language);
+
+CREATE TABLE IF NOT EXISTS game_rule_versions (
+	rule_id    INT NOT NULL REFERENCES game_rules (id) ON DELETE CASCADE,
+	version    INT NOT NULL,
+	language   TEXT NOT NULL,
+	title      TEXT NOT NULL,
+	steps      JSONB NOT NULL,
+	author_id  BIGINT REFERENCES users (id) ON DELETE SET NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (rule_id, version)
+);
+
+-- Existing rules become version 1 with no known author
+INSERT INTO game_rule_versions (rule_id, version, language, title, steps)
+SELECT id, version, language, title, steps FROM game_rules
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,35 @@
+-- "Teach me this game" walkthrough sessions over a snapshot of a rule set.
+CREATE TABLE IF NOT EXISTS rule_walkthroughs (
+	id            BIGSERIAL PRIMARY KEY,
+	rule_id       INT NOT NULL,
+	rule_version  INT NOT NULL,
+	owner_id      BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	join_code     TEXT NOT NULL UNIQUE,
+	current_step  INT NOT NULL DEFAULT 1,
+	total_steps   INT NOT NULL,
+	started_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	completed_at  TIMESTAMPTZ,
+	FOREIGN KEY (rule_id, rule_version) REFERENCES game_rule_versions (rule_id, version) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS rule_walkthrough_participants (
+	walkthrough_id BIGINT NOT NULL REFERENCES rule_walkthroughs (id) ON DELETE CASCADE,
+	user_id        BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	joined_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (walkthrough_id, user_id)
+);
+
+-- One row per step shown. reread is true when the step had already been shown
+-- earlier in the same session, which is what the step stats report on.
+CREATE TABLE IF NOT EXISTS rule_walkthrough_events (
+	id             BIGSERIAL PRIMARY KEY,
+	walkthrough_id BIGINT NOT NULL REFERENCES rule_walkthroughs (id) ON DELETE CASCADE,
+	user_id        BIGINT REFERENCES users (id) ON DELETE SET NULL,
+	step           INT NOT NULL,
+	action         TEXT NOT NULL,
+	reread         BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS rule_walkthrough_events_walkthrough_idx ON rule_walkthrough_events (walkthrough_id, step);
@@ -0,0 +1,40 @@
+-- Full-text search over game rule steps.
+--
+-- Postgres ships no Thai parser or dictionary, and Thai is written without
+-- spaces between words, so to_tsvector cannot split it into useful lexemes.
+-- Thai steps are therefore indexed with the 'simple' configuration (which
+-- still handles any Latin words mixed in) and matched by substring through a
+-- trigram index; English steps use the 'english' configuration with stemming.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE TABLE IF NOT EXISTS game_rule_step_search (
+	rule_id    INT NOT NULL REFERENCES game_rules (id) ON DELETE CASCADE,
+	step       INT NOT NULL,
+	language   TEXT NOT NULL,
+	config     REGCONFIG NOT NULL,
+	step_title TEXT NOT NULL,
+	body       TEXT NOT NULL,
+	tsv        TSVECTOR GENERATED ALWAYS AS (
+		setweight(to_tsvector(config, step_title), 'A') || setweight(to_tsvector(config, body), 'B')
+	) STORED,
+	PRIMARY KEY (rule_id, step)
+);
+
+CREATE INDEX IF NOT EXISTS game_rule_step_search_tsv_idx ON game_rule_step_search USING GIN (tsv);
+CREATE INDEX IF NOT EXISTS game_rule_step_search_trgm_idx ON game_rule_step_search USING GIN (body gin_trgm_ops);
+
+-- Backfill from existing rules; the application keeps this table in sync afterwards
+INSERT INTO game_rule_step_search (rule_id, step, language, config, step_title, body)
+SELECT r.id,
+       (s.elem ->> 'step')::int,
+       r.language,
+       CASE r.language WHEN 'en' THEN 'english'::regconfig ELSE 'simple'::regconfig END,
+       COALESCE(s.elem ->> 'title', ''),
+       concat_ws(E'\n',
+           r.title,
+           s.elem ->> 'title',
+           s.elem ->> 'description',
+           (SELECT string_agg(t, E'\n') FROM jsonb_array_elements_text(COALESCE(s.elem -> 'tips', '[]'::jsonb)) AS t))
+FROM game_rules r
+CROSS JOIN LATERAL jsonb_array_elements(r.steps) AS s(elem)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,33 @@
+-- Append-only history of user_states changes. A row is written in the same
+-- transaction as every user_states upsert that changes something, with the
+-- values before and after the change.
+CREATE TABLE IF NOT EXISTS user_state_events (
+	id               BIGSERIAL PRIMARY KEY,
+	user_id          BIGINT NOT NULL,
+	boardgame_id     INT NOT NULL,
+	liked            BOOLEAN NOT NULL,
+	favorited        BOOLEAN NOT NULL,
+	rating           DOUBLE PRECISION NOT NULL,
+	prev_liked       BOOLEAN,
+	prev_favorited   BOOLEAN,
+	prev_rating      DOUBLE PRECISION,
+	changed_fields   TEXT[] NOT NULL,
+	source           TEXT NOT NULL,
+	created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS user_state_events_user_idx ON user_state_events (user_id, id DESC);
+CREATE INDEX IF NOT EXISTS user_state_events_boardgame_idx ON user_state_events (boardgame_id, id DESC);
+CREATE INDEX IF NOT EXISTS user_state_events_created_at_idx ON user_state_events (created_at);
+
+-- Append-only: refuse updates and deletes
+CREATE OR REPLACE FUNCTION user_state_events_immutable() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'user_state_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS user_state_events_no_update ON user_state_events;
+CREATE TRIGGER user_state_events_no_update
+	BEFORE UPDATE OR DELETE ON user_state_events
+	FOR EACH ROW EXECUTE FUNCTION user_state_events_immutable();
@@ -0,0 +1,3 @@
+-- Optimistic concurrency for user_states. The version is bumped on every
+-- change and exposed to clients as an ETag; updates carry it in If-Match.
+ALTER TABLE user_states ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,16 @@
+-- Activities that do not change user_states (views, plays, searches) are kept
+-- here; like/favorite/rate activities go through user_states and
+-- user_state_events instead.
+CREATE TABLE IF NOT EXISTS user_activity_log (
+	id           BIGSERIAL PRIMARY KEY,
+	user_id      BIGINT NOT NULL,
+	type         TEXT NOT NULL,
+	boardgame_id INT,
+	session_id   TEXT NOT NULL DEFAULT '',
+	data         JSONB NOT NULL DEFAULT '{}',
+	occurred_at  TIMESTAMPTZ NOT NULL,
+	created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS user_activity_log_user_idx ON user_activity_log (user_id, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS user_activity_log_boardgame_idx ON user_activity_log (boardgame_id, type) WHERE boardgame_id IS NOT NULL;
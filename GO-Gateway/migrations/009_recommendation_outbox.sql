@@ -0,0 +1,21 @@
+-- Actions waiting to be forwarded to the recommendation service. Rows are written in
+-- the same transaction as the user_states / user_activity_log change that caused them
+-- and delivered by the outbox worker in id order per user.
+CREATE TABLE IF NOT EXISTS recommendation_outbox (
+	id              BIGSERIAL PRIMARY KEY,
+	user_id         BIGINT NOT NULL,
+	boardgame_id    INT NOT NULL,
+	action_type     TEXT NOT NULL,
+	action_value    DOUBLE PRECISION NOT NULL,
+	action_time     TIMESTAMPTZ NOT NULL,
+	attempts        INT NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	last_error      TEXT,
+	delivered_at    TIMESTAMPTZ,
+	dead_at         TIMESTAMPTZ,
+	created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS recommendation_outbox_pending_idx
+	ON recommendation_outbox (user_id, id)
+	WHERE delivered_at IS NULL AND dead_at IS NULL;
@@ -0,0 +1,7 @@
+-- Client event IDs let resent activities be dropped. Every activity type is logged from
+-- now on (including likes, favorites and ratings) so their event IDs are remembered too.
+ALTER TABLE user_activity_log ADD COLUMN IF NOT EXISTS event_id TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_activity_log_event_idx
+	ON user_activity_log (user_id, event_id)
+	WHERE event_id IS NOT NULL;
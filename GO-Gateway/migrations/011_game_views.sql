@@ -0,0 +1,23 @@
+-- Game page views, one row per user, game and session. Each VIEW_GAME activity adds a
+-- view and its dwell time to the row for its session.
+CREATE TABLE IF NOT EXISTS game_views (
+	user_id         BIGINT NOT NULL,
+	boardgame_id    INT NOT NULL REFERENCES boardgames(id) ON DELETE CASCADE,
+	session_id      TEXT NOT NULL DEFAULT '',
+	view_count      INT NOT NULL DEFAULT 0,
+	dwell_ms        BIGINT NOT NULL DEFAULT 0,
+	first_viewed_at TIMESTAMPTZ NOT NULL,
+	last_viewed_at  TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (user_id, boardgame_id, session_id)
+);
+
+CREATE INDEX IF NOT EXISTS game_views_recent_idx ON game_views (user_id, last_viewed_at DESC);
+
+-- Running totals per game, kept in step with game_views
+CREATE TABLE IF NOT EXISTS game_view_counts (
+	boardgame_id   INT PRIMARY KEY REFERENCES boardgames(id) ON DELETE CASCADE,
+	views          BIGINT NOT NULL DEFAULT 0,
+	sessions       BIGINT NOT NULL DEFAULT 0,
+	total_dwell_ms BIGINT NOT NULL DEFAULT 0,
+	updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,29 @@
+-- Logged plays. The logging user owns the play; participants are registered users or guests.
+CREATE TABLE IF NOT EXISTS plays (
+	id               BIGSERIAL PRIMARY KEY,
+	user_id          BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	boardgame_id     INT NOT NULL REFERENCES boardgames (id) ON DELETE CASCADE,
+	played_at        TIMESTAMPTZ NOT NULL,
+	duration_minutes INT CHECK (duration_minutes >= 0),
+	location         TEXT NOT NULL DEFAULT '',
+	notes            TEXT NOT NULL DEFAULT '',
+	created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS plays_user_idx ON plays (user_id, played_at DESC);
+CREATE INDEX IF NOT EXISTS plays_boardgame_idx ON plays (boardgame_id);
+
+CREATE TABLE IF NOT EXISTS play_players (
+	play_id    BIGINT NOT NULL REFERENCES plays (id) ON DELETE CASCADE,
+	position   INT NOT NULL,
+	user_id    BIGINT REFERENCES users (id) ON DELETE SET NULL,
+	guest_name TEXT NOT NULL DEFAULT '',
+	score      DOUBLE PRECISION,
+	winner     BOOLEAN NOT NULL DEFAULT FALSE,
+	PRIMARY KEY (play_id, position),
+	CHECK (user_id IS NOT NULL OR guest_name <> '')
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS play_players_user_idx ON play_players (play_id, user_id) WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS play_players_user_lookup_idx ON play_players (user_id) WHERE user_id IS NOT NULL;
@@ -0,0 +1,33 @@
+-- Personal collections. A game can hold several statuses at once (e.g. owned and for trade).
+CREATE TABLE IF NOT EXISTS user_collection (
+	user_id           BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	boardgame_id      INT NOT NULL REFERENCES boardgames (id) ON DELETE CASCADE,
+	status            TEXT NOT NULL CHECK (status IN ('owned', 'previously_owned', 'wishlist', 'want_to_play', 'for_trade')),
+	wishlist_priority SMALLINT CHECK (wishlist_priority BETWEEN 1 AND 5),
+	notes             TEXT NOT NULL DEFAULT '',
+	added_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (user_id, boardgame_id, status),
+	CHECK (status = 'wishlist' OR wishlist_priority IS NULL)
+);
+
+CREATE INDEX IF NOT EXISTS user_collection_status_idx ON user_collection (user_id, status);
+
+-- User-defined shelves
+CREATE TABLE IF NOT EXISTS user_shelves (
+	id          BIGSERIAL PRIMARY KEY,
+	user_id     BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	name        TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_shelves_name_idx ON user_shelves (user_id, lower(name));
+
+CREATE TABLE IF NOT EXISTS user_shelf_games (
+	shelf_id     BIGINT NOT NULL REFERENCES user_shelves (id) ON DELETE CASCADE,
+	boardgame_id INT NOT NULL REFERENCES boardgames (id) ON DELETE CASCADE,
+	added_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (shelf_id, boardgame_id)
+);
@@ -0,0 +1,51 @@
+-- Written reviews. A review belongs to the author's user_states row for the game, so the
+-- rating shown with it is always the author's current rating.
+CREATE TABLE IF NOT EXISTS reviews (
+	id            BIGSERIAL PRIMARY KEY,
+	user_id       BIGINT NOT NULL,
+	boardgame_id  INT NOT NULL,
+	body          TEXT NOT NULL,
+	status        TEXT NOT NULL DEFAULT 'visible' CHECK (status IN ('visible', 'hidden')),
+	hidden_reason TEXT NOT NULL DEFAULT '',
+	moderated_by  BIGINT REFERENCES users (id) ON DELETE SET NULL,
+	moderated_at  TIMESTAMPTZ,
+	helpful_count INT NOT NULL DEFAULT 0,
+	edit_count    INT NOT NULL DEFAULT 0,
+	created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	UNIQUE (user_id, boardgame_id),
+	FOREIGN KEY (user_id, boardgame_id) REFERENCES user_states (user_id, boardgame_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS reviews_boardgame_idx ON reviews (boardgame_id, status);
+
+-- Earlier bodies of edited reviews
+CREATE TABLE IF NOT EXISTS review_revisions (
+	id          BIGSERIAL PRIMARY KEY,
+	review_id   BIGINT NOT NULL REFERENCES reviews (id) ON DELETE CASCADE,
+	body        TEXT NOT NULL,
+	written_at  TIMESTAMPTZ NOT NULL,
+	replaced_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS review_revisions_review_idx ON review_revisions (review_id, id);
+
+CREATE TABLE IF NOT EXISTS review_votes (
+	review_id  BIGINT NOT NULL REFERENCES reviews (id) ON DELETE CASCADE,
+	user_id    BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (review_id, user_id)
+);
+
+-- Reports stay open until a moderator hides or restores the review
+CREATE TABLE IF NOT EXISTS review_reports (
+	id          BIGSERIAL PRIMARY KEY,
+	review_id   BIGINT NOT NULL REFERENCES reviews (id) ON DELETE CASCADE,
+	user_id     BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	reason      TEXT NOT NULL,
+	created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	resolved_at TIMESTAMPTZ,
+	UNIQUE (review_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS review_reports_open_idx ON review_reports (review_id) WHERE resolved_at IS NULL;
@@ -0,0 +1,35 @@
+-- Community complexity (weight) votes, 1 = light to 5 = heavy, one per user and game
+CREATE TABLE IF NOT EXISTS game_weight_votes (
+	user_id      BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	boardgame_id INT NOT NULL REFERENCES boardgames (id) ON DELETE CASCADE,
+	weight       SMALLINT NOT NULL CHECK (weight BETWEEN 1 AND 5),
+	updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (user_id, boardgame_id)
+);
+
+-- Player count poll: how a user rates the game at each player count
+CREATE TABLE IF NOT EXISTS game_player_count_votes (
+	user_id      BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	boardgame_id INT NOT NULL REFERENCES boardgames (id) ON DELETE CASCADE,
+	player_count SMALLINT NOT NULL CHECK (player_count > 0),
+	vote         TEXT NOT NULL CHECK (vote IN ('best', 'recommended', 'not_recommended')),
+	updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (user_id, boardgame_id, player_count)
+);
+
+CREATE INDEX IF NOT EXISTS game_player_count_votes_game_idx ON game_player_count_votes (boardgame_id, player_count);
+
+-- Poll results per game, recomputed whenever a vote changes. recommended_player_counts
+-- includes the best counts.
+CREATE TABLE IF NOT EXISTS game_poll_stats (
+	boardgame_id              INT PRIMARY KEY REFERENCES boardgames (id) ON DELETE CASCADE,
+	weight_avg                DOUBLE PRECISION,
+	weight_votes              INT NOT NULL DEFAULT 0,
+	player_count_voters       INT NOT NULL DEFAULT 0,
+	best_player_counts        INT[] NOT NULL DEFAULT '{}',
+	recommended_player_counts INT[] NOT NULL DEFAULT '{}',
+	updated_at                TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS game_poll_stats_weight_idx ON game_poll_stats (weight_avg);
+CREATE INDEX IF NOT EXISTS game_poll_stats_best_idx ON game_poll_stats USING GIN (best_player_counts);
@@ -0,0 +1,27 @@
+-- Follow graph. A follow of a private account stays pending until the followee accepts it.
+-- Two accepted follows in opposite directions make the users friends.
+CREATE TABLE IF NOT EXISTS user_follows (
+	follower_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	followee_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	status      TEXT NOT NULL DEFAULT 'accepted' CHECK (status IN ('pending', 'accepted')),
+	created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	accepted_at TIMESTAMPTZ,
+	PRIMARY KEY (follower_id, followee_id),
+	CHECK (follower_id <> followee_id)
+);
+
+CREATE INDEX IF NOT EXISTS user_follows_followee_idx ON user_follows (followee_id, status);
+
+-- Per-user privacy. Users without a row use the column defaults.
+-- feed_visibility decides who sees the user's activity in their feed.
+CREATE TABLE IF NOT EXISTS user_privacy_settings (
+	user_id         BIGINT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+	private_account BOOLEAN NOT NULL DEFAULT FALSE,
+	feed_visibility TEXT NOT NULL DEFAULT 'followers' CHECK (feed_visibility IN ('followers', 'friends', 'nobody')),
+	updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Feed lookups by author and time
+CREATE INDEX IF NOT EXISTS reviews_user_created_idx ON reviews (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS plays_user_created_idx ON plays (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS user_state_events_user_created_idx ON user_state_events (user_id, created_at DESC);
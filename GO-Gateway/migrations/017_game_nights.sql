@@ -0,0 +1,34 @@
+-- Game night events. The host is also stored as an invitee with a 'yes' RSVP.
+CREATE TABLE IF NOT EXISTS game_nights (
+	id         BIGSERIAL PRIMARY KEY,
+	host_id    BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	title      TEXT NOT NULL,
+	starts_at  TIMESTAMPTZ NOT NULL,
+	location   TEXT NOT NULL DEFAULT '',
+	notes      TEXT NOT NULL DEFAULT '',
+	status     TEXT NOT NULL DEFAULT 'planned' CHECK (status IN ('planned', 'cancelled')),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS game_nights_host_idx ON game_nights (host_id, starts_at);
+
+CREATE TABLE IF NOT EXISTS game_night_invites (
+	game_night_id BIGINT NOT NULL REFERENCES game_nights (id) ON DELETE CASCADE,
+	user_id       BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	rsvp          TEXT NOT NULL DEFAULT 'invited' CHECK (rsvp IN ('invited', 'yes', 'maybe', 'no')),
+	invited_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	responded_at  TIMESTAMPTZ,
+	PRIMARY KEY (game_night_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS game_night_invites_user_idx ON game_night_invites (user_id);
+
+-- Approval voting on the shortlist: each attendee may vote for any number of games
+CREATE TABLE IF NOT EXISTS game_night_votes (
+	game_night_id BIGINT NOT NULL REFERENCES game_nights (id) ON DELETE CASCADE,
+	user_id       BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	boardgame_id  INT NOT NULL REFERENCES boardgames (id) ON DELETE CASCADE,
+	created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (game_night_id, user_id, boardgame_id)
+);
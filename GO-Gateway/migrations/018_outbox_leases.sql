@@ -0,0 +1,4 @@
+-- Outbox messages are claimed with a lease instead of being locked by a transaction
+-- that stays open while they are delivered. A claimed message is skipped by other
+-- deliverers until locked_until; if the claimer dies, it is delivered again after that.
+ALTER TABLE recommendation_outbox ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ;
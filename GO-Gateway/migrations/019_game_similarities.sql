@@ -0,0 +1,9 @@
+-- Item-item similarities used by the gateway's fallback recommender. Recomputed from
+-- user_states with the other aggregates, so recommending does not join user_states
+-- with itself on every request. Each game keeps only its closest neighbours.
+CREATE TABLE IF NOT EXISTS game_similarities (
+	source_id    INT NOT NULL REFERENCES boardgames (id) ON DELETE CASCADE,
+	candidate_id INT NOT NULL REFERENCES boardgames (id) ON DELETE CASCADE,
+	similarity   DOUBLE PRECISION NOT NULL,
+	PRIMARY KEY (source_id, candidate_id)
+);
@@ -0,0 +1,15 @@
+-- The mean rating across all games, used as the prior of every game's Bayesian average.
+-- Refreshed by the periodic aggregate recompute, so recomputing one game after a state
+-- change reads this value instead of averaging the whole of user_states.
+CREATE TABLE IF NOT EXISTS rating_prior (
+	id         BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+	mean       DOUBLE PRECISION NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO rating_prior (mean)
+SELECT COALESCE(AVG(rating), 0) FROM user_states WHERE rating > 0
+ON CONFLICT (id) DO NOTHING;
+
+-- Recomputing one game reads only that game's states
+CREATE INDEX IF NOT EXISTS user_states_boardgame_idx ON user_states (boardgame_id);
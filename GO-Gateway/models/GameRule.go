@@ -1,9 +1,117 @@
 package models
 
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultRuleLanguage is used when a rule set is saved without a language
+const DefaultRuleLanguage = "th"
+
 // GameRule represents the structure of the game_rules table
 type GameRule struct {
-	ID          int         `json:"id"`
-	BoardgameID int         `json:"boardgame_id"`
-	Title       string      `json:"title"`
-	Steps       interface{} `json:"steps"` // Using interface{} for jsonb/json type
+	ID          int        `json:"id"`
+	BoardgameID int        `json:"boardgame_id"`
+	Title       string     `json:"title"`
+	Steps       []RuleStep `json:"steps"`    // Stored as jsonb
+	Language    string     `json:"language"` // ISO 639-1 code, e.g. "th" or "en"
+	Version     int        `json:"version"`
+	UpdatedBy   *int64     `json:"updated_by,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// GameRuleVersion is a snapshot of a rule set stored in game_rule_versions
+type GameRuleVersion struct {
+	RuleID    int        `json:"rule_id"`
+	Version   int        `json:"version"`
+	Language  string     `json:"language"`
+	Title     string     `json:"title"`
+	Steps     []RuleStep `json:"steps"`
+	AuthorID  *int64     `json:"author_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// RuleStep is one entry of the steps jsonb column
+type RuleStep struct {
+	Step        int      `json:"step"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	ImageURL    string   `json:"image_url,omitempty"`
+	Tips        []string `json:"tips,omitempty"`
+}
+
+// Limits applied when validating rule sets
+const (
+	MaxRuleSteps           = 200
+	MaxRuleTitleLength     = 200
+	MaxRuleStepDescription = 5000
+)
+
+var languageCodePattern = regexp.MustCompile(`^[a-z]{2,3}$`)
+
+// Validate checks the rule set and numbers any steps sent without a step number
+func (r *GameRule) Validate() error {
+	var problems []string
+
+	r.Title = strings.TrimSpace(r.Title)
+	if r.Title == "" {
+		problems = append(problems, "title is required")
+	} else if utf8.RuneCountInString(r.Title) > MaxRuleTitleLength {
+		problems = append(problems, fmt.Sprintf("title must be at most %d characters", MaxRuleTitleLength))
+	}
+
+	r.Language = strings.ToLower(strings.TrimSpace(r.Language))
+	if r.Language == "" {
+		r.Language = DefaultRuleLanguage
+	} else if !languageCodePattern.MatchString(r.Language) {
+		problems = append(problems, "language must be a two or three letter ISO 639 code")
+	}
+
+	if len(r.Steps) == 0 {
+		problems = append(problems, "at least one step is required")
+	} else if len(r.Steps) > MaxRuleSteps {
+		problems = append(problems, fmt.Sprintf("at most %d steps are allowed", MaxRuleSteps))
+	}
+
+	for i := range r.Steps {
+		step := &r.Steps[i]
+		if step.Step == 0 {
+			step.Step = i + 1
+		}
+		if step.Step != i+1 {
+			problems = append(problems, fmt.Sprintf("steps[%d]: step must be %d (steps are numbered in order from 1)", i, i+1))
+		}
+		step.Title = strings.TrimSpace(step.Title)
+		step.Description = strings.TrimSpace(step.Description)
+		if step.Title == "" && step.Description == "" {
+			problems = append(problems, fmt.Sprintf("steps[%d]: title or description is required", i))
+		}
+		if utf8.RuneCountInString(step.Title) > MaxRuleTitleLength {
+			problems = append(problems, fmt.Sprintf("steps[%d]: title must be at most %d characters", i, MaxRuleTitleLength))
+		}
+		if utf8.RuneCountInString(step.Description) > MaxRuleStepDescription {
+			problems = append(problems, fmt.Sprintf("steps[%d]: description must be at most %d characters", i, MaxRuleStepDescription))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// RuleSearchHit is a rule step matching a full-text search
+type RuleSearchHit struct {
+	RuleID      int     `json:"rule_id"`
+	BoardgameID int     `json:"boardgame_id"`
+	RuleTitle   string  `json:"rule_title"`
+	Language    string  `json:"language"`
+	Step        int     `json:"step"`
+	StepTitle   string  `json:"step_title"`
+	Snippet     string  `json:"snippet"` // HTML-escaped, matched terms wrapped in <mark></mark>
+	Rank        float64 `json:"rank"`
 }
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGameRuleValidateLengths(t *testing.T) {
+	// Thai takes three bytes per character in UTF-8
+	thai := func(n int) string { return strings.Repeat("ก", n) }
+
+	tests := []struct {
+		name    string
+		modify  func(r *GameRule)
+		wantErr string
+	}{
+		{"thai title at the limit", func(r *GameRule) { r.Title = thai(MaxRuleTitleLength) }, ""},
+		{"thai title over the limit", func(r *GameRule) { r.Title = thai(MaxRuleTitleLength + 1) }, "title must be at most"},
+		{"thai step title at the limit", func(r *GameRule) { r.Steps[0].Title = thai(MaxRuleTitleLength) }, ""},
+		{"thai step title over the limit", func(r *GameRule) { r.Steps[0].Title = thai(MaxRuleTitleLength + 1) }, "steps[0]: title must be at most"},
+		{"thai description at the limit", func(r *GameRule) { r.Steps[0].Description = thai(MaxRuleStepDescription) }, ""},
+		{"thai description over the limit", func(r *GameRule) { r.Steps[0].Description = thai(MaxRuleStepDescription + 1) }, "steps[0]: description must be at most"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := GameRule{Title: "Setup", Steps: []RuleStep{{Title: "Deal", Description: "Deal five cards"}}}
+			tt.modify(&r)
+			err := r.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import "time"
 
 type BoardGame struct {
 	ID              int       `json:"id"`
+	ExternalID      string    `json:"external_id,omitempty"` // เช่น "bgg:13" สำหรับเกมที่ import เข้ามา
 	Title           string    `json:"title"`
 	Description     string    `json:"description"`
 	MinPlayers      int       `json:"min_players"`
@@ -22,6 +23,9 @@ type BoardGame struct {
 	LikedByCurrentUser     bool    `json:"likedByCurrentUser,omitempty"`
 	FavoritedByCurrentUser bool    `json:"favoritedByCurrentUser,omitempty"`
 	CurrentUserRating      float64 `json:"currentUserRating,omitempty"`
+
+	// Filled on the detail endpoint only
+	ReviewSummary *ReviewSummary `json:"review_summary,omitempty"`
 }
 
 // ActivityData represents the nested data structure within the request body
@@ -33,6 +37,7 @@ type ActivityData struct {
 	IsFavorite  bool    `json:"isFavorite"`
 	RatingValue float64 `json:"ratingValue"`
 	SearchQuery string  `json:"searchQuery"`
+	DwellMs     int64   `json:"dwellMs"` // Time spent on the game page, for VIEW_GAME
 }
 
 // GameSearchQuery represents the expected structure of the incoming query parameters
@@ -43,6 +48,11 @@ type GameSearchQuery struct {
 	PlayTime    int      `query:"playTime"`
 	Limit       int      `query:"limit"`
 	Page        int      `query:"page"`
+
+	// Community poll filters, applied by the search service before paging
+	MinWeight float64 `query:"minWeight"`
+	MaxWeight float64 `query:"maxWeight"`
+	BestAt    int     `query:"bestAt"`
 }
 
 // UserState represents a row in the user_states table
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Collection statuses
+const (
+	CollectionOwned           = "owned"
+	CollectionPreviouslyOwned = "previously_owned"
+	CollectionWishlist        = "wishlist"
+	CollectionWantToPlay      = "want_to_play"
+	CollectionForTrade        = "for_trade"
+)
+
+// CollectionStatuses lists every valid collection status
+var CollectionStatuses = []string{
+	CollectionOwned,
+	CollectionPreviouslyOwned,
+	CollectionWishlist,
+	CollectionWantToPlay,
+	CollectionForTrade,
+}
+
+// CollectionEntry is one status a user gave a game
+type CollectionEntry struct {
+	Status           string    `json:"status"`
+	WishlistPriority *int      `json:"wishlist_priority,omitempty"` // 1 (must have) to 5 (thinking about it); wishlist only
+	Notes            string    `json:"notes,omitempty"`
+	AddedAt          time.Time `json:"added_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// CollectionGame is a game in a user's collection with all its statuses
+type CollectionGame struct {
+	Game    BoardGame         `json:"game"`
+	Entries []CollectionEntry `json:"entries"`
+}
+
+// Shelf is a user-defined list of games
+type Shelf struct {
+	ID          int64     `json:"id"`
+	UserID      int64     `json:"user_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	GameCount   int       `json:"game_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
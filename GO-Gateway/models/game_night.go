@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Game night statuses
+const (
+	GameNightPlanned   = "planned"
+	GameNightCancelled = "cancelled"
+)
+
+// RSVP answers; invited means the user has not answered yet
+const (
+	RSVPInvited = "invited"
+	RSVPYes     = "yes"
+	RSVPMaybe   = "maybe"
+	RSVPNo      = "no"
+)
+
+// GameNight is a planned get-together with its invitees
+type GameNight struct {
+	ID             int64             `json:"id"`
+	HostID         int64             `json:"host_id"`
+	HostUsername   string            `json:"host_username"`
+	Title          string            `json:"title"`
+	StartsAt       time.Time         `json:"starts_at"`
+	Location       string            `json:"location"`
+	Notes          string            `json:"notes"`
+	Status         string            `json:"status"`
+	ConfirmedCount int               `json:"confirmed_count"`
+	Invites        []GameNightInvite `json:"invites"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// GameNightInvite is one invitee and their RSVP
+type GameNightInvite struct {
+	UserID      int64      `json:"user_id"`
+	Username    string     `json:"username"`
+	RSVP        string     `json:"rsvp"`
+	InvitedAt   time.Time  `json:"invited_at"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+}
+
+// ShortlistGame is a candidate game owned by a confirmed attendee, with its votes
+type ShortlistGame struct {
+	BoardgameID int      `json:"boardgame_id"`
+	Title       string   `json:"title"`
+	MinPlayers  int      `json:"min_players"`
+	MaxPlayers  int      `json:"max_players"`
+	PlayTimeMin int      `json:"play_time_min"`
+	PlayTimeMax int      `json:"play_time_max"`
+	ImageURL    string   `json:"image_url"`
+	Owners      []string `json:"owners"`
+	Votes       int      `json:"votes"`
+	VotedByMe   bool     `json:"voted_by_me"`
+}
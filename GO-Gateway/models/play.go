@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Play is one logged session of a board game
+type Play struct {
+	ID              int64        `json:"id"`
+	UserID          int64        `json:"user_id"` // The user who logged the play
+	BoardgameID     int          `json:"boardgame_id"`
+	GameTitle       string       `json:"game_title,omitempty"`
+	PlayedAt        time.Time    `json:"played_at"`
+	DurationMinutes *int         `json:"duration_minutes,omitempty"`
+	Location        string       `json:"location"`
+	Notes           string       `json:"notes"`
+	Players         []PlayPlayer `json:"players"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+// PlayPlayer is a participant of a play: a registered user (UserID) or a guest (GuestName)
+type PlayPlayer struct {
+	UserID    *int64   `json:"user_id,omitempty"`
+	Username  string   `json:"username,omitempty"`
+	GuestName string   `json:"guest_name,omitempty"`
+	Score     *float64 `json:"score,omitempty"`
+	Winner    bool     `json:"winner"`
+}
+
+// GamePlayStats summarises the plays of one game a user took part in
+type GamePlayStats struct {
+	BoardgameID  int        `json:"boardgame_id"`
+	Title        string     `json:"title"`
+	Plays        int        `json:"plays"`
+	Wins         int        `json:"wins"`
+	WinRate      float64    `json:"win_rate"`
+	TotalMinutes int        `json:"total_minutes"`
+	LastPlayedAt *time.Time `json:"last_played_at,omitempty"`
+}
+
+// PlayStats summarises all of a user's plays
+type PlayStats struct {
+	UserID       int64           `json:"user_id"`
+	TotalPlays   int             `json:"total_plays"`
+	GamesPlayed  int             `json:"games_played"`
+	Wins         int             `json:"wins"`
+	WinRate      float64         `json:"win_rate"`
+	TotalMinutes int             `json:"total_minutes"`
+	HIndex       int             `json:"h_index"` // h games played at least h times each
+	Games        []GamePlayStats `json:"games"`
+}
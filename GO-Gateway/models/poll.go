@@ -0,0 +1,50 @@
+package models
+
+// Player count poll answers
+const (
+	PlayerCountBest           = "best"
+	PlayerCountRecommended    = "recommended"
+	PlayerCountNotRecommended = "not_recommended"
+)
+
+// PlayerCountVote is a user's answer for one player count
+type PlayerCountVote struct {
+	PlayerCount int    `json:"player_count"`
+	Vote        string `json:"vote"`
+}
+
+// UserPollVotes is everything a user voted for one game
+type UserPollVotes struct {
+	Weight       *int              `json:"weight"`
+	PlayerCounts []PlayerCountVote `json:"player_counts"`
+}
+
+// PlayerCountResult tallies the votes for one player count. Verdict is the community
+// answer: best, recommended or not_recommended.
+type PlayerCountResult struct {
+	PlayerCount    int    `json:"player_count"`
+	Best           int    `json:"best"`
+	Recommended    int    `json:"recommended"`
+	NotRecommended int    `json:"not_recommended"`
+	Verdict        string `json:"verdict"`
+}
+
+// GamePollResults is the aggregated weight and player count poll of a game
+type GamePollResults struct {
+	BoardgameID             int                 `json:"boardgame_id"`
+	WeightAvg               *float64            `json:"weight_avg"`
+	WeightVotes             int                 `json:"weight_votes"`
+	WeightDistribution      map[int]int         `json:"weight_distribution"`
+	PlayerCountVoters       int                 `json:"player_count_voters"`
+	PlayerCounts            []PlayerCountResult `json:"player_counts"`
+	BestPlayerCounts        []int               `json:"best_player_counts"`
+	RecommendedPlayerCounts []int               `json:"recommended_player_counts"`
+}
+
+// GamePollStats is the stored summary of a game's polls, used for filtering and recommendations
+type GamePollStats struct {
+	BoardgameID             int      `json:"boardgame_id"`
+	WeightAvg               *float64 `json:"weight_avg"`
+	BestPlayerCounts        []int    `json:"best_player_counts"`
+	RecommendedPlayerCounts []int    `json:"recommended_player_counts"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Review statuses
+const (
+	ReviewVisible = "visible"
+	ReviewHidden  = "hidden"
+)
+
+// Review is a user's written review of a board game, shown with their current rating
+type Review struct {
+	ID           int64      `json:"id"`
+	UserID       int64      `json:"user_id"`
+	Username     string     `json:"username"`
+	BoardgameID  int        `json:"boardgame_id"`
+	Rating       float64    `json:"rating"`
+	Body         string     `json:"body"`
+	Status       string     `json:"status"`
+	HiddenReason string     `json:"hidden_reason,omitempty"`
+	ModeratedAt  *time.Time `json:"moderated_at,omitempty"`
+	HelpfulCount int        `json:"helpful_count"`
+	EditCount    int        `json:"edit_count"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// ReviewRevision is an earlier body of an edited review
+type ReviewRevision struct {
+	Body       string    `json:"body"`
+	WrittenAt  time.Time `json:"written_at"`
+	ReplacedAt time.Time `json:"replaced_at"`
+}
+
+// ReviewSummary is shown on the game detail endpoint
+type ReviewSummary struct {
+	Count         int         `json:"count"`
+	AverageRating float64     `json:"average_rating"`
+	Distribution  map[int]int `json:"distribution"` // rounded rating -> number of reviews
+	MostHelpful   *Review     `json:"most_helpful,omitempty"`
+}
+
+// ReviewReport is one user's report of a review
+type ReviewReport struct {
+	UserID    int64     `json:"user_id"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ModerationItem is a review waiting for a moderator, with its open reports
+type ModerationItem struct {
+	Review  Review         `json:"review"`
+	Reports []ReviewReport `json:"reports"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Follow statuses
+const (
+	FollowPending  = "pending"
+	FollowAccepted = "accepted"
+)
+
+// Feed visibility settings
+const (
+	FeedFollowers = "followers"
+	FeedFriends   = "friends"
+	FeedNobody    = "nobody"
+)
+
+// Feed item types
+const (
+	FeedRating   = "rating"
+	FeedReview   = "review"
+	FeedPlay     = "play"
+	FeedFavorite = "favorite"
+)
+
+// Follow is one edge of the follow graph, seen from the other user
+type Follow struct {
+	UserID     int64      `json:"user_id"`
+	Username   string     `json:"username"`
+	AvatarURL  string     `json:"avatar_url"`
+	Status     string     `json:"status"`
+	Friend     bool       `json:"friend"`
+	CreatedAt  time.Time  `json:"created_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+}
+
+// PrivacySettings controls who can follow a user and who sees their activity
+type PrivacySettings struct {
+	PrivateAccount bool   `json:"private_account"`
+	FeedVisibility string `json:"feed_visibility"`
+}
+
+// FeedItem is one entry in the social activity feed. SourceID is the ID of the
+// state event, review or play it comes from. Text is the start of a review; play
+// notes are never included.
+type FeedItem struct {
+	Type        string    `json:"type"`
+	SourceID    int64     `json:"source_id"`
+	UserID      int64     `json:"user_id"`
+	Username    string    `json:"username"`
+	BoardgameID int       `json:"boardgame_id"`
+	GameTitle   string    `json:"game_title"`
+	Rating      *float64  `json:"rating,omitempty"`
+	Text        string    `json:"text,omitempty"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
@@ -23,5 +23,6 @@ type ActivityLog struct {
 	Data      ActivityData `json:"data"`
 	UserID    int64        `json:"userID"`    // Changed to int64 to match incoming data
 	SessionID string       `json:"sessionID"` // Assuming sessionID is a string, adjust if needed
-	Timestamp string       `json:"timestamp"` // Assuming timestamp is a string, adjust if needed
+	Timestamp string       `json:"timestamp"` // RFC 3339; empty means the time it was received
+	EventID   string       `json:"eventID"`   // Client-generated ID used to drop resent events
 }
\ No newline at end of file
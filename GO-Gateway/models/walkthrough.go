@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Walkthrough is a guided, step-by-step session over one version of a rule set
+type Walkthrough struct {
+	ID          int64      `json:"id"`
+	RuleID      int        `json:"rule_id"`
+	RuleVersion int        `json:"rule_version"`
+	OwnerID     int64      `json:"owner_id"`
+	JoinCode    string     `json:"join_code"`
+	CurrentStep int        `json:"current_step"`
+	TotalSteps  int        `json:"total_steps"`
+	StartedAt   time.Time  `json:"started_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// WalkthroughStepStat aggregates how often a step of one rule version was shown and re-read
+// across sessions
+type WalkthroughStepStat struct {
+	RuleVersion int `json:"rule_version"`
+	Step        int `json:"step"`
+	Views       int `json:"views"`
+	Rereads     int `json:"rereads"`
+	Sessions    int `json:"sessions"`
+}
@@ -1,48 +1,74 @@
 package routes
 
 import (
+	"guru-game/internal/aggregation"
 	"guru-game/internal/auth/handlers_Auth"
 	"guru-game/internal/auth/jwt"
 	"guru-game/internal/boardgame/handlers_board"
 	"guru-game/internal/boardgame/service_board"
+	"guru-game/internal/catalogimport"
+	importhandlers "guru-game/internal/catalogimport/handlers"
+	"guru-game/internal/collection"
+	collectionhandlers "guru-game/internal/collection/handlers"
 	"guru-game/internal/db/repository/boardgame"
 	"guru-game/internal/db/repository/user_states"
+	"guru-game/internal/gamenight"
+	gamenighthandlers "guru-game/internal/gamenight/handlers"
 	gamesearchhandlers "guru-game/internal/gamesearch/handlers"
 	gamestatehandlers "guru-game/internal/gamestate/handlers"
+	playservice "guru-game/internal/plays"
+	playhandlers "guru-game/internal/plays/handlers"
+	"guru-game/internal/poll"
+	pollhandlers "guru-game/internal/poll/handlers"
 	"guru-game/internal/recommendation"
+	"guru-game/internal/review"
+	reviewhandlers "guru-game/internal/review/handlers"
+	"guru-game/internal/social"
+	socialhandlers "guru-game/internal/social/handlers"
 	useractivityhandlers "guru-game/internal/useractivity/handlers"
+	"guru-game/internal/userstate"
+	"guru-game/internal/walkthrough"
+	walkthroughhandlers "guru-game/internal/walkthrough/handlers"
 	"log"
-	"os"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/joho/godotenv"
 )
 
-func SetupRoutes(app *fiber.App, userStateRepo user_states.UserStateRepository, boardGameRepo boardgame.BoardGameRepository, gameRuleService *service_board.GameRuleService, gameSearchHandlers *gamesearchhandlers.GameSearchHandlers) {
+// Dependencies are the repositories, services and clients the routes are built on
+type Dependencies struct {
+	UserStateRepo      user_states.UserStateRepository
+	BoardGameRepo      boardgame.BoardGameRepository
+	GameRuleService    *service_board.GameRuleService
+	WalkthroughService *walkthrough.Service
+	AggregationService *aggregation.Service
+	GameSearchHandlers *gamesearchhandlers.GameSearchHandlers
+	UserStateService   *userstate.Service
+	PlayService        *playservice.Service
+	CollectionService  *collection.Service
+	ReviewService      *review.Service
+	PollService        *poll.Service
+	SocialService      *social.Service
+	GameNightService   *gamenight.Service
+	// RecommendationClient talks to the ML service; the in-process fallback is added here
+	RecommendationClient recommendation.RecommendationClient
+}
+
+func SetupRoutes(app *fiber.App, deps Dependencies) {
 	// Load environment variables
 	if err := godotenv.Load(); err != nil {
 		log.Println("⚠️ Warning: .env file not found")
 	}
 
-	// Get Python service URL from environment variable, default to localhost:50051
-	pythonServiceURL := os.Getenv("PYTHON_SERVICE_URL")
-	if pythonServiceURL == "" {
-		pythonServiceURL = "http://localhost:50051"
-	}
-
-	// --- Setup REST client for recommendation ---
-	log.Println("🔌 Connecting to Python ML REST service...")
-	log.Printf("🌐 Python service URL: %s", pythonServiceURL)
-	restClient := recommendation.NewRESTRecommendationClient(pythonServiceURL)
-	log.Println("✅ REST client initialized")
-
 	bgService := service_board.GetBoardgameService()
-	// Pass userStateRepo to the recommendation handler constructor
-	recommendHandler := recommendation.NewHandler(restClient, bgService, userStateRepo)
+	// Reads fall back to the in-process recommender while the ML service is unavailable.
+	// Only ML results are cached, so a cached list is served before falling back.
+	recoClient := recommendation.NewFailoverRecommendationClient(deps.RecommendationClient, recommendation.NewLocalRecommendationClient(bgService, deps.UserStateRepo))
+	recommendHandler := recommendation.NewHandler(recoClient, bgService, deps.UserStateRepo, deps.CollectionService, deps.PollService, deps.SocialService, deps.GameNightService)
 	log.Println("✅ Recommendation handler initialized")
 
 	// Initialize Boardgame Handlers with BoardgameRepository
-	boardGameHandlers := handlers_board.NewBoardGameHandlers(boardGameRepo)
+	boardGameHandlers := handlers_board.NewBoardGameHandlers(deps.BoardGameRepo, deps.ReviewService)
 
 	// Auth routes
 	api := app.Group("/auth")
@@ -62,14 +88,134 @@ func SetupRoutes(app *fiber.App, userStateRepo user_states.UserStateRepository,
 	bg := app.Group("/boardgames")
 	// Apply JWT middleware to potentially get user ID, but handler logic should handle unauthenticated users
 	bg.Get("/", jwt.JWTMiddleware, boardGameHandlers.HandleGetAllBoardGames)
+	// Must be registered before /:id so "export" is not parsed as an ID
+	bg.Get("/export", jwt.JWTMiddleware, boardGameHandlers.HandleExportBoardGames)
 	bg.Get("/:id", boardGameHandlers.GetBoardGameByIDHandler)
 	bg.Get("/es/:id", boardGameHandlers.GetBoardGameByIDFromESHandler)
 
+	// Game rule routes
+	gameRuleHandlers := handlers_board.NewGameRuleHandlers(deps.GameRuleService)
+	bg.Get("/:id/rules", gameRuleHandlers.HandleGetGameRules)
+	bg.Get("/:id/rules/search", gameRuleHandlers.HandleSearchGameRules)
+	rules := app.Group("/rules")
+	rules.Get("/search", gameRuleHandlers.HandleSearchGameRules)
+	rules.Get("/:rule_id/versions", gameRuleHandlers.HandleGetGameRuleVersions)
+	rules.Get("/:rule_id/versions/:version", gameRuleHandlers.HandleGetGameRuleVersion)
+	rules.Get("/:rule_id/diff", gameRuleHandlers.HandleDiffGameRuleVersions)
+
+	// Rule walkthrough routes
+	walkthroughHandlers := walkthroughhandlers.NewWalkthroughHandlers(deps.WalkthroughService)
+	rules.Get("/:rule_id/walkthrough-stats", walkthroughHandlers.HandleStepStats)
+	wt := app.Group("/walkthroughs", jwt.JWTMiddleware)
+	wt.Post("/", walkthroughHandlers.HandleStart)
+	wt.Post("/join", walkthroughHandlers.HandleJoin)
+	wt.Get("/:id", walkthroughHandlers.HandleGet)
+	wt.Post("/:id/next", walkthroughHandlers.HandleNext)
+	wt.Post("/:id/previous", walkthroughHandlers.HandlePrevious)
+	wt.Post("/:id/jump", walkthroughHandlers.HandleJump)
+	wt.Post("/:id/complete", walkthroughHandlers.HandleComplete)
+
+	// Admin routes
+	admin := app.Group("/admin", jwt.JWTMiddleware, jwt.AdminMiddleware)
+	importHandlers := importhandlers.NewImportHandlers(catalogimport.NewImporter(deps.BoardGameRepo))
+	admin.Post("/boardgames/import", importHandlers.HandleImport)
+	admin.Post("/boardgames/:id/rules", gameRuleHandlers.HandleCreateGameRule)
+	admin.Put("/rules/:rule_id", gameRuleHandlers.HandleUpdateGameRule)
+	admin.Delete("/rules/:rule_id", gameRuleHandlers.HandleDeleteGameRule)
+	admin.Post("/aggregates/recompute", func(c *fiber.Ctx) error {
+		if err := deps.AggregationService.RecomputeAll(c.Context()); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to recompute aggregates"})
+		}
+		return c.JSON(fiber.Map{"message": "Aggregates recomputed successfully"})
+	})
+
 	// User Activity routes
-	userActivity := app.Group("/user/activities")
-	// Create a new instance of UserActivityHandler with the restClient
-	userActivityHandler := useractivityhandlers.NewUserActivityHandler(restClient)
+	userActivity := app.Group("/user/activities", jwt.JWTMiddleware)
+	userActivityHandler := useractivityhandlers.NewUserActivityHandler(deps.UserStateService)
 	userActivity.Post("/", userActivityHandler.HandleUserActivity)
+	userActivity.Post("/batch", userActivityHandler.HandleUserActivityBatch)
+	app.Get("/user/recently-viewed", jwt.JWTMiddleware, userActivityHandler.HandleRecentlyViewed)
+	bg.Get("/:id/view-stats", userActivityHandler.HandleGameViewStats)
+
+	// Play log routes
+	playHandlers := playhandlers.NewPlayHandlers(deps.PlayService)
+	playRoutes := app.Group("/plays", jwt.JWTMiddleware)
+	playRoutes.Get("/", playHandlers.HandleListPlays)
+	playRoutes.Post("/", playHandlers.HandleCreatePlay)
+	playRoutes.Get("/stats", playHandlers.HandlePlayStats)
+	playRoutes.Get("/:id", playHandlers.HandleGetPlay)
+	playRoutes.Put("/:id", playHandlers.HandleUpdatePlay)
+	playRoutes.Delete("/:id", playHandlers.HandleDeletePlay)
+
+	// Collection and shelf routes
+	collectionHandlers := collectionhandlers.NewCollectionHandlers(deps.CollectionService)
+	userCollection := app.Group("/user/collection", jwt.JWTMiddleware)
+	userCollection.Get("/", collectionHandlers.HandleListCollection)
+	userCollection.Get("/:game_id", collectionHandlers.HandleGetCollectionGame)
+	userCollection.Put("/:game_id/:status", collectionHandlers.HandleSetCollectionStatus)
+	userCollection.Delete("/:game_id/:status", collectionHandlers.HandleRemoveCollectionStatus)
+	shelves := app.Group("/user/shelves", jwt.JWTMiddleware)
+	shelves.Get("/", collectionHandlers.HandleListShelves)
+	shelves.Post("/", collectionHandlers.HandleCreateShelf)
+	shelves.Put("/:id", collectionHandlers.HandleUpdateShelf)
+	shelves.Delete("/:id", collectionHandlers.HandleDeleteShelf)
+	shelves.Get("/:id/games", collectionHandlers.HandleListShelfGames)
+	shelves.Put("/:id/games/:game_id", collectionHandlers.HandleAddToShelf)
+	shelves.Delete("/:id/games/:game_id", collectionHandlers.HandleRemoveFromShelf)
+
+	// Review routes
+	reviewHandlers := reviewhandlers.NewReviewHandlers(deps.ReviewService)
+	bg.Get("/:id/reviews", reviewHandlers.HandleListReviews)
+	bg.Get("/:id/review", jwt.JWTMiddleware, reviewHandlers.HandleGetMyReview)
+	bg.Put("/:id/review", jwt.JWTMiddleware, reviewHandlers.HandleWriteReview)
+	bg.Delete("/:id/review", jwt.JWTMiddleware, reviewHandlers.HandleDeleteReview)
+	reviewRoutes := app.Group("/reviews")
+	reviewRoutes.Get("/:id/history", reviewHandlers.HandleReviewHistory)
+	reviewRoutes.Post("/:id/helpful", jwt.JWTMiddleware, reviewHandlers.HandleVoteHelpful)
+	reviewRoutes.Delete("/:id/helpful", jwt.JWTMiddleware, reviewHandlers.HandleUnvoteHelpful)
+	reviewRoutes.Post("/:id/report", jwt.JWTMiddleware, reviewHandlers.HandleReportReview)
+	admin.Get("/reviews/queue", reviewHandlers.HandleModerationQueue)
+	admin.Post("/reviews/:id/hide", reviewHandlers.HandleHideReview)
+	admin.Post("/reviews/:id/restore", reviewHandlers.HandleRestoreReview)
+
+	// Weight and player count poll routes
+	pollHandlers := pollhandlers.NewPollHandlers(deps.PollService)
+	bg.Get("/:id/polls", pollHandlers.HandleGetPollResults)
+	bg.Get("/:id/polls/mine", jwt.JWTMiddleware, pollHandlers.HandleGetMyVotes)
+	bg.Put("/:id/polls/weight", jwt.JWTMiddleware, pollHandlers.HandleVoteWeight)
+	bg.Delete("/:id/polls/weight", jwt.JWTMiddleware, pollHandlers.HandleDeleteWeightVote)
+	bg.Put("/:id/polls/player-counts", jwt.JWTMiddleware, pollHandlers.HandleVotePlayerCounts)
+
+	// Follow graph and activity feed routes
+	socialHandlers := socialhandlers.NewSocialHandlers(deps.SocialService)
+	socialRoutes := app.Group("/social", jwt.JWTMiddleware)
+	socialRoutes.Get("/feed", socialHandlers.HandleFeed)
+	socialRoutes.Get("/privacy", socialHandlers.HandleGetPrivacy)
+	socialRoutes.Put("/privacy", socialHandlers.HandleUpdatePrivacy)
+	socialRoutes.Get("/followers", socialHandlers.HandleListFollowers)
+	socialRoutes.Delete("/followers/:user_id", socialHandlers.HandleRemoveFollower)
+	socialRoutes.Get("/following", socialHandlers.HandleListFollowing)
+	socialRoutes.Get("/friends", socialHandlers.HandleListFriends)
+	socialRoutes.Post("/follow/:user_id", socialHandlers.HandleFollow)
+	socialRoutes.Delete("/follow/:user_id", socialHandlers.HandleUnfollow)
+	socialRoutes.Get("/requests", socialHandlers.HandleListRequests)
+	socialRoutes.Post("/requests/:user_id/accept", socialHandlers.HandleAcceptRequest)
+	socialRoutes.Post("/requests/:user_id/decline", socialHandlers.HandleDeclineRequest)
+
+	// Game night routes
+	gameNightHandlers := gamenighthandlers.NewGameNightHandlers(deps.GameNightService)
+	gameNights := app.Group("/game-nights", jwt.JWTMiddleware)
+	gameNights.Get("/", gameNightHandlers.HandleListGameNights)
+	gameNights.Post("/", gameNightHandlers.HandleCreateGameNight)
+	gameNights.Get("/:id", gameNightHandlers.HandleGetGameNight)
+	gameNights.Put("/:id", gameNightHandlers.HandleUpdateGameNight)
+	gameNights.Post("/:id/cancel", gameNightHandlers.HandleCancelGameNight)
+	gameNights.Post("/:id/invites", gameNightHandlers.HandleInvite)
+	gameNights.Delete("/:id/invites/:user_id", gameNightHandlers.HandleUninvite)
+	gameNights.Put("/:id/rsvp", gameNightHandlers.HandleRSVP)
+	gameNights.Get("/:id/shortlist", gameNightHandlers.HandleShortlist)
+	gameNights.Put("/:id/votes/:game_id", gameNightHandlers.HandleVote)
+	gameNights.Delete("/:id/votes/:game_id", gameNightHandlers.HandleUnvote)
 
 	// Recommendation routes
 	reco := app.Group("/recommendations")
@@ -82,6 +228,7 @@ func SetupRoutes(app *fiber.App, userStateRepo user_states.UserStateRepository,
 	reco.Get("/behavior/:user_id", recommendHandler.HandleGetBehaviorBasedRecommendations)
 
 	reco.Get("/", recommendHandler.HandleGetRecommendations)
+	reco.Post("/group", jwt.JWTMiddleware, recommendHandler.HandleGetGroupRecommendations)
 	reco.Get("/user/:user_id", func(c *fiber.Ctx) error {
 		c.Queries()["user_id"] = c.Params("user_id")
 		return recommendHandler.HandleGetRecommendations(c)
@@ -101,14 +248,19 @@ func SetupRoutes(app *fiber.App, userStateRepo user_states.UserStateRepository,
 	// Get user's favorite boardgames directly from DB
 	reco.Get("/favorites/:user_id", recommendHandler.HandleGetFavoritedBoardgames)
 	// Game State Update routes
-	gameState := app.Group("/api/game/updateState")
-	// Create an instance of GameStateHandlers with the UserStateRepository
-	gameStateHandlersInstance := gamestatehandlers.NewGameStateHandlers(userStateRepo)
+	gameState := app.Group("/api/game/updateState", jwt.JWTMiddleware)
+	// Create an instance of GameStateHandlers with the shared user state service
+	gameStateHandlersInstance := gamestatehandlers.NewGameStateHandlers(deps.UserStateService)
+	gameState.Get("/", gameStateHandlersInstance.HandleGetGameState)
 	gameState.Post("/", gameStateHandlersInstance.HandleGameStateUpdate)
 	gameState.Put("/", gameStateHandlersInstance.HandleGameStateUpdate)
 	gameState.Patch("/", gameStateHandlersInstance.HandleGameStateUpdate)
 
+	// State history routes
+	app.Get("/user/state-events", jwt.JWTMiddleware, gameStateHandlersInstance.HandleGetMyStateEvents)
+	admin.Get("/boardgames/:id/state-events", gameStateHandlersInstance.HandleGetBoardgameStateEvents)
+
 	// Game Search routes
 	gameSearch := app.Group("/api/search")
-	gameSearch.Get("/", gameSearchHandlers.HandleGameSearch)
+	gameSearch.Get("/", deps.GameSearchHandlers.HandleGameSearch)
 }
@@ -1,4 +1,4 @@
-from fastapi import FastAPI, HTTPException
+from fastapi import FastAPI, HTTPException, Request
 from pydantic import BaseModel
 from typing import List, Optional
 from datetime import datetime
@@ -19,6 +19,16 @@ PORT = int(os.getenv("PYTHON_SERVICE_PORT", "50051"))
 
 app = FastAPI(title="Board Game Recommendation API")
 
+@app.middleware("http")
+async def request_id_middleware(request: Request, call_next):
+    """Echo the gateway's X-Request-ID and tag error logs with it"""
+    request_id = request.headers.get("X-Request-ID", "-")
+    response = await call_next(request)
+    if response.status_code >= 500:
+        logger.error(f"❌ [{request_id}] {request.method} {request.url.path} -> {response.status_code}")
+    response.headers["X-Request-ID"] = request_id
+    return response
+
 class BoardgamesRequest(BaseModel):
     boardgames: List[Boardgame]
 
@@ -35,6 +45,14 @@ class Boardgame(BaseModel):
     rating_count: int
     popularity_score: float
     image_url: str
+    weight: Optional[float] = None
+    best_player_counts: List[int] = []
+    recommended_player_counts: List[int] = []
+
+class PollStats(BaseModel):
+    weight: Optional[float] = None
+    best_player_counts: List[int] = []
+    recommended_player_counts: List[int] = []
 
 class RecommendationRequest(BaseModel):
     user_id: str
@@ -94,6 +112,18 @@ async def send_all_boardgames(request: BoardgamesRequest):
     except Exception as e:
         raise HTTPException(status_code=500, detail=str(e))
 
+@app.put("/api/boardgames/{boardgame_id}/polls")
+async def update_poll_stats(boardgame_id: int, stats: PollStats):
+    try:
+        updated = recommendation_service.update_poll_stats(boardgame_id, stats.dict())
+        if not updated:
+            raise HTTPException(status_code=404, detail="Boardgame not indexed")
+        return {"success": True, "message": "Poll stats updated successfully"}
+    except HTTPException:
+        raise
+    except Exception as e:
+        raise HTTPException(status_code=500, detail=str(e))
+
 @app.get("/api/boardgames")
 async def get_all_boardgames():
     try:
@@ -134,7 +164,10 @@ async def search_boardgames_endpoint(
     playTime: Optional[int] = None,
     categories: Optional[str] = None,
     limit: int = 10,
-    page: int = 1
+    page: int = 1,
+    minWeight: Optional[float] = None,
+    maxWeight: Optional[float] = None,
+    bestAt: Optional[int] = None
 ):
     try:
         categories_list = categories.split(",") if categories else None
@@ -145,7 +178,10 @@ async def search_boardgames_endpoint(
             play_time=playTime,
             categories=categories_list,
             limit=limit,
-            page=page
+            page=page,
+            min_weight=minWeight,
+            max_weight=maxWeight,
+            best_player_count=bestAt
         )
         return results
     except Exception as e:
@@ -81,6 +81,15 @@ boardgame_mapping = {
             "popularity_score": {
                 "type": "float"
             },
+            "weight": {
+                "type": "float"
+            },
+            "best_player_counts": {
+                "type": "integer"
+            },
+            "recommended_player_counts": {
+                "type": "integer"
+            },
             "image_url": {
                 "type": "keyword",
                 "index": False
@@ -5,6 +5,7 @@ from pydantic import BaseModel
 from .indexing import create_indices
 from .setting import boardgame_index_name, user_action_index_name
 from connection.connection import client
+from elasticsearch import NotFoundError
 from typing import Optional, List, Union
 
 # ตั้งค่า logging
@@ -32,6 +33,10 @@ class Boardgame(BaseModel):
     rating_count: int
     popularity_score: float
     image_url: str
+    # Community poll results from the gateway, missing for games nobody voted on
+    weight: Optional[float] = None
+    best_player_counts: List[int] = []
+    recommended_player_counts: List[int] = []
 
 # Define weights for different user actions and scoring components
 ACTION_WEIGHTS = {
@@ -43,7 +48,10 @@ ACTION_WEIGHTS = {
     "play_time_match": 0.2,
     "rating_avg_consideration": 1,
     "popularity_consideration": 0,
-    "similarity_impact": 2.0 # Weight for the similarity-based score component
+    "similarity_impact": 2.0, # Weight for the similarity-based score component
+    "best_player_count_match": 0.4, # Voted best at a player count the user plays
+    "weight_match": 0.5, # Community complexity close to what the user likes
+    "weight_tolerance": 0.75
 }
 
 # Helper function to calculate similarity between two boardgames (based on categories)
@@ -269,6 +277,8 @@ class RecommendationService:
                 'categories': set(user_categories) if user_categories else set(),
                 'player_counts': set(),
                 'play_times': set(),
+                'best_player_counts': set(),
+                'weights': [],
             } # Ratings are now directly used for preference score
 
             for action in user_actions:
@@ -296,6 +306,11 @@ class RecommendationService:
                     user_preferences['play_times'].add(boardgame.play_time_max)
                     logger.info(f"  ⏱️ Added play time range: {boardgame.play_time_min}-{boardgame.play_time_max}")
 
+                    if boardgame.best_player_counts:
+                        user_preferences['best_player_counts'].update(boardgame.best_player_counts)
+                    if boardgame.weight is not None:
+                        user_preferences['weights'].append(boardgame.weight)
+
                 # Calculate preference score for this specific boardgame based on actions
                 current_preference_score = user_boardgame_preference_scores.get(bg_id_str, 0.0)
                 if action.action_type == "like":
@@ -358,6 +373,18 @@ class RecommendationService:
                         score += ACTION_WEIGHTS["play_time_match"]
                         logger.info(f"  ⏱️ Play time match (score: {ACTION_WEIGHTS['play_time_match']:.2f})")
 
+                # Community poll matching
+                if user_preferences['best_player_counts'] and boardgame.best_player_counts:
+                    if user_preferences['best_player_counts'].intersection(boardgame.best_player_counts):
+                        score += ACTION_WEIGHTS["best_player_count_match"]
+                        logger.info(f"  👥 Best player count match (score: {ACTION_WEIGHTS['best_player_count_match']:.2f})")
+
+                if user_preferences['weights'] and boardgame.weight is not None:
+                    preferred_weight = sum(user_preferences['weights']) / len(user_preferences['weights'])
+                    if abs(boardgame.weight - preferred_weight) <= ACTION_WEIGHTS["weight_tolerance"]:
+                        score += ACTION_WEIGHTS["weight_match"]
+                        logger.info(f"  ⚖️ Weight match {boardgame.weight:.2f} ~ {preferred_weight:.2f} (score: {ACTION_WEIGHTS['weight_match']:.2f})")
+
                 # Rating consideration
                 if boardgame.rating_avg > 0:
                     rating_score = ACTION_WEIGHTS["rating_avg_consideration"] * (boardgame.rating_avg / 5.0)
@@ -427,6 +454,28 @@ class RecommendationService:
             logger.error(f"❌ Error updating boardgames: {e}")
             return False
 
+    def update_poll_stats(self, boardgame_id: int, stats: dict) -> bool:
+        """Replace the community poll fields of one indexed boardgame"""
+        try:
+            client.update(
+                index=boardgame_index_name,
+                id=str(boardgame_id),
+                body={"doc": stats}
+            )
+            for bg in self.boardgames:
+                if bg.id == boardgame_id:
+                    bg.weight = stats.get("weight")
+                    bg.best_player_counts = stats.get("best_player_counts", [])
+                    bg.recommended_player_counts = stats.get("recommended_player_counts", [])
+            logger.info(f"✅ Poll stats of boardgame {boardgame_id} updated in Elasticsearch")
+            return True
+        except NotFoundError:
+            logger.warning(f"⚠️ Boardgame {boardgame_id} is not indexed yet; poll stats arrive with the next sync")
+            return False
+        except Exception as e:
+            logger.error(f"❌ Error updating poll stats of boardgame {boardgame_id}: {e}")
+            raise
+
     def get_all_boardgames(self) -> List[Boardgame]:
         """Get all boardgames from Elasticsearch"""
         try:
@@ -490,6 +539,9 @@ def search_boardgames(
     categories: Optional[List[str]] = None,
     limit: int = 10,
     page: int = 1,
+    min_weight: Optional[float] = None,
+    max_weight: Optional[float] = None,
+    best_player_count: Optional[int] = None,
     search_logic: str = "OR",  # OR logic for text search
     category_logic: str = "AND"  # AND logic for categories
 ) -> List[Boardgame]:
@@ -499,6 +551,8 @@ def search_boardgames(
     Args:
         search_logic: "OR" or "AND" - logic for text search terms
         category_logic: "OR" or "AND" - logic between categories
+        min_weight, max_weight, best_player_count: community poll filters; games nobody
+            voted on never match them
     """
     try:
         query = {
@@ -678,6 +732,18 @@ def search_boardgames(
                 {"range": {"play_time_max": {"gte": play_time}}}
             ])
 
+        # Community poll filters. Range and term filters skip documents without the field,
+        # so games nobody voted on never match.
+        if min_weight is not None and min_weight > 0:
+            logger.info(f"⚖️ Min weight filter: {min_weight}")
+            query["bool"]["filter"].append({"range": {"weight": {"gte": min_weight}}})
+        if max_weight is not None and max_weight > 0:
+            logger.info(f"⚖️ Max weight filter: {max_weight}")
+            query["bool"]["filter"].append({"range": {"weight": {"lte": max_weight}}})
+        if best_player_count is not None and best_player_count > 0:
+            logger.info(f"👥 Best at filter: {best_player_count}")
+            query["bool"]["filter"].append({"term": {"best_player_counts": best_player_count}})
+
         # === HANDLE EMPTY QUERY ===
         if (not query["bool"]["must"] and 
             not query["bool"]["should"] and 
@@ -740,7 +806,7 @@ def search_boardgames(
         # === LOGGING ===
         search_type = f"text({search_logic})" if search_query else ""
         category_type = f"categories({category_logic})" if categories else ""
-        filter_type = "filters" if (player_count or play_time) else ""
+        filter_type = "filters" if (player_count or play_time or min_weight or max_weight or best_player_count) else ""
         
         search_description = " + ".join(filter(None, [search_type, category_type, filter_type]))
         if not search_description: